@@ -24,6 +24,8 @@ func handleError(ctx iris.Context, err error) {
 			outErr = apiErrors.ErrNotFound
 		case datastoreErrors.ErrAlreadyExists:
 			outErr = apiErrors.ErrAlreadyExists
+		case datastoreErrors.ErrStaleVersion:
+			outErr = apiErrors.ErrPreconditionFailed
 		default:
 			ctx.Application().Logger().Errorf("%v", err)
 			outErr = apiErrors.ErrInternal
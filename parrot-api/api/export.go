@@ -1,16 +1,219 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"fmt"
-	"strings"
+	"regexp"
+	"sort"
+	"strconv"
 
-"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12"
+
+	datastoreErrors "github.com/iris-contrib/parrot/parrot-api/datastore/errors"
 	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
 	"github.com/iris-contrib/parrot/parrot-api/export"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+	"github.com/iris-contrib/parrot/parrot-api/translate"
+)
+
+var (
+	unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+	// maxPseudoExpansion caps the percentage by which pseudo-localized values are lengthened.
+	maxPseudoExpansion = 300
 )
 
-// exportLocale is an API endpoint for exporting locale pairs.
+// exportOptions controls how incomplete locales are exported.
+type exportOptions struct {
+	// minProgress is the completion percentage a locale needs to reach to be exported.
+	minProgress float64
+	// omitUntranslated leaves out empty keys instead of refusing to export
+	// locales below minProgress.
+	omitUntranslated bool
+	// approvedOnly replaces values that have not been approved with the ones of the source locale,
+	// which must exist.
+	approvedOnly bool
+	// source is the ident of the source locale used by approvedOnly and pseudo,
+	// the project's source locale by default.
+	source string
+	// fallback fills empty values from the locale's fallback chain.
+	fallback bool
+	// pseudo exports pseudo-localized values of the source locale, lengthened by
+	// the expansion percentage.
+	pseudo    bool
+	expansion int
+	// nested overrides whether formats that can nest keys do so, if set.
+	nested *bool
+
+	project      *model.Project
+	sourceLocale *model.Locale
+	locales      []model.Locale
+}
+
+// parseExportOptions reads the 'minProgress', 'omitUntranslated', 'approvedOnly',
+// 'source', 'fallback', 'pseudo', 'expansion' and 'nested' query params.
+func parseExportOptions(ctx iris.Context) (*exportOptions, error) {
+	query := ctx.Request().URL.Query()
+	opts := &exportOptions{
+		omitUntranslated: query.Get("omitUntranslated") == "true",
+		approvedOnly:     query.Get("approvedOnly") == "true",
+		source:           query.Get("source"),
+		fallback:         query.Get("fallback") == "true",
+		pseudo:           query.Get("pseudo") == "true",
+		expansion:        translate.DefaultExpansion}
+
+	if v := query.Get("minProgress"); v != "" {
+		minProgress, err := strconv.ParseFloat(v, 64)
+		if err != nil || minProgress < 0 || minProgress > 100 {
+			return nil, apiErrors.ErrBadRequest
+		}
+		opts.minProgress = minProgress
+	}
+
+	if v := query.Get("expansion"); v != "" {
+		expansion, err := strconv.Atoi(v)
+		if err != nil || expansion < 0 || expansion > maxPseudoExpansion {
+			return nil, apiErrors.ErrBadRequest
+		}
+		opts.expansion = expansion
+	}
+
+	if v := query.Get("nested"); v != "" {
+		nested, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, apiErrors.ErrBadRequest
+		}
+		opts.nested = &nested
+	}
+
+	return opts, nil
+}
+
+// prepare loads the source locale and the fallback locales required by the options.
+func (o *exportOptions) prepare(project *model.Project) error {
+	o.project = project
+	if o.source == "" {
+		o.source = project.SourceLocale
+	}
+
+	if o.approvedOnly {
+		if err := o.loadSource(); err != nil {
+			return err
+		}
+	}
+
+	if o.fallback {
+		locales, err := store.GetProjectLocales(project.ID)
+		if err != nil {
+			return err
+		}
+		for i := range locales {
+			locales[i].SyncKeys(project.Keys)
+			locales[i].SyncPluralKeys(project.PluralKeys)
+			// Fallback values must not bring back values that are not approved
+			if o.approvedOnly {
+				locales[i].KeepApproved(o.sourceLocale)
+			}
+		}
+		o.locales = locales
+	}
+
+	return nil
+}
+
+// loadSource loads the source locale, which must exist. Without it, values that
+// are not approved would silently be exported empty, and pseudo-localized values
+// would have nothing to start from.
+func (o *exportOptions) loadSource() error {
+	if o.sourceLocale != nil {
+		return nil
+	}
+	if o.source == "" {
+		return apiErrors.ErrBadRequest
+	}
+	source, err := store.GetProjectLocaleByIdent(o.project.ID, o.source)
+	if err == datastoreErrors.ErrNotFound {
+		return apiErrors.ErrBadRequest
+	}
+	if err != nil {
+		return err
+	}
+	source.SyncKeys(o.project.Keys)
+	source.SyncPluralKeys(o.project.PluralKeys)
+	o.sourceLocale = source
+	return nil
+}
+
+// pseudoSource returns the locale holding the values of the source locale, which
+// are the ones pseudo-localized since the locale's own values are usually missing
+// until the translations arrive. Keys must already be synced with the project.
+func (o *exportOptions) pseudoSource(loc *model.Locale) (*model.Locale, error) {
+	if err := o.loadSource(); err != nil {
+		return nil, err
+	}
+	return sourceValues(loc, o.sourceLocale), nil
+}
+
+// sourceValues returns a copy of the locale holding the values and statuses of
+// the source. Plural forms keep the categories of the locale, those the source
+// lacks get its 'other' form.
+func sourceValues(loc, source *model.Locale) *model.Locale {
+	result := loc.Copy()
+	copied := source.Copy()
+	result.Pairs = copied.Pairs
+	result.Statuses = copied.Statuses
+	for k, forms := range result.Plurals {
+		for c := range forms {
+			v, ok := copied.Plurals[k][c]
+			if !ok {
+				v = copied.Plurals[k]["other"]
+			}
+			forms[c] = v
+		}
+	}
+	return result
+}
+
+// configure sets the project's key delimiter on exporters that can nest keys,
+// and whether they nest them if the options say so.
+func (o *exportOptions) configure(exporter export.Exporter) {
+	nester, ok := exporter.(export.Nester)
+	if !ok {
+		return
+	}
+	nester.SetKeyDelimiter(o.project.KeyDelimiter)
+	if o.nested != nil {
+		nester.SetNested(*o.nested)
+	}
+}
+
+// apply prepares the locale for exporting. Keys must already be synced with the project.
+// The progress is checked first, then values that are not approved are replaced and
+// empty values are filled from the fallback chain, which only holds approved values
+// with approvedOnly. Without a minimum progress, omitUntranslated always leaves out
+// the keys that are still empty.
+func (o *exportOptions) apply(loc *model.Locale) error {
+	incomplete := o.minProgress > 0 && loc.Stats().Progress < o.minProgress
+	if incomplete && !o.omitUntranslated {
+		return apiErrors.ErrLocaleIncomplete
+	}
+
+	if o.approvedOnly {
+		loc.KeepApproved(o.sourceLocale)
+	}
+	if o.fallback {
+		loc.Fill(fallbackLocales(o.project, loc.Ident, o.locales))
+	}
+	if o.omitUntranslated && (incomplete || o.minProgress == 0) {
+		loc.RemoveUntranslated()
+	}
+	return nil
+}
+
+// exportLocale is an API endpoint for exporting locale pairs. With the 'pseudo' query
+// param, the values of the source locale are pseudo-localized under the locale's
+// ident, to test the UI before the translations arrive.
 func exportLocale(ctx iris.Context) {
 
 	projectID := ctx.Params().Get("projectID")
@@ -29,48 +232,76 @@ func exportLocale(ctx iris.Context) {
 		return
 	}
 
-	locale, err := store.GetProjectLocaleByIdent(projectID, localeIdent)
+	opts, err := parseExportOptions(ctx)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
-	var exporter export.Exporter
-	switch strings.ToLower(i18nType) {
-	case "keyvaluejson":
-		exporter = &export.JSON{}
-	case "po":
-		exporter = &export.Gettext{}
-	case "strings":
-		exporter = &export.AppleStrings{}
-	case "properties":
-		exporter = &export.JavaProperties{}
-	case "xmlproperties":
-		exporter = &export.JavaXML{}
-	case "android":
-		exporter = &export.Android{}
-	case "php":
-		exporter = &export.PHP{}
-	case "xlsx":
-		exporter = &export.XLSX{}
-	case "csv":
-		exporter = &export.CSV{}
-	case "yaml":
-		exporter = &export.Yaml{}
-	case "ini":
-		exporter = &export.INI{}
-	default:
+	exporter, ok := export.NewExporter(i18nType)
+	if !ok {
 		handleError(ctx, apiErrors.ErrBadRequest)
 		return
 	}
+	if opts.pseudo {
+		exporter = export.NewPseudo(exporter, opts.expansion)
+	}
+
+	locale, err := store.GetProjectLocaleByIdent(projectID, localeIdent)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if commenter, ok := exporter.(export.Commenter); ok {
+		comments, err := getKeyDescriptions(projectID)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		commenter.SetComments(comments)
+	}
+
+	if err := opts.prepare(project); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	opts.configure(exporter)
+
+	locale.SyncKeys(project.Keys)
+	locale.SyncPluralKeys(project.PluralKeys)
+	if opts.pseudo {
+		locale, err = opts.pseudoSource(locale)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+	}
+	if err := opts.apply(locale); err != nil {
+		handleError(ctx, err)
+		return
+	}
 
 	result, err := exporter.Export(locale)
+	if errs, ok := err.(*apiErrors.MultiError); ok {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
 	filename := fmt.Sprintf("%s.%s", localeIdent, exporter.FileExtension())
+	if opts.pseudo {
+		filename = fmt.Sprintf("%s-pseudo.%s", localeIdent, exporter.FileExtension())
+	}
 
 	ctx.Header("Content-Type", "application/octet-stream")
 	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
@@ -83,3 +314,133 @@ func exportLocale(ctx iris.Context) {
 		return
 	}
 }
+
+// exportProject is an API endpoint for exporting every project locale, or the ones
+// selected with the 'ident' query param, as a single ZIP archive. The archive is
+// refused if any locale is below the requested minimum progress. With the 'pseudo'
+// query param, every locale holds the pseudo-localized values of the source locale.
+func exportProject(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	i18nType := ctx.Params().Get("type")
+	if i18nType == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	localeIdents := ctx.Request().URL.Query()["ident"]
+
+	opts, err := parseExportOptions(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	exporter, ok := export.NewExporter(i18nType)
+	if !ok {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	if opts.pseudo {
+		exporter = export.NewPseudo(exporter, opts.expansion)
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if commenter, ok := exporter.(export.Commenter); ok {
+		comments, err := getKeyDescriptions(projectID)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		commenter.SetComments(comments)
+	}
+
+	locales, err := store.GetProjectLocales(projectID, localeIdents...)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if len(locales) == 0 {
+		handleError(ctx, apiErrors.ErrNotFound)
+		return
+	}
+
+	if err := opts.prepare(project); err != nil {
+		handleError(ctx, err)
+		return
+	}
+	opts.configure(exporter)
+
+	// Export everything before writing the response, so that errors can still be reported
+	files := make(map[string][]byte, len(locales))
+	for i := range locales {
+		locale := &locales[i]
+		locale.SyncKeys(project.Keys)
+		locale.SyncPluralKeys(project.PluralKeys)
+		if opts.pseudo {
+			locale, err = opts.pseudoSource(locale)
+			if err != nil {
+				handleError(ctx, err)
+				return
+			}
+		}
+		if err := opts.apply(locale); err != nil {
+			handleError(ctx, err)
+			return
+		}
+
+		data, err := exporter.Export(locale)
+		if errs, ok := err.(*apiErrors.MultiError); ok {
+			render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+			return
+		}
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		path, err := export.BundlePath(i18nType, locale.Ident)
+		if err != nil {
+			handleError(ctx, apiErrors.ErrUnprocessable)
+			return
+		}
+		files[path] = data
+	}
+
+	filename := fmt.Sprintf("%s_%s.zip", unsafeFilenameChars.ReplaceAllString(project.Name, "_"), exporter.FileExtension())
+	if opts.pseudo {
+		filename = fmt.Sprintf("%s_%s-pseudo.zip", unsafeFilenameChars.ReplaceAllString(project.Name, "_"), exporter.FileExtension())
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+	// Entries are written in order, so that the same export gives the same archive
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	archive := zip.NewWriter(ctx.ResponseWriter())
+	for _, path := range paths {
+		w, err := archive.Create(path)
+		if err != nil {
+			ctx.Application().Logger().Errorf("failed to write export archive: %v", err)
+			return
+		}
+		if _, err := w.Write(files[path]); err != nil {
+			ctx.Application().Logger().Errorf("failed to write export archive: %v", err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		ctx.Application().Logger().Errorf("failed to write export archive: %v", err)
+	}
+}
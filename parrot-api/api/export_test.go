@@ -0,0 +1,158 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/translate"
+)
+
+func TestSourceValues(t *testing.T) {
+	loc := &model.Locale{
+		Ident:   "pl_PL",
+		Pairs:   map[string]string{"a": "przetłumaczone"},
+		Plurals: map[string]model.PluralForms{"items": {"one": "", "few": "", "many": "", "other": ""}}}
+	source := &model.Locale{
+		Ident:    "en_US",
+		Pairs:    map[string]string{"a": "translated"},
+		Statuses: map[string]string{"a": model.StatusApproved},
+		Plurals:  map[string]model.PluralForms{"items": {"one": "an item", "other": "items"}}}
+
+	result := sourceValues(loc, source)
+	if result.Ident != "pl_PL" || result.Pairs["a"] != "translated" || result.Statuses["a"] != model.StatusApproved {
+		t.Errorf("expected the values of the source under the locale's ident, got %+v", result)
+	}
+	expected := model.PluralForms{"one": "an item", "few": "items", "many": "items", "other": "items"}
+	if !result.Plurals["items"].Equal(expected) || len(result.Plurals["items"]) != 4 {
+		t.Errorf("expected %v, got %v", expected, result.Plurals["items"])
+	}
+	if loc.Pairs["a"] != "przetłumaczone" || source.Plurals["items"]["few"] != "" {
+		t.Error("expected the locales to be left unchanged")
+	}
+}
+
+func TestExportLocalePseudo(t *testing.T) {
+	previous := store
+	defer func() { store = previous }()
+	db := memory.New()
+	store = db
+
+	project, err := db.CreateProject(model.Project{Name: "project", Keys: []string{"greeting"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ident, value := range map[string]string{"en_US": "Hello", "de_DE": "", "fr_FR": "Bonjour"} {
+		loc := model.Locale{Ident: ident, ProjectID: project.ID, Pairs: map[string]string{"greeting": value}}
+		if _, err := db.CreateLocale(loc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := iris.New()
+	app.Get("/{projectID}/locales/{localeIdent}/export/{type}", exportLocale)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	get := func(query string) (int, map[string]string) {
+		req := httptest.NewRequest("GET", "/"+project.ID+"/locales/de_DE/export/keyvaluejson?pseudo=true"+query, nil)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		var pairs map[string]string
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &pairs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, pairs
+	}
+
+	// Without a source locale, there is nothing to pseudo-localize
+	if status, _ := get(""); status != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, status)
+	}
+
+	status, pairs := get("&source=fr_FR")
+	if expected := translate.Pseudolocalize("Bonjour", translate.DefaultExpansion); status != http.StatusOK || pairs["greeting"] != expected {
+		t.Errorf("expected %q, got status %d and %v", expected, status, pairs)
+	}
+
+	if _, err := db.UpdateProjectSourceLocale(project.ID, "en_US"); err != nil {
+		t.Fatal(err)
+	}
+	status, pairs = get("")
+	if expected := translate.Pseudolocalize("Hello", translate.DefaultExpansion); status != http.StatusOK || pairs["greeting"] != expected {
+		t.Errorf("expected %q, got status %d and %v", expected, status, pairs)
+	}
+}
+
+func TestExportProjectPseudo(t *testing.T) {
+	previous := store
+	defer func() { store = previous }()
+	db := memory.New()
+	store = db
+
+	project, err := db.CreateProject(model.Project{Name: "project", Keys: []string{"greeting"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ident, value := range map[string]string{"en_US": "Hello", "de_DE": "", "fr_FR": "Bonjour"} {
+		loc := model.Locale{Ident: ident, ProjectID: project.ID, Pairs: map[string]string{"greeting": value}}
+		if _, err := db.CreateLocale(loc, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.UpdateProjectSourceLocale(project.ID, "en_US"); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Get("/{projectID}/export/{type}", exportProject)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/"+project.ID+"/export/keyvaluejson?pseudo=true", nil)
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != 3 {
+		t.Fatalf("expected 3 archive entries, got %d", len(archive.File))
+	}
+	expected := translate.Pseudolocalize("Hello", translate.DefaultExpansion)
+	for i, f := range archive.File {
+		if i > 0 && archive.File[i-1].Name >= f.Name {
+			t.Errorf("expected sorted entries, got %q before %q", archive.File[i-1].Name, f.Name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pairs map[string]string
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			t.Fatal(err)
+		}
+		if pairs["greeting"] != expected {
+			t.Errorf("%s: expected %q, got %v", f.Name, expected, pairs)
+		}
+	}
+}
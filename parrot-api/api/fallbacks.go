@@ -0,0 +1,103 @@
+package api
+
+import (
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// getProjectFallbacks is an API endpoint for retrieving the fallback chain of every
+// project locale, whether set on the project or derived from the locale languages.
+func getProjectFallbacks(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	locales, err := store.GetProjectLocales(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	idents := localeIdents(locales)
+	result := make(map[string][]string, len(idents))
+	for _, ident := range idents {
+		chain := project.FallbackChain(ident, idents)
+		if chain == nil {
+			chain = []string{}
+		}
+		result[ident] = chain
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// updateProjectFallbacks is an API endpoint for setting the fallback chains of project locales.
+// Locales left out of the payload use the chains derived from their language.
+func updateProjectFallbacks(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project := model.Project{}
+	if err := ctx.ReadJSON(&project.Fallbacks); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	locales, err := store.GetProjectLocales(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if errs := project.ValidateFallbacks(localeIdents(locales)); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	result, err := store.UpdateProjectFallbacks(projectID, project.Fallbacks)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// fallbackLocales returns the locales of the fallback chain of the locale with the
+// provided ident, in order, out of the provided project locales.
+func fallbackLocales(project *model.Project, ident string, locales []model.Locale) []model.Locale {
+	byIdent := make(map[string]model.Locale, len(locales))
+	for _, loc := range locales {
+		byIdent[loc.Ident] = loc
+	}
+
+	chain := project.FallbackChain(ident, localeIdents(locales))
+	result := make([]model.Locale, len(chain))
+	for i, f := range chain {
+		result[i] = byIdent[f]
+	}
+	return result
+}
+
+// localeIdents returns the idents of the locales.
+func localeIdents(locales []model.Locale) []string {
+	idents := make([]string, len(locales))
+	for i, loc := range locales {
+		idents[i] = loc.Ident
+	}
+	return idents
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strings"
+
 	"github.com/kataras/iris/v12"
 	"github.com/iris-contrib/parrot/parrot-api/render"
 )
@@ -25,6 +27,12 @@ func isValidContentType(ct string) bool {
 	return false
 }
 
+// isMultipartContentType returns true if the provided content type
+// is a multipart form, regardless of its boundary parameter.
+func isMultipartContentType(ct string) bool {
+	return strings.HasPrefix(ct, "multipart/form-data")
+}
+
 // ping is an API endpoint for checking if the API is up.
 func ping(ctx iris.Context) {
 	render.JSON(ctx, 200, map[string]interface{}{
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// newJournal returns a journal for the history of a change made on behalf of the
+// requesting subject. The store writes it along with the change.
+func newJournal(ctx iris.Context) (*model.Journal, error) {
+	subID, err := getSubjectID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subType, err := getSubjectType(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Journal{SubjectID: subID, SubjectType: string(subType)}, nil
+}
+
+// getLocaleHistory is an API endpoint for retrieving the change history of a locale,
+// newest first. Results can be filtered with the 'key' query param.
+func getLocaleHistory(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	ident := ctx.Params().Get("localeIdent")
+	if ident == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	key := ctx.Request().URL.Query().Get("key")
+
+	// Make sure the locale exists, otherwise an empty history would be misleading
+	if _, err := store.GetProjectLocaleByIdent(projectID, ident); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	result, err := store.GetLocaleHistory(projectID, ident, key)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// revertLocalePair is an API endpoint for restoring a locale pair to the value
+// it was set to by a previous revision. The revert is itself recorded in the history.
+// A locale changed while the revert is checked fails it with a 412 status.
+func revertLocalePair(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	ident := ctx.Params().Get("localeIdent")
+	if ident == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	revisionID := ctx.Params().Get("revisionID")
+	if revisionID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	revision, err := store.GetHistoryEntry(projectID, revisionID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if revision.LocaleIdent != ident || revision.Action != model.HistoryPairUpdated {
+		handleError(ctx, apiErrors.ErrNotFound)
+		return
+	}
+
+	loc, err := store.GetProjectLocaleByIdent(projectID, ident)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	loc.SyncKeys(project.Keys)
+	if _, ok := loc.Pairs[revision.Key]; !ok {
+		// The key has since been deleted or renamed
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+	loc.Pairs[revision.Key] = revision.NewValue
+
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if errs := loc.ValidateLength(keys); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	// Only the reverted value is written, and only to the version that was checked
+	result, err := store.MergeLocalePairs(projectID, ident, map[string]string{revision.Key: revision.NewValue}, loc.Version, j)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/export"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// importLocale is an API endpoint for importing locale pairs from an uploaded file.
+// Keys not yet in the project are added to it. Existing translations are kept
+// unless the 'overwrite' query param is set to true. Nested keys are joined with
+// the project's key delimiter. Files holding plural forms for existing keys that
+// aren't plural are rejected.
+func importLocale(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	localeIdent := ctx.Params().Get("localeIdent")
+	if localeIdent == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	i18nType := ctx.Params().Get("type")
+	if i18nType == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	overwrite := ctx.Request().URL.Query().Get("overwrite") == "true"
+
+	importer, ok := export.NewImporter(i18nType)
+	if !ok {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	file, _, err := ctx.FormFile("file")
+	if err != nil {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if nester, ok := importer.(export.Nester); ok {
+		nester.SetKeyDelimiter(project.KeyDelimiter)
+	}
+
+	imported, err := importer.Import(data)
+	if errs, ok := err.(*apiErrors.MultiError); ok {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+	if err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	// Reject files holding values that are longer than their keys allow
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if errs := imported.ValidateLength(keys); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	// Add any keys that the project doesn't know about yet
+	known := make(map[string]bool, len(project.Keys))
+	for _, k := range project.Keys {
+		known[k] = true
+	}
+
+	// Plural forms for an existing key that isn't plural would turn it into a plural
+	// key, so the file is rejected instead
+	var conflicts []apiErrors.Error
+	for k := range imported.Plurals {
+		if known[k] && !project.IsPluralKey(k) {
+			conflicts = append(conflicts, apiErrors.Error{
+				Type:    model.ErrInvalidPluralKey.Type,
+				Message: fmt.Sprintf("key '%s' is not a plural key of the project", k)})
+		}
+	}
+	if conflicts != nil {
+		sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Message < conflicts[j].Message })
+		render.Error(ctx, iris.StatusUnprocessableEntity, model.NewValidationError(conflicts))
+		return
+	}
+	var newKeys, newPluralKeys []string
+	for k := range imported.Pairs {
+		if k == "" || known[k] {
+			continue
+		}
+		known[k] = true
+		newKeys = append(newKeys, k)
+	}
+	for k := range imported.Plurals {
+		if k != "" && !known[k] {
+			known[k] = true
+			newKeys = append(newKeys, k)
+			newPluralKeys = append(newPluralKeys, k)
+		}
+	}
+	sort.Strings(newKeys)
+	sort.Strings(newPluralKeys)
+
+	// The keys are added and the values merged into the stored locale in a single
+	// transaction, while it's locked, so that no change made since it was read is lost
+	var pairsUpdated, pluralsUpdated int
+	merge := func(project *model.Project, loc *model.Locale) error {
+		merged := loc.Copy()
+		merged.SyncKeys(project.Keys)
+		pairsUpdated = merged.MergePairs(imported.Pairs, overwrite)
+		loc.SetPairs(merged.Pairs)
+
+		merged.SyncPluralKeys(project.PluralKeys)
+		pluralsUpdated = merged.MergePlurals(imported.Plurals, overwrite)
+		if pluralsUpdated > 0 {
+			loc.SetPlurals(merged.Plurals)
+		}
+		return nil
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	result, added, err := store.ImportLocale(projectID, localeIdent, newKeys, newPluralKeys, merge, j)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, map[string]interface{}{
+		"keysAdded":      len(added),
+		"pairsUpdated":   pairsUpdated,
+		"pluralsUpdated": pluralsUpdated,
+		"locale":         result,
+	})
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// keyMetaPayload holds the metadata fields of a key. Fields left out are not changed.
+type keyMetaPayload struct {
+	Description *string  `json:"description"`
+	Context     *string  `json:"context"`
+	MaxLength   *int     `json:"max_length"`
+	Tags        []string `json:"tags"`
+}
+
+// keyUpdatePayload holds the fields of a key that can be updated, its metadata
+// and whether it holds plural forms.
+type keyUpdatePayload struct {
+	Plural *bool `json:"plural"`
+	keyMetaPayload
+}
+
+// apply copies the provided fields to the key.
+func (p *keyMetaPayload) apply(k *model.Key) {
+	if p.Description != nil {
+		k.Description = strings.TrimSpace(*p.Description)
+	}
+	if p.Context != nil {
+		k.Context = strings.TrimSpace(*p.Context)
+	}
+	if p.MaxLength != nil {
+		k.MaxLength = *p.MaxLength
+	}
+	if p.Tags != nil {
+		k.Tags = p.Tags
+	}
+	k.SanitizeTags()
+}
+
+// isEmpty returns true if no metadata field was provided.
+func (p *keyMetaPayload) isEmpty() bool {
+	return p.Description == nil && p.Context == nil && p.MaxLength == nil && p.Tags == nil
+}
+
+// getProjectKeys is an API endpoint for retrieving the metadata of every project key.
+func getProjectKeys(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	// Make sure the project exists, otherwise an empty list would be misleading
+	if _, err := store.GetProject(projectID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	result, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// showProjectKey is an API endpoint for retrieving the metadata of a project key.
+func showProjectKey(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	key := ctx.Params().Get("key")
+	if key == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	result, err := store.GetProjectKey(projectID, key)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// updateProjectKeyMeta is an API endpoint for updating the metadata of a project key,
+// and whether it holds plural forms.
+func updateProjectKeyMeta(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	key := ctx.Params().Get("key")
+	if key == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = keyUpdatePayload{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	k, err := store.GetProjectKey(projectID, key)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	data.apply(k)
+	if errs := k.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	if data.Plural != nil {
+		j, err := newJournal(ctx)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		if _, err := store.SetProjectKeyPlural(projectID, key, *data.Plural, j); err != nil {
+			handleError(ctx, err)
+			return
+		}
+	}
+	if data.isEmpty() {
+		render.JSON(ctx, iris.StatusOK, k)
+		return
+	}
+
+	result, err := store.UpdateProjectKeyMeta(*k)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// getKeyDescriptions returns the descriptions of the project keys, by key.
+func getKeyDescriptions(projectID string) (map[string]string, error) {
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return model.KeyDescriptions(keys), nil
+}
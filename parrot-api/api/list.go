@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+var (
+	// maxListLimit caps the number of items of a page.
+	maxListLimit = 500
+	// localeFields lists the fields that can be selected on the locale list.
+	localeFields = []string{"id", "ident", "language", "country", "pairs", "plurals", "statuses", "project_id"}
+)
+
+// listOptions parses the 'limit', 'cursor' and 'sort' query params of a list.
+// The sort param names one of the fields, prefixed with '-' for descending order.
+// Without a limit the whole list is returned. A cursor keeps the sort order of
+// the page it was returned with.
+func listOptions(ctx iris.Context, fields []string) (model.ListOptions, error) {
+	query := ctx.Request().URL.Query()
+	opts := model.ListOptions{Sort: fields[0]}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			return opts, apiErrors.ErrBadRequest
+		}
+		opts.Limit = n
+	}
+
+	if v := query.Get("sort"); v != "" {
+		opts.Desc = strings.HasPrefix(v, "-")
+		opts.Sort = strings.TrimPrefix(v, "-")
+		if !contains(fields, opts.Sort) {
+			return opts, apiErrors.ErrBadRequest
+		}
+	}
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := model.DecodeCursor(v)
+		if err != nil {
+			return opts, err
+		}
+		if query.Get("sort") != "" && (cursor.Sort != opts.Sort || cursor.Desc != opts.Desc) {
+			return opts, model.ErrInvalidCursor
+		}
+		if !contains(fields, cursor.Sort) {
+			return opts, model.ErrInvalidCursor
+		}
+		opts.Sort, opts.Desc, opts.After = cursor.Sort, cursor.Desc, cursor
+	}
+
+	return opts, nil
+}
+
+// renderPage writes a page of a list along with the cursor of the next one.
+func renderPage(ctx iris.Context, opts model.ListOptions, next *model.Cursor, payload interface{}) {
+	page := render.Page{Limit: opts.Limit}
+	if next != nil {
+		page.NextCursor = next.Encode()
+	}
+	render.JSONPage(ctx, iris.StatusOK, page, payload)
+}
+
+// listFields parses the comma separated 'fields' query param, which selects
+// the fields of the listed items. It returns nil if all fields are selected.
+func listFields(ctx iris.Context, allowed []string) ([]string, error) {
+	v := ctx.Request().URL.Query().Get("fields")
+	if v == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if !contains(allowed, f) {
+			return nil, apiErrors.ErrBadRequest
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// selectFields returns the JSON objects of the items, a slice, keeping only the fields.
+// It returns the items unchanged if fields is nil.
+func selectFields(items interface{}, fields []string) (interface{}, error) {
+	if fields == nil {
+		return items, nil
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &objects); err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]json.RawMessage, len(objects))
+	for i, o := range objects {
+		result[i] = make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := o[f]; ok {
+				result[i][f] = v
+			}
+		}
+	}
+	return result, nil
+}
+
+// contains returns true if s holds v.
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
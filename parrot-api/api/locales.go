@@ -1,8 +1,12 @@
 package api
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/kataras/iris/v12"
 
+	datastoreErrors "github.com/iris-contrib/parrot/parrot-api/datastore/errors"
 	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
 	"github.com/iris-contrib/parrot/parrot-api/model"
 	"github.com/iris-contrib/parrot/parrot-api/render"
@@ -31,8 +35,14 @@ func createLocale(ctx iris.Context) {
 	}
 
 	loc.SyncKeys(proj.Keys)
+	loc.SyncPluralKeys(proj.PluralKeys)
 
-	result, err := store.CreateLocale(loc)
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	result, err := store.CreateLocale(loc, j)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -41,7 +51,9 @@ func createLocale(ctx iris.Context) {
 	render.JSON(ctx, iris.StatusCreated, result)
 }
 
-// showLocale is an API endpoint for retrieving a project locale by ident.
+// showLocale is an API endpoint for retrieving a project locale by ident. If the
+// project has a source locale, or the 'source' query param selects one, the issues
+// found by checking the locale against it are included.
 func showLocale(ctx iris.Context) {
 	projectID := ctx.Params().Get("projectID")
 	if projectID == "" {
@@ -67,11 +79,26 @@ func showLocale(ctx iris.Context) {
 	}
 
 	loc.SyncKeys(proj.Keys)
+	loc.SyncPluralKeys(proj.PluralKeys)
+	loc.SyncStatuses()
+
+	headers := map[string]string{"ETag": localeETag(loc)}
+	source, err := checkSource(ctx, proj)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if source != nil {
+		render.JSONWithHeaders(ctx, iris.StatusOK, headers, checkedLocale{loc, loc.Check(source)})
+		return
+	}
 
-	render.JSON(ctx, iris.StatusOK, loc)
+	render.JSONWithHeaders(ctx, iris.StatusOK, headers, loc)
 }
 
 // findLocales is an API endpoint for retrieving project locales and filtering by ident.
+// The list is paginated and the 'fields' query param selects the fields of the locales,
+// the pairs aren't loaded unless selected.
 func findLocales(ctx iris.Context) {
 	projectID := ctx.Params().Get("projectID")
 	if projectID == "" {
@@ -79,9 +106,23 @@ func findLocales(ctx iris.Context) {
 		return
 
 	}
-	localeIdents := ctx.Request().URL.Query()["ident"]
+	opts, err := listOptions(ctx, model.LocaleSortFields)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	fields, err := listFields(ctx, localeFields)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
 
-	locs, err := store.GetProjectLocales(projectID, localeIdents...)
+	q := model.LocaleQuery{
+		ListOptions:  opts,
+		Idents:       ctx.Request().URL.Query()["ident"],
+		WithoutPairs: fields != nil && !contains(fields, "pairs") && !contains(fields, "plurals") && !contains(fields, "statuses"),
+	}
+	locs, next, err := store.FindProjectLocales(projectID, q)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -93,14 +134,30 @@ func findLocales(ctx iris.Context) {
 		return
 	}
 
-	for i := range locs {
-		locs[i].SyncKeys(project.Keys)
+	if !q.WithoutPairs {
+		for i := range locs {
+			locs[i].SyncKeys(project.Keys)
+			locs[i].SyncPluralKeys(project.PluralKeys)
+			locs[i].SyncStatuses()
+		}
 	}
 
-	render.JSON(ctx, iris.StatusOK, locs)
+	result, err := selectFields(locs, fields)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	renderPage(ctx, opts, next, result)
 }
 
 // updateLocalePairs is an API endpoint for updating a locale's key value pairs.
+// The pairs replace the locale's ones, unless the 'merge' query param is set, then
+// only the keys of the request are updated. If the 'If-Match' header holds no
+// ETag of the current version of the locale, the update fails. In strict mode,
+// set with the 'strict' query param, the changed values are checked against the
+// project's source locale, or the one selected by the 'source' query param, and
+// any issue rejects the update.
 func updateLocalePairs(ctx iris.Context) {
 	ident := ctx.Params().Get("localeIdent")
 	if ident == "" {
@@ -113,6 +170,9 @@ func updateLocalePairs(ctx iris.Context) {
 		return
 	}
 
+	merge := ctx.Request().URL.Query().Get("merge") == "true"
+	strict := ctx.Request().URL.Query().Get("strict") == "true"
+
 	loc := &model.Locale{}
 
 	if err := ctx.ReadJSON(&loc.Pairs); err != nil {
@@ -126,14 +186,210 @@ func updateLocalePairs(ctx iris.Context) {
 		return
 	}
 
+	if merge {
+		// Keys that aren't in the project are ignored, like when replacing the pairs
+		for k := range loc.Pairs {
+			if !contains(project.Keys, k) {
+				delete(loc.Pairs, k)
+			}
+		}
+	} else {
+		loc.SyncKeys(project.Keys)
+	}
+
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if errs := loc.ValidateLength(keys); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	current, err := store.GetProjectLocaleByIdent(projectID, ident)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	version, err := ifMatchVersion(ctx, current)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if strict {
+		source, err := checkSource(ctx, project)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		if source == nil {
+			handleError(ctx, apiErrors.ErrBadRequest)
+			return
+		}
+		// Only the changed values are checked, so that existing issues don't block updates
+		changed := &model.Locale{Pairs: make(map[string]string)}
+		for k, v := range loc.Pairs {
+			if current.Pairs[k] != v {
+				changed.Pairs[k] = v
+			}
+		}
+		if errs := model.NewIssuesError(changed.Check(source)); errs != nil {
+			render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+			return
+		}
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	var result *model.Locale
+	if merge {
+		result, err = store.MergeLocalePairs(projectID, ident, loc.Pairs, version, j)
+	} else {
+		result, err = store.UpdateLocalePairs(projectID, ident, loc.Pairs, version, j)
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSONWithHeaders(ctx, iris.StatusOK, map[string]string{"ETag": localeETag(result)}, result)
+}
+
+// updateLocalePlurals is an API endpoint for updating the plural forms of a locale's plural keys.
+// As with the pairs, the update fails if the 'If-Match' header holds no ETag of the
+// current version of the locale.
+func updateLocalePlurals(ctx iris.Context) {
+	ident := ctx.Params().Get("localeIdent")
+	if ident == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	loc, err := store.GetProjectLocaleByIdent(projectID, ident)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	version, err := ifMatchVersion(ctx, loc)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	loc.Plurals = nil
+	if err := ctx.ReadJSON(&loc.Plurals); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if errs := loc.ValidatePlurals(project.PluralKeys); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+	loc.SyncPluralKeys(project.PluralKeys)
+
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if errs := loc.ValidateLength(keys); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	result, err := store.UpdateLocalePlurals(projectID, ident, loc.Plurals, version, j)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSONWithHeaders(ctx, iris.StatusOK, map[string]string{"ETag": localeETag(result)}, result)
+}
+
+// updateLocaleStatuses is an API endpoint for changing the review status of a locale's pairs.
+// Approving values requires the right to approve locales.
+func updateLocaleStatuses(ctx iris.Context) {
+	ident := ctx.Params().Get("localeIdent")
+	if ident == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var statuses map[string]string
+	if err := ctx.ReadJSON(&statuses); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	role, err := getRequesterRole(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	for _, s := range statuses {
+		if s == model.StatusApproved && !isAllowed(role, canApproveLocales) {
+			handleError(ctx, apiErrors.ErrForbiden)
+			return
+		}
+	}
+
+	loc, err := store.GetProjectLocaleByIdent(projectID, ident)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
 	loc.SyncKeys(project.Keys)
+	loc.SyncPluralKeys(project.PluralKeys)
+	if errs := loc.ValidateStatuses(statuses); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
 
-	result, err := store.UpdateLocalePairs(projectID, ident, loc.Pairs)
+	result, err := store.UpdateLocaleStatuses(projectID, ident, statuses)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
+	result.SyncKeys(project.Keys)
+	result.SyncPluralKeys(project.PluralKeys)
+	result.SyncStatuses()
+
 	render.JSON(ctx, iris.StatusOK, result)
 }
 
@@ -150,7 +406,22 @@ func deleteLocale(ctx iris.Context) {
 		return
 	}
 
-	err := store.DeleteLocale(projectID, ident)
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if project.SourceLocale == ident {
+		handleError(ctx, apiErrors.ErrSourceLocaleInUse)
+		return
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	err = store.DeleteLocale(projectID, ident, j)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -158,3 +429,39 @@ func deleteLocale(ctx iris.Context) {
 
 	render.JSON(ctx, iris.StatusNoContent, nil)
 }
+
+// localeETag returns the entity tag of the locale's version.
+func localeETag(loc *model.Locale) string {
+	return strconv.Quote(strconv.Itoa(loc.Version))
+}
+
+// ifMatchVersion returns the version of the current locale if it matches one
+// of the ETags in the 'If-Match' header, zero if the header is missing or matches
+// any version. ETags are compared strongly, so weak ones never match, and the
+// update fails with ErrStaleVersion when none does.
+func ifMatchVersion(ctx iris.Context, current *model.Locale) (int, error) {
+	v := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+
+	matched := false
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		opaque := strings.TrimPrefix(tag, "W/")
+		if !strings.HasPrefix(opaque, `"`) {
+			return 0, apiErrors.ErrBadRequest
+		}
+		value, err := strconv.Unquote(opaque)
+		if err != nil {
+			return 0, apiErrors.ErrBadRequest
+		}
+		if opaque == tag && value == strconv.Itoa(current.Version) {
+			matched = true
+		}
+	}
+	if !matched {
+		return 0, datastoreErrors.ErrStaleVersion
+	}
+	return current.Version, nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris/v12"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func TestUpdateLocalePairsIfMatch(t *testing.T) {
+	previous := store
+	defer func() { store = previous }()
+	db := memory.New()
+	store = db
+
+	project, err := db.CreateProject(model.Project{Name: "project", Keys: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateLocale(model.Locale{Ident: "en", ProjectID: project.ID, Pairs: map[string]string{"a": ""}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Patch("/{projectID}/locales/{localeIdent}/pairs", func(ctx iris.Context) {
+		ctx.Values().Set("subjectID", "jane")
+		ctx.Values().Set("subjectType", "user")
+		ctx.Next()
+	}, updateLocalePairs)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	status := func(ifMatch, value string) int {
+		req := httptest.NewRequest("PATCH", "/"+project.ID+"/locales/en/pairs", strings.NewReader(`{"a": "`+value+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// The locale is at version 1, and each successful update increments it
+	tests := []struct {
+		ifMatch  string
+		expected int
+	}{
+		{`"1"`, http.StatusOK},
+		{`"1"`, http.StatusPreconditionFailed},
+		{`W/"2"`, http.StatusPreconditionFailed},
+		{`"1", W/"2"`, http.StatusPreconditionFailed},
+		{`"1", "2"`, http.StatusOK},
+		{`"abc", "3"`, http.StatusOK},
+		{`*`, http.StatusOK},
+		{``, http.StatusOK},
+		{`5`, http.StatusBadRequest},
+		{`"6", 5`, http.StatusBadRequest},
+	}
+	for i, tt := range tests {
+		if got := status(tt.ifMatch, strconv.Itoa(i)); got != tt.expected {
+			t.Errorf("If-Match %s: expected status %d, got %d", tt.ifMatch, tt.expected, got)
+		}
+	}
+}
+
+func TestUpdateLocalePluralsIfMatch(t *testing.T) {
+	previous := store
+	defer func() { store = previous }()
+	db := memory.New()
+	store = db
+
+	project, err := db.CreateProject(model.Project{Name: "project", Keys: []string{"items"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SetProjectKeyPlural(project.ID, "items", true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateLocale(model.Locale{Ident: "en", Language: "en", ProjectID: project.ID, Pairs: map[string]string{"items": ""}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	app := iris.New()
+	app.Patch("/{projectID}/locales/{localeIdent}/plurals", func(ctx iris.Context) {
+		ctx.Values().Set("subjectID", "jane")
+		ctx.Values().Set("subjectType", "user")
+		ctx.Next()
+	}, updateLocalePlurals)
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+	update := func(ifMatch, value string) *httptest.ResponseRecorder {
+		body := `{"items": {"one": "an item", "other": "` + value + `"}}`
+		req := httptest.NewRequest("PATCH", "/"+project.ID+"/locales/en/plurals", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := update(`"1"`, "items")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected status %d and ETag \"2\", got %d and %q: %s", http.StatusOK, rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+	if rec := update(`"1"`, "stale items"); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, rec.Code)
+	}
+	loc, err := db.GetProjectLocaleByIdent(project.ID, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := loc.Plurals["items"]["other"]; v != "items" {
+		t.Errorf("expected the stale update to be refused, got %q", v)
+	}
+}
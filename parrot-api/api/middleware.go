@@ -8,12 +8,13 @@ import (
 
 // enforceContentTypeJSON only allows requests that have the
 // Content-Type header set to a valid JSON mime type, unless
-// the body is empty (useful for 'verb' or 'action' requests).
+// the body is empty (useful for 'verb' or 'action' requests)
+// or is a multipart form used to upload files.
 func enforceContentTypeJSON(ctx iris.Context) {
 	switch ctx.Method() {
 	case "POST", "PUT", "PATCH":
 		ct := ctx.GetHeader("Content-Type")
-		if !isValidContentType(ct) && ctx.Request().ContentLength > 0 {
+		if !isValidContentType(ct) && !isMultipartContentType(ct) && ctx.Request().ContentLength > 0 {
 			handleError(ctx, apiErrors.ErrUnsupportedMediaType)
 			return
 		}
@@ -23,13 +23,19 @@ func getProjectClients(ctx iris.Context) {
 		return
 	}
 
-	result, err := store.GetProjectClients(projectID)
+	opts, err := listOptions(ctx, model.ProjectClientSortFields)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
-	render.JSON(ctx, iris.StatusOK, result)
+	result, next, err := store.GetProjectClients(projectID, opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	renderPage(ctx, opts, next, result)
 }
 
 // getProjectClient is an API endpoint for retrieving a project client.
@@ -138,6 +144,37 @@ func updateProjectClientName(ctx iris.Context) {
 	render.JSON(ctx, iris.StatusOK, result)
 }
 
+// updateProjectClientCanAddKeys is an API endpoint for allowing a project client to add
+// keys to the project, as done by 'parrot push', or taking the right back.
+func updateProjectClientCanAddKeys(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	clientID := ctx.Params().Get("clientID")
+	if clientID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	pc := model.ProjectClient{}
+	if err := ctx.ReadJSON(&pc); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+	pc.ProjectID = projectID
+	pc.ClientID = clientID
+
+	result, err := store.UpdateProjectClientCanAddKeys(pc)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
 // resetProjectClientSecret is an API endpoint for regenerating a project client's secret.
 func resetProjectClientSecret(ctx iris.Context) {
 	projectID := ctx.Params().Get("projectID")
@@ -11,7 +11,9 @@ import (
 )
 
 type projectKeyPayload struct {
-	Key string `json:"key"`
+	Key    string `json:"key"`
+	Plural bool   `json:"plural"`
+	keyMetaPayload
 }
 
 type projectKeyUpdatePayload struct {
@@ -73,7 +75,73 @@ func updateProjectName(ctx iris.Context) {
 	render.JSON(ctx, iris.StatusOK, result)
 }
 
+// updateProjectSourceLocale is an API endpoint for setting the source locale of a
+// project, the locale the others are translated from. An empty ident unsets it.
+func updateProjectSourceLocale(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project := model.Project{}
+	if err := ctx.ReadJSON(&project); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+	project.SourceLocale = strings.TrimSpace(project.SourceLocale)
+
+	locales, err := store.GetProjectLocales(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	if errs := project.ValidateSourceLocale(localeIdents(locales)); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	result, err := store.UpdateProjectSourceLocale(projectID, project.SourceLocale)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// updateProjectKeyDelimiter is an API endpoint for setting the delimiter that splits
+// the project's keys into levels when exporting them as nested objects.
+func updateProjectKeyDelimiter(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project := model.Project{}
+	if err := ctx.ReadJSON(&project); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	if errs := project.ValidateKeyDelimiter(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	result, err := store.UpdateProjectKeyDelimiter(projectID, project.KeyDelimiter)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
 // addProjectKey is an API endpoint for adding keys ('strings') to a project.
+// The key's metadata can be provided along with it.
 func addProjectKey(ctx iris.Context) {
 	projectID := ctx.Params().Get("projectID")
 	if projectID == "" {
@@ -94,7 +162,19 @@ func addProjectKey(ctx iris.Context) {
 
 	data.Key = strings.Trim(data.Key, " ")
 
-	result, err := store.AddProjectKey(projectID, data.Key)
+	key := model.Key{ProjectID: projectID, Key: data.Key, Plural: data.Plural}
+	data.apply(&key)
+	if errs := key.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	result, err := store.AddProjectKey(key, j)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -125,7 +205,12 @@ func updateProjectKey(ctx iris.Context) {
 
 	data.NewKey = strings.Trim(data.NewKey, "")
 
-	project, localesAffected, err := store.UpdateProjectKey(projectID, data.OldKey, data.NewKey)
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	project, localesAffected, err := store.UpdateProjectKey(projectID, data.OldKey, data.NewKey, j)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -140,6 +225,7 @@ func updateProjectKey(ctx iris.Context) {
 }
 
 // deleteProjectKey is an API endpoint for deleting keys ('strings') from a project.
+// The key is taken from the path, or from the payload when the path has none.
 func deleteProjectKey(ctx iris.Context) {
 	projectID := ctx.Params().Get("projectID")
 	if projectID == "" {
@@ -147,10 +233,12 @@ func deleteProjectKey(ctx iris.Context) {
 		return
 	}
 
-	var data = projectKeyPayload{}
-	if err := ctx.ReadJSON(&data); err != nil {
-		handleError(ctx, err)
-		return
+	var data = projectKeyPayload{Key: ctx.Params().Get("key")}
+	if data.Key == "" {
+		if err := ctx.ReadJSON(&data); err != nil {
+			handleError(ctx, err)
+			return
+		}
 	}
 
 	if data.Key == "" {
@@ -158,7 +246,12 @@ func deleteProjectKey(ctx iris.Context) {
 		return
 	}
 
-	result, err := store.DeleteProjectKey(projectID, data.Key)
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	result, err := store.DeleteProjectKey(projectID, data.Key, j)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -17,13 +17,19 @@ func getUserProjects(ctx iris.Context) {
 		return
 	}
 
-	projects, err := store.GetUserProjects(id)
+	opts, err := listOptions(ctx, model.ProjectSortFields)
 	if err != nil {
 		handleError(ctx, err)
 		return
 	}
 
-	render.JSON(ctx, iris.StatusOK, projects)
+	projects, next, err := store.GetUserProjects(id, opts)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	renderPage(ctx, opts, next, projects)
 }
 
 // getProjectUsers is an API endpoint for retrieving all users with access to a project.
@@ -34,7 +40,13 @@ func getProjectUsers(ctx iris.Context) {
 		return
 	}
 
-	projectUsers, err := store.GetProjectUsers(projectID)
+	opts, err := listOptions(ctx, model.ProjectUserSortFields)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	projectUsers, next, err := store.GetProjectUsers(projectID, opts)
 	if err != nil {
 		handleError(ctx, err)
 		return
@@ -55,7 +67,7 @@ func getProjectUsers(ctx iris.Context) {
 		result = append(result, pu)
 	}
 
-	render.JSON(ctx, iris.StatusOK, result)
+	renderPage(ctx, opts, next, result)
 }
 
 // assignProjectUser is an API endpoint for giving an already registered user
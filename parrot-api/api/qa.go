@@ -0,0 +1,79 @@
+package api
+
+import (
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// checkedLocale is a locale along with the issues found by checking it against a source locale.
+type checkedLocale struct {
+	*model.Locale
+	Issues []model.Issue `json:"issues"`
+}
+
+// checkSource returns the locale selected by the 'source' query param, or else the
+// project's source locale, synced with the keys of the project, to check the
+// project's locales against. It returns nil if there is no source.
+func checkSource(ctx iris.Context, project *model.Project) (*model.Locale, error) {
+	ident := ctx.Request().URL.Query().Get("source")
+	if ident == "" {
+		ident = project.SourceLocale
+	}
+	if ident == "" {
+		return nil, nil
+	}
+
+	source, err := store.GetProjectLocaleByIdent(project.ID, ident)
+	if err != nil {
+		return nil, err
+	}
+	source.SyncKeys(project.Keys)
+	source.SyncPluralKeys(project.PluralKeys)
+
+	return source, nil
+}
+
+// getLocaleIssues is an API endpoint for checking the values of a locale against the
+// ones of the source locale, the project's one unless the 'source' query param
+// selects another. Mismatched placeholders, tags, whitespace and terminal
+// punctuation are reported.
+func getLocaleIssues(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	ident := ctx.Params().Get("localeIdent")
+	if ident == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	source, err := checkSource(ctx, project)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if source == nil {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	loc, err := store.GetProjectLocaleByIdent(projectID, ident)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	loc.SyncKeys(project.Keys)
+	loc.SyncPluralKeys(project.PluralKeys)
+
+	render.JSON(ctx, iris.StatusOK, loc.Check(source))
+}
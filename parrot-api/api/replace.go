@@ -0,0 +1,107 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// replacePayload is a find-and-replace request, previewed unless confirmed.
+type replacePayload struct {
+	model.Replace
+	Confirm bool `json:"confirm"`
+}
+
+// replaceLocaleValues is an API endpoint for replacing a pattern in the values of
+// several locales. Unless 'confirm' is set, it returns a preview of the changes
+// without applying them. Confirmed changes are applied in a single transaction.
+func replaceLocaleValues(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = replacePayload{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+	if errs := data.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	changes := make([]model.ValueChange, 0)
+	apply := func(loc *model.Locale) error {
+		c, err := data.Apply(loc)
+		if err != nil {
+			return err
+		}
+		if errs := loc.ValidateLength(keys); errs != nil {
+			return errs
+		}
+
+		changes = append(changes, c...)
+		return nil
+	}
+
+	if data.Confirm {
+		var j *model.Journal
+		j, err = newJournal(ctx)
+		if err == nil {
+			_, err = store.UpdateLocales(projectID, data.Locales, apply, j)
+		}
+	} else {
+		err = previewReplace(projectID, data.Locales, apply)
+	}
+	if err != nil {
+		if errs, ok := err.(*apiErrors.MultiError); ok {
+			render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+			return
+		}
+		handleError(ctx, err)
+		return
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Locale < changes[j].Locale })
+
+	render.JSON(ctx, iris.StatusOK, map[string]interface{}{
+		"applied": data.Confirm,
+		"changes": changes,
+	})
+}
+
+// previewReplace applies fn to copies of the locales with the idents, without storing them.
+func previewReplace(projectID string, localeIdents []string, fn func(*model.Locale) error) error {
+	locs, err := store.GetProjectLocales(projectID, localeIdents...)
+	if err != nil {
+		return err
+	}
+	for _, ident := range localeIdents {
+		found := false
+		for _, loc := range locs {
+			found = found || loc.Ident == ident
+		}
+		if !found {
+			return apiErrors.ErrNotFound
+		}
+	}
+
+	for i := range locs {
+		if err := fn(&locs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
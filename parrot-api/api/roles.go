@@ -14,11 +14,14 @@ type RoleGrant string
 
 // known roles
 const (
-	ownerRole     = "owner"
-	editorRole    = "editor"
-	viewerRole    = "viewer"
-	clientRole    = "client"
+	ownerRole  = "owner"
+	editorRole = "editor"
+	viewerRole = "viewer"
+	clientRole = "client"
+	// keyClientRole is the role of API clients allowed to add keys.
+	keyClientRole = "keyclient"
 	developerRole = "developer"
+	reviewerRole  = "reviewer"
 )
 
 // known grants
@@ -36,6 +39,12 @@ const (
 	canViewLocales        = "CanViewLocales"
 	canManageAPIClients   = "CanManageAPIClients"
 	canExportLocales      = "CanExportLocales"
+	canApproveLocales     = "CanApproveLocales"
+	canAddKeys            = "CanAddKeys"
+	canManageWebhooks     = "CanManageWebhooks"
+	// canManageTranslation allows setting the machine translation provider,
+	// which is sent the project's values along with its API key.
+	canManageTranslation = "CanManageTranslation"
 )
 
 // permissions mapping of Roles to Grants.
@@ -54,6 +63,9 @@ var permissions = map[Role][]RoleGrant{
 		canViewLocales,
 		canManageAPIClients,
 		canExportLocales,
+		canApproveLocales,
+		canManageWebhooks,
+		canManageTranslation,
 	},
 	editorRole: []RoleGrant{
 		canViewProjectRoles,
@@ -74,12 +86,26 @@ var permissions = map[Role][]RoleGrant{
 	clientRole: []RoleGrant{
 		canExportLocales,
 	},
+	keyClientRole: []RoleGrant{
+		canViewProject,
+		canExportLocales,
+		canAddKeys,
+	},
 	developerRole: []RoleGrant{
 		canViewProjectRoles,
 		canViewProject,
 		canViewLocales,
 		canExportLocales,
 		canManageAPIClients,
+		canManageWebhooks,
+		canManageTranslation,
+	},
+	reviewerRole: []RoleGrant{
+		canViewProjectRoles,
+		canViewProject,
+		canViewLocales,
+		canExportLocales,
+		canApproveLocales,
 	},
 }
 
@@ -87,7 +113,7 @@ var permissions = map[Role][]RoleGrant{
 func isRole(r string) bool {
 	v := Role(r)
 	switch v {
-	case ownerRole, editorRole, viewerRole, developerRole:
+	case ownerRole, editorRole, viewerRole, developerRole, reviewerRole:
 		return true
 	}
 	return false
@@ -117,63 +143,93 @@ func getProjectUserRole(projID, userID string) (string, error) {
 	return user.Role, nil
 }
 
-// mustBeProjectClient returns an error if no client with provided clientID
-// exists for a given project.
-func mustBeProjectClient(projID, clientID string) error {
+// getProjectClientRole returns the role of a client of the project, or an error
+// if no client with provided clientID exists for the project.
+func getProjectClientRole(projID, clientID string) (Role, error) {
 	client, err := store.GetProjectClient(projID, clientID)
-	if err != nil || client == nil {
-		return err
+	if err != nil {
+		return "", err
+	}
+	if client.CanAddKeys {
+		return keyClientRole, nil
 	}
-	return nil
+	return clientRole, nil
 }
 
 // mustAuthorize authorizes or denies requests based on required rights for action.
 // Identifies if requesting subject is able to perform action on the particular project.
 func mustAuthorize(action RoleGrant) iris.Handler {
+	return mustAuthorizeAny(action)
+}
+
+// mustAuthorizeAny authorizes requests from subjects that have at least one of the grants.
+func mustAuthorizeAny(actions ...RoleGrant) iris.Handler {
 	return func(ctx iris.Context) {
-		projectID := ctx.Params().Get("projectID")
-		if projectID == "" {
-			handleError(ctx, apiErrors.ErrBadRequest)
+		role, err := getRequesterRole(ctx)
+		if err != nil {
+			handleError(ctx, err)
 			return
 		}
 
-		subType, err := getSubjectType(ctx)
-		if err != nil {
-			handleError(ctx, apiErrors.ErrBadRequest)
-			return
+		for _, action := range actions {
+			if isAllowed(role, action) {
+				ctx.Next()
+				return
+			}
 		}
-		requesterID, err := getSubjectID(ctx)
+
+		handleError(ctx, apiErrors.ErrForbiden)
+	}
+}
+
+// mustAuthorizeAll authorizes requests from subjects that have every one of the grants.
+func mustAuthorizeAll(actions ...RoleGrant) iris.Handler {
+	return func(ctx iris.Context) {
+		role, err := getRequesterRole(ctx)
 		if err != nil {
-			handleError(ctx, apiErrors.ErrBadRequest)
+			handleError(ctx, err)
 			return
 		}
 
-		var requesterRole string
-
-		switch subType {
-		case userSubject:
-			requesterRole, err = getProjectUserRole(projectID, requesterID)
-			if err != nil {
-				handleError(ctx, err)
-				return
-			}
-		case clientSubject:
-			err := mustBeProjectClient(projectID, requesterID)
-			if err != nil {
-				handleError(ctx, err)
+		for _, action := range actions {
+			if !isAllowed(role, action) {
+				handleError(ctx, apiErrors.ErrForbiden)
 				return
 			}
-			requesterRole = clientRole
-		default:
-			handleError(ctx, apiErrors.ErrBadRequest)
-			return
-		}
-
-		if !isAllowed(Role(requesterRole), action) {
-			handleError(ctx, apiErrors.ErrForbiden)
-			return
 		}
 
 		ctx.Next()
 	}
 }
+
+// getRequesterRole returns the role of the requesting subject in the project
+// of the current request. API clients have the client role, or the key client role
+// if they are allowed to add keys.
+func getRequesterRole(ctx iris.Context) (Role, error) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		return "", apiErrors.ErrBadRequest
+	}
+
+	subType, err := getSubjectType(ctx)
+	if err != nil {
+		return "", apiErrors.ErrBadRequest
+	}
+	requesterID, err := getSubjectID(ctx)
+	if err != nil {
+		return "", apiErrors.ErrBadRequest
+	}
+
+	switch subType {
+	case userSubject:
+		role, err := getProjectUserRole(projectID, requesterID)
+		if err != nil {
+			return "", err
+		}
+		return Role(role), nil
+	case clientSubject:
+		return getProjectClientRole(projectID, requesterID)
+	}
+
+	return "", apiErrors.ErrBadRequest
+}
@@ -48,9 +48,13 @@ func NewRouter(ds datastore.Store, tp auth.TokenProvider) iris.Configurator {
 
 					r2.Patch("/name", mustAuthorize(canUpdateProject), updateProjectName)
 
-					r2.Post("/keys", mustAuthorize(canUpdateProject), addProjectKey)
+					r2.Post("/keys", mustAuthorizeAny(canUpdateProject, canAddKeys), addProjectKey)
 					r2.Patch("/keys", mustAuthorize(canUpdateProject), updateProjectKey)
 					r2.Delete("/keys", mustAuthorize(canUpdateProject), deleteProjectKey)
+					r2.Get("/keys", mustAuthorize(canViewProject), getProjectKeys)
+					r2.Get("/keys/{key:path}", mustAuthorize(canViewProject), showProjectKey)
+					r2.Patch("/keys/{key:path}", mustAuthorize(canUpdateProject), updateProjectKeyMeta)
+					r2.Delete("/keys/{key:path}", mustAuthorize(canUpdateProject), deleteProjectKey)
 
 					r2.PartyFunc("/users", func(r3 iris.Party) {
 						r3.Get("/", mustAuthorize(canViewProjectRoles), getProjectUsers)
@@ -65,9 +69,34 @@ func NewRouter(ds datastore.Store, tp auth.TokenProvider) iris.Configurator {
 						r3.Post("/", mustAuthorize(canManageAPIClients), createProjectClient)
 						r3.Patch("/{clientID}/resetSecret", mustAuthorize(canManageAPIClients), resetProjectClientSecret)
 						r3.Patch("/{clientID}/name", mustAuthorize(canManageAPIClients), updateProjectClientName)
+						r3.Patch("/{clientID}/canAddKeys", mustAuthorize(canManageAPIClients), updateProjectClientCanAddKeys)
 						r3.Delete("/{clientID}", mustAuthorize(canManageAPIClients), deleteProjectClient)
 					})
 
+					r2.PartyFunc("/webhooks", func(r3 iris.Party) {
+						r3.Get("/", mustAuthorize(canManageWebhooks), getProjectWebhooks)
+						r3.Post("/", mustAuthorize(canManageWebhooks), createProjectWebhook)
+						r3.Get("/{webhookID}", mustAuthorize(canManageWebhooks), showProjectWebhook)
+						r3.Patch("/{webhookID}", mustAuthorize(canManageWebhooks), updateProjectWebhook)
+						r3.Patch("/{webhookID}/resetSecret", mustAuthorize(canManageWebhooks), resetProjectWebhookSecret)
+						r3.Delete("/{webhookID}", mustAuthorize(canManageWebhooks), deleteProjectWebhook)
+						r3.Get("/{webhookID}/deliveries", mustAuthorize(canManageWebhooks), getWebhookDeliveries)
+					})
+
+					r2.Get("/translation", mustAuthorize(canManageTranslation), getTranslationConfig)
+					r2.Patch("/translation", mustAuthorize(canManageTranslation), updateTranslationConfig)
+					r2.Delete("/translation", mustAuthorize(canManageTranslation), deleteTranslationConfig)
+					r2.Post("/prefill", mustAuthorize(canUpdateLocales), prefillLocales)
+
+					r2.Get("/stats", mustAuthorize(canViewLocales), getProjectStats)
+					r2.Get("/fallbacks", mustAuthorize(canViewLocales), getProjectFallbacks)
+					r2.Get("/search", mustAuthorize(canViewLocales), searchProject)
+					r2.Post("/replace", mustAuthorize(canUpdateLocales), replaceLocaleValues)
+					r2.Patch("/fallbacks", mustAuthorize(canUpdateProject), updateProjectFallbacks)
+					r2.Patch("/source-locale", mustAuthorize(canUpdateProject), updateProjectSourceLocale)
+					r2.Patch("/key-delimiter", mustAuthorize(canUpdateProject), updateProjectKeyDelimiter)
+					r2.Get("/export/{type}", mustAuthorize(canExportLocales), exportProject)
+
 					r2.PartyFunc("/locales", func(r3 iris.Party) {
 						r3.Get("/", mustAuthorize(canViewLocales), findLocales)
 						r3.Post("/", mustAuthorize(canCreateLocales), createLocale)
@@ -75,9 +104,16 @@ func NewRouter(ds datastore.Store, tp auth.TokenProvider) iris.Configurator {
 						r3.PartyFunc("/{localeIdent}", func(r4 iris.Party) {
 							r4.Get("/", mustAuthorize(canViewLocales), showLocale)
 							r4.Patch("/pairs", mustAuthorize(canUpdateLocales), updateLocalePairs)
+							r4.Patch("/plurals", mustAuthorize(canUpdateLocales), updateLocalePlurals)
+							r4.Patch("/statuses", mustAuthorizeAny(canUpdateLocales, canApproveLocales), updateLocaleStatuses)
 							r4.Delete("/", mustAuthorize(canDeleteLocales), deleteLocale)
 
+							r4.Get("/issues", mustAuthorize(canViewLocales), getLocaleIssues)
+							r4.Get("/history", mustAuthorize(canViewLocales), getLocaleHistory)
+							r4.Post("/history/{revisionID}/revert", mustAuthorize(canUpdateLocales), revertLocalePair)
+
 							r4.Get("/export/{type}", mustAuthorize(canExportLocales), exportLocale)
+							r4.Post("/import/{type}", mustAuthorizeAll(canUpdateProject, canUpdateLocales), importLocale)
 						})
 					})
 
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// searchProject is an API endpoint for finding the keys whose name or values match
+// the 'q' query param, grouped by key. The search can be restricted to the locales
+// of the 'ident' query params, and the 'regex', 'ignore_case' and 'untranslated'
+// query params change how entries are matched.
+func searchProject(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	query := ctx.Request().URL.Query()
+	q := model.SearchQuery{
+		Text:         query.Get("q"),
+		Regex:        query.Get("regex") == "true",
+		IgnoreCase:   query.Get("ignore_case") == "true",
+		Locales:      query["ident"],
+		Untranslated: query.Get("untranslated") == "true",
+	}
+	if err := q.Validate(); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	result, err := store.SearchProject(projectID, q)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
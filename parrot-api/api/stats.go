@@ -0,0 +1,48 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+// getProjectStats is an API endpoint for retrieving the translation progress
+// of every project locale.
+func getProjectStats(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	locales, err := store.GetProjectLocales(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	result := model.ProjectStats{
+		ProjectID: projectID,
+		Keys:      len(project.Keys),
+		Locales:   make([]model.LocaleStats, len(locales))}
+	for i := range locales {
+		locales[i].SyncKeys(project.Keys)
+		locales[i].SyncPluralKeys(project.PluralKeys)
+		result.Locales[i] = locales[i].Stats()
+	}
+	sort.Slice(result.Locales, func(i, j int) bool {
+		return result.Locales[i].Ident < result.Locales[j].Ident
+	})
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
@@ -20,7 +20,7 @@ const (
 	clientSubject = "client"
 )
 
-// tokenMiddleware guards against request without a valid token.
+// tokenMiddleware guards against request without a valid, non revoked token.
 // Adds subject ID and subject type values to request context.
 func tokenMiddleware(tp auth.TokenProvider) iris.Handler {
 	return func(ctx iris.Context) {
@@ -36,6 +36,16 @@ func tokenMiddleware(tp auth.TokenProvider) iris.Handler {
 			return
 		}
 
+		revoked, err := auth.IsTokenRevoked(store, claims)
+		if err != nil {
+			handleError(ctx, err)
+			return
+		}
+		if revoked {
+			handleError(ctx, apiErrors.ErrUnauthorized)
+			return
+		}
+
 		subID := claims["sub"]
 		if subID == nil || subID == "" {
 			handleError(ctx, apiErrors.ErrInternal)
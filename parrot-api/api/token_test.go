@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/kataras/iris/v12"
+
+	"github.com/iris-contrib/parrot/parrot-api/auth"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+)
+
+func TestTokenMiddlewareRevokedTokens(t *testing.T) {
+	previous := store
+	defer func() { store = previous }()
+	db := memory.New()
+	store = db
+
+	tp := auth.TokenProvider{Name: "test", SigningKey: []byte("signing key")}
+	app := iris.New()
+	app.Get("/", tokenMiddleware(tp), func(ctx iris.Context) {
+		ctx.StatusCode(http.StatusOK)
+	})
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt := time.Now().Add(-time.Minute)
+	newToken := func(jti, sub string) string {
+		token, err := tp.CreateToken(jwt.MapClaims{
+			"jti":     jti,
+			"sub":     sub,
+			"subType": "user",
+			"iat":     issuedAt.Unix(),
+			"exp":     issuedAt.Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	status := func(token string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	revokedID := newToken("revoked", "jane")
+	kept := newToken("kept", "jane")
+	other := newToken("other", "john")
+	if code := status(revokedID); code != http.StatusOK {
+		t.Fatalf("expected token to be accepted but got status %d", code)
+	}
+
+	if err := db.RevokeAccessToken("revoked", issuedAt.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if code := status(revokedID); code != http.StatusUnauthorized {
+		t.Errorf("expected revoked token to be refused but got status %d", code)
+	}
+	if code := status(kept); code != http.StatusOK {
+		t.Errorf("expected other tokens of the subject to be accepted but got status %d", code)
+	}
+
+	if err := db.RevokeSubjectAccessTokens("jane", time.Now(), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if code := status(kept); code != http.StatusUnauthorized {
+		t.Errorf("expected tokens of the revoked subject to be refused but got status %d", code)
+	}
+	if code := status(newToken("", "jane")); code != http.StatusUnauthorized {
+		t.Errorf("expected tokens without an id to be refused with their subject but got status %d", code)
+	}
+	if code := status(other); code != http.StatusOK {
+		t.Errorf("expected tokens of other subjects to be accepted but got status %d", code)
+	}
+}
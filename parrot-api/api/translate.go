@@ -0,0 +1,248 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/kataras/iris/v12"
+
+	datastoreErrors "github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+	"github.com/iris-contrib/parrot/parrot-api/translate"
+)
+
+// translationConfigPayload holds the fields of a translation config. Fields left out are not changed.
+type translationConfigPayload struct {
+	Provider *string `json:"provider"`
+	URL      *string `json:"url"`
+	APIKey   *string `json:"api_key"`
+}
+
+// translationConfigView is a translation config as returned by the API,
+// which tells whether it has an API key without revealing it.
+type translationConfigView struct {
+	*model.TranslationConfig
+	HasAPIKey bool `json:"has_api_key"`
+}
+
+// apply copies the provided fields to the config. The API key is dropped when the
+// provider or the URL changes without a new one, so that it is never sent elsewhere.
+func (p *translationConfigPayload) apply(c *model.TranslationConfig) {
+	provider, url := c.Provider, c.URL
+	if p.Provider != nil {
+		c.Provider = strings.TrimSpace(*p.Provider)
+	}
+	if p.URL != nil {
+		c.URL = strings.TrimSpace(*p.URL)
+	}
+	if p.APIKey != nil {
+		c.APIKey = *p.APIKey
+	} else if c.Provider != provider || c.URL != url {
+		c.APIKey = ""
+	}
+}
+
+// getTranslationConfig is an API endpoint for retrieving the machine translation provider of a project.
+func getTranslationConfig(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	result, err := store.GetTranslationConfig(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, translationConfigView{result, result.APIKey != ""})
+}
+
+// updateTranslationConfig is an API endpoint for selecting the machine translation
+// provider of a project and setting its credentials.
+func updateTranslationConfig(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = translationConfigPayload{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	config, err := store.GetTranslationConfig(projectID)
+	if err == datastoreErrors.ErrNotFound {
+		config, err = &model.TranslationConfig{ProjectID: projectID}, nil
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	data.apply(config)
+	if errs := config.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	result, err := store.SetTranslationConfig(*config)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, translationConfigView{result, result.APIKey != ""})
+}
+
+// deleteTranslationConfig is an API endpoint for removing the machine translation provider of a project.
+func deleteTranslationConfig(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	err := store.DeleteTranslationConfig(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusNoContent, nil)
+}
+
+// prefillLocales is an API endpoint for filling the empty pairs of some locales with
+// machine translations of the values of a source locale, the project's one by
+// default. The filled values are marked as machine translated. Translations longer
+// than the max length of their key, and values entered while translating, are left
+// as they are.
+func prefillLocales(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = model.Prefill{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	project, err := store.GetProject(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	if data.Source == "" {
+		data.Source = project.SourceLocale
+	}
+	if errs := data.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	config, err := store.GetTranslationConfig(projectID)
+	if err == datastoreErrors.ErrNotFound {
+		err = model.ErrTranslationNotConfigured
+	}
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	provider, err := translate.New(*config)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	keys, err := store.GetProjectKeys(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	locs, err := store.GetProjectLocales(projectID, append([]string{data.Source}, data.Locales...)...)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	byIdent := make(map[string]*model.Locale, len(locs))
+	for i := range locs {
+		byIdent[locs[i].Ident] = &locs[i]
+	}
+	source, ok := byIdent[data.Source]
+	if !ok {
+		handleError(ctx, apiErrors.ErrNotFound)
+		return
+	}
+
+	translations := make(map[string]map[string]string)
+	var targets []string
+	for _, ident := range data.Locales {
+		target, ok := byIdent[ident]
+		if !ok {
+			handleError(ctx, apiErrors.ErrNotFound)
+			return
+		}
+		if _, ok := translations[ident]; ok {
+			continue
+		}
+
+		values, err := translate.Values(provider, source.Ident, ident, data.Missing(project, source, target))
+		if err != nil {
+			ctx.Application().Logger().Errorf("machine translation failed: %v", err)
+			handleError(ctx, apiErrors.ErrTranslationFailed)
+			return
+		}
+		for _, k := range keys {
+			if v, ok := values[k.Key]; ok && !k.Fits(v) {
+				delete(values, k.Key)
+			}
+		}
+		translations[ident] = values
+		if len(values) > 0 {
+			targets = append(targets, ident)
+		}
+	}
+
+	filled := make(map[string]map[string]string, len(data.Locales))
+	for ident := range translations {
+		filled[ident] = make(map[string]string)
+	}
+	if len(targets) == 0 {
+		render.JSON(ctx, iris.StatusOK, map[string]interface{}{"locales": filled})
+		return
+	}
+
+	j, err := newJournal(ctx)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+	_, err = store.UpdateLocales(projectID, targets, func(loc *model.Locale) error {
+		if loc.Statuses == nil {
+			loc.Statuses = make(map[string]string)
+		}
+
+		for k, v := range translations[loc.Ident] {
+			if v == "" || loc.Pairs[k] != "" {
+				continue
+			}
+			loc.Pairs[k] = v
+			loc.Statuses[k] = model.StatusMachineTranslated
+			filled[loc.Ident][k] = v
+		}
+		return nil
+	}, j)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, map[string]interface{}{"locales": filled})
+}
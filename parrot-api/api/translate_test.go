@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func TestTranslationConfigPayloadApply(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		payload  translationConfigPayload
+		expected string
+	}{
+		{"unchanged", translationConfigPayload{}, "key"},
+		{"same url", translationConfigPayload{URL: str("https://example.com")}, "key"},
+		{"new url", translationConfigPayload{URL: str("https://attacker.example")}, ""},
+		{"new provider", translationConfigPayload{Provider: str(model.TranslationPseudo)}, ""},
+		{"new url and key", translationConfigPayload{URL: str("https://other.example"), APIKey: str("other")}, "other"},
+	}
+	for _, tt := range tests {
+		c := model.TranslationConfig{Provider: model.TranslationHTTP, URL: "https://example.com", APIKey: "key"}
+		tt.payload.apply(&c)
+		if c.APIKey != tt.expected {
+			t.Errorf("%s: expected API key %q, got %q", tt.name, tt.expected, c.APIKey)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris/v12"
+
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/render"
+)
+
+var (
+	webhookSecretBytes = 32
+	// defaultDeliveriesLimit and maxDeliveriesLimit bound the size of the delivery log.
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+// webhookPayload holds the fields of a webhook. Fields left out are not changed.
+type webhookPayload struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// apply copies the provided fields to the webhook.
+func (p *webhookPayload) apply(w *model.Webhook) {
+	if p.URL != nil {
+		w.URL = strings.TrimSpace(*p.URL)
+	}
+	if p.Events != nil {
+		w.Events = p.Events
+	}
+	if p.Active != nil {
+		w.Active = *p.Active
+	}
+}
+
+// getProjectWebhooks is an API endpoint for retrieving the webhooks of a project.
+func getProjectWebhooks(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	result, err := store.GetProjectWebhooks(projectID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// showProjectWebhook is an API endpoint for retrieving a project webhook.
+func showProjectWebhook(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	webhookID := ctx.Params().Get("webhookID")
+	if webhookID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	result, err := store.GetProjectWebhook(projectID, webhookID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// createProjectWebhook is an API endpoint for subscribing a URL to the events of a project.
+// The webhook is active unless stated otherwise and its signing secret is generated.
+func createProjectWebhook(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = webhookPayload{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	w := model.Webhook{ProjectID: projectID, Active: true}
+	data.apply(&w)
+	if errs := w.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	secret, err := generateClientSecret(webhookSecretBytes)
+	if err != nil {
+		handleError(ctx, apiErrors.ErrInternal)
+		return
+	}
+	w.Secret = secret
+
+	result, err := store.CreateWebhook(w)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusCreated, result)
+}
+
+// updateProjectWebhook is an API endpoint for changing the url, events or state of a webhook.
+func updateProjectWebhook(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	webhookID := ctx.Params().Get("webhookID")
+	if webhookID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	var data = webhookPayload{}
+	if err := ctx.ReadJSON(&data); err != nil {
+		handleError(ctx, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	w, err := store.GetProjectWebhook(projectID, webhookID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	data.apply(w)
+	if errs := w.Validate(); errs != nil {
+		render.Error(ctx, iris.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	result, err := store.UpdateWebhook(*w)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// resetProjectWebhookSecret is an API endpoint for generating a new signing secret for a webhook.
+func resetProjectWebhookSecret(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	webhookID := ctx.Params().Get("webhookID")
+	if webhookID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	w, err := store.GetProjectWebhook(projectID, webhookID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	secret, err := generateClientSecret(webhookSecretBytes)
+	if err != nil {
+		handleError(ctx, apiErrors.ErrInternal)
+		return
+	}
+	w.Secret = secret
+
+	result, err := store.UpdateWebhook(*w)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
+
+// deleteProjectWebhook is an API endpoint for deleting a webhook along with its deliveries.
+func deleteProjectWebhook(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	webhookID := ctx.Params().Get("webhookID")
+	if webhookID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	err := store.DeleteWebhook(projectID, webhookID)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusNoContent, nil)
+}
+
+// getWebhookDeliveries is an API endpoint for retrieving the latest deliveries
+// of a webhook, newest first. The count can be set with the 'limit' query param.
+func getWebhookDeliveries(ctx iris.Context) {
+	projectID := ctx.Params().Get("projectID")
+	if projectID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+	webhookID := ctx.Params().Get("webhookID")
+	if webhookID == "" {
+		handleError(ctx, apiErrors.ErrBadRequest)
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if v := ctx.Request().URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			handleError(ctx, apiErrors.ErrBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	// Make sure the webhook belongs to the project
+	if _, err := store.GetProjectWebhook(projectID, webhookID); err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	result, err := store.GetWebhookDeliveries(webhookID, limit)
+	if err != nil {
+		handleError(ctx, err)
+		return
+	}
+
+	render.JSON(ctx, iris.StatusOK, result)
+}
@@ -1,6 +1,11 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -8,8 +13,8 @@ import (
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/schema"
-	"github.com/iris-contrib/parrot/parrot-api/datastore"
 	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
 	"github.com/iris-contrib/parrot/parrot-api/render"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,6 +25,7 @@ type authRequestPayload struct {
 	GrantType    string `json:"grant_type" schema:"grant_type"`
 	Username     string `json:"username" schema:"username"`
 	Password     string `json:"password" schema:"password"`
+	RefreshToken string `json:"refresh_token" schema:"refresh_token"`
 }
 
 type introspectRequest struct {
@@ -29,10 +35,16 @@ type introspectRequest struct {
 	ClientSecret  string `json:"client_secret" schema:"client_secret"`
 }
 
+type revokeRequest struct {
+	Token         string `json:"token" schema:"token"`
+	TokenTypeHint string `json:"token_type_hint" schema:"token_type_hint"`
+}
+
 type tokenResponse struct {
-	AccessToken string `json:"access_token" `
-	TokenType   string `json:"token_type" `
-	ExpiresIn   string `json:"expires_in" `
+	AccessToken  string `json:"access_token" `
+	TokenType    string `json:"token_type" `
+	ExpiresIn    string `json:"expires_in" `
+	RefreshToken string `json:"refresh_token,omitempty" `
 }
 
 var (
@@ -40,6 +52,10 @@ var (
 		"Cache-Control": "no-store",
 		"Pragma":        "no-cache",
 	}
+
+	accessTokenLifetime  = time.Hour * 24
+	refreshTokenLifetime = time.Hour * 24 * 30
+	refreshTokenBytes    = 32
 )
 
 type tokenClaims struct {
@@ -72,6 +88,8 @@ func IssueToken(tp TokenProvider, store AuthStore) iris.Handler {
 			handlePasswordGrant(ctx, *payload, tp, store)
 		case "client_credentials":
 			handleClientCredentialsGrant(ctx, *payload, tp, store)
+		case "refresh_token":
+			handleRefreshTokenGrant(ctx, *payload, tp, store)
 		default:
 			ctx.StatusCode(apiErrors.ErrBadRequest.Status)
 			ctx.WriteString(apiErrors.ErrBadRequest.Message)
@@ -98,28 +116,17 @@ func handlePasswordGrant(ctx iris.Context, payload authRequestPayload, tp TokenP
 		return
 	}
 
-	// Create the Claims
-	now := time.Now()
-	claims := tokenClaims{
-		SubjectType: "user",
-		StandardClaims: jwt.StandardClaims{
-			Issuer:    tp.Name,
-			IssuedAt:  now.Unix(),
-			ExpiresAt: now.Add(time.Hour * 24).Unix(),
-			Subject:   fmt.Sprintf("%s", claimedUser.ID),
-		},
-	}
-
-	tokenString, err := tp.CreateToken(claims)
+	data, err := createAccessToken(tp, "user", fmt.Sprintf("%s", claimedUser.ID))
 	if err != nil {
 		render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
 		return
 	}
 
-	data := tokenResponse{
-		AccessToken: tokenString,
-		TokenType:   "Bearer",
-		ExpiresIn:   fmt.Sprintf("%d", claims.ExpiresAt-time.Now().Unix()),
+	// Start a new family of refresh tokens for this login
+	data.RefreshToken, err = createRefreshToken(store, model.RefreshToken{SubjectID: claimedUser.ID, SubjectType: "user"})
+	if err != nil {
+		render.Error(ctx, apiErrors.ErrInternal.Status, apiErrors.ErrInternal)
+		return
 	}
 
 	render.JSONWithHeaders(ctx, iris.StatusOK, tokenResponseHeaders, data)
@@ -144,35 +151,147 @@ func handleClientCredentialsGrant(ctx iris.Context, payload authRequestPayload,
 		return
 	}
 
+	data, err := createAccessToken(tp, "client", fmt.Sprintf("%s", claimedClient.ClientID))
+	if err != nil {
+		render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	render.JSONWithHeaders(ctx, iris.StatusOK, tokenResponseHeaders, data)
+}
+
+// handleRefreshTokenGrant handles the 'refresh_token' grant type.
+// The refresh token is replaced by a new one on every use. Presenting a token that
+// was already replaced revokes every token issued since the original login.
+func handleRefreshTokenGrant(ctx iris.Context, payload authRequestPayload, tp TokenProvider, store AuthStore) {
+	if payload.RefreshToken == "" {
+		render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	tokenHash := hashRefreshToken(payload.RefreshToken)
+	claimed, err := store.GetRefreshToken(tokenHash)
+	if err != nil {
+		render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+		return
+	}
+
+	if claimed.RevokedAt != nil {
+		// The token was either revoked or already used, someone might hold a stolen copy
+		if err := revokeRefreshTokenFamily(store, claimed); err != nil {
+			ctx.Application().Logger().Errorf("failed to revoke refresh tokens: %v", err)
+		}
+		render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+		return
+	}
+	if !claimed.IsActive(time.Now()) {
+		render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+		return
+	}
+
+	// Make sure the user still exists
+	if _, err := store.GetUserByID(claimed.SubjectID); err != nil {
+		render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+		return
+	}
+
+	data, err := createAccessToken(tp, claimed.SubjectType, claimed.SubjectID)
+	if err != nil {
+		render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+		return
+	}
+
+	refreshToken, err := generateRandomToken(refreshTokenBytes)
+	if err != nil {
+		render.Error(ctx, apiErrors.ErrInternal.Status, apiErrors.ErrInternal)
+		return
+	}
+	_, err = store.RotateRefreshToken(tokenHash, model.RefreshToken{
+		TokenHash:   hashRefreshToken(refreshToken),
+		FamilyID:    claimed.FamilyID,
+		SubjectID:   claimed.SubjectID,
+		SubjectType: claimed.SubjectType,
+		ExpiresAt:   time.Now().Add(refreshTokenLifetime),
+	})
+	if err != nil {
+		// The token was used concurrently
+		render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+		return
+	}
+	data.RefreshToken = refreshToken
+
+	render.JSONWithHeaders(ctx, iris.StatusOK, tokenResponseHeaders, data)
+}
+
+// createAccessToken creates and signs a new access token for the subject.
+func createAccessToken(tp TokenProvider, subjectType, subjectID string) (tokenResponse, error) {
+	tokenID, err := generateRandomToken(16)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+
 	// Create the Claims
 	now := time.Now()
 	claims := tokenClaims{
-		SubjectType: "client",
+		SubjectType: subjectType,
 		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
 			Issuer:    tp.Name,
 			IssuedAt:  now.Unix(),
-			ExpiresAt: now.Add(time.Hour * 24).Unix(),
-			Subject:   fmt.Sprintf("%s", claimedClient.ClientID),
+			ExpiresAt: now.Add(accessTokenLifetime).Unix(),
+			Subject:   subjectID,
 		},
 	}
 
 	tokenString, err := tp.CreateToken(claims)
 	if err != nil {
-		render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
-		return
+		return tokenResponse{}, err
 	}
 
-	data := tokenResponse{
+	return tokenResponse{
 		AccessToken: tokenString,
 		TokenType:   "Bearer",
 		ExpiresIn:   fmt.Sprintf("%d", claims.ExpiresAt-time.Now().Unix()),
+	}, nil
+}
+
+// createRefreshToken stores a new refresh token and returns its value.
+// Only a hash of the value is stored.
+func createRefreshToken(store AuthStore, token model.RefreshToken) (string, error) {
+	value, err := generateRandomToken(refreshTokenBytes)
+	if err != nil {
+		return "", err
 	}
 
-	render.JSONWithHeaders(ctx, iris.StatusOK, tokenResponseHeaders, data)
+	token.TokenHash = hashRefreshToken(value)
+	token.ExpiresAt = time.Now().Add(refreshTokenLifetime)
+	if _, err := store.CreateRefreshToken(token); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// hashRefreshToken returns the hash under which a refresh token is stored.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-// IntrospectToken verifies the validity of a token and writes its claims.
-func IntrospectToken(tp TokenProvider, store datastore.Store) iris.Handler {
+// generateRandomToken generates a cryptographically secure pseudorandom string.
+func generateRandomToken(bytes int) (string, error) {
+	b := make([]byte, bytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// IntrospectToken is a HTTP endpoint that tells whether a token is active and writes
+// its claims, as specified by RFC 7662. Callers authenticate with the credentials of
+// an API client, with HTTP Basic authentication or the client_id and client_secret
+// params. Tokens that are invalid, expired or revoked are only reported as inactive.
+func IntrospectToken(tp TokenProvider, store AuthStore) iris.Handler {
 	return func(ctx iris.Context) {
 		r := ctx.Request()
 		err := r.ParseForm()
@@ -189,28 +308,180 @@ func IntrospectToken(tp TokenProvider, store datastore.Store) iris.Handler {
 			return
 		}
 
+		if id, secret, ok := r.BasicAuth(); ok {
+			payload.ClientId, payload.ClientSecret = id, secret
+		}
+		if !authenticateClient(store, payload.ClientId, payload.ClientSecret) {
+			render.Error(ctx, apiErrors.ErrUnauthorized.Status, apiErrors.ErrUnauthorized)
+			return
+		}
+
 		if payload.Token == "" {
 			render.Error(ctx, apiErrors.ErrBadRequest.Status, apiErrors.ErrBadRequest)
 			return
 		}
 
-		claims, err := tp.ParseAndExtractClaims(payload.Token)
+		inactive := map[string]interface{}{"active": false}
+
+		claims, err := tp.ParseAndVerifyToken(payload.Token)
 		if err != nil {
-			render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+			render.JSON(ctx, iris.StatusOK, inactive)
+			return
+		}
+		revoked, err := IsTokenRevoked(store, claims)
+		if err != nil {
+			render.Error(ctx, apiErrors.ErrInternal.Status, apiErrors.ErrInternal)
+			return
+		}
+		if revoked {
+			render.JSON(ctx, iris.StatusOK, inactive)
 			return
 		}
 
 		data := make(map[string]interface{})
-
 		for k, v := range claims {
 			data[k] = v
 		}
-
 		data["active"] = true
-		if err := claims.Valid(); err != nil {
-			data["active"] = false
-		}
 
 		render.JSON(ctx, iris.StatusOK, data)
 	}
 }
+
+// authenticateClient returns true if the secret is the one of the API client.
+func authenticateClient(store AuthStore, clientID, secret string) bool {
+	if clientID == "" || secret == "" {
+		return false
+	}
+	client, err := store.FindOneClient(clientID)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) == 1
+}
+
+// IsTokenRevoked returns true if the access token with the claims was revoked, either
+// by itself or along with every token of its subject.
+func IsTokenRevoked(store model.TokenStorer, claims jwt.MapClaims) (bool, error) {
+	// Tokens issued before revocation support have no ID, they can only be revoked by subject
+	jti, _ := claims["jti"].(string)
+	sub, _ := claims["sub"].(string)
+	iat, _ := claims["iat"].(float64)
+	return store.IsAccessTokenRevoked(jti, sub, time.Unix(int64(iat), 0))
+}
+
+// RevokeSubject revokes every refresh token of the subject, and the access tokens
+// issued to it so far.
+func RevokeSubject(store model.TokenStorer, subjectID string) error {
+	if err := store.RevokeSubjectRefreshTokens(subjectID); err != nil {
+		return err
+	}
+	return revokeSubjectAccessTokens(store, subjectID)
+}
+
+// revokeSubjectAccessTokens revokes the access tokens issued to the subject so far.
+// Tokens only carry their issue time in seconds, so the tokens issued during the
+// rest of the current second are revoked too.
+func revokeSubjectAccessTokens(store model.TokenStorer, subjectID string) error {
+	issuedBefore := time.Now().Truncate(time.Second).Add(time.Second)
+	return store.RevokeSubjectAccessTokens(subjectID, issuedBefore, issuedBefore.Add(accessTokenLifetime))
+}
+
+// RevokeToken is a HTTP endpoint that revokes access and refresh tokens, as specified by RFC 7009.
+// Revoking a refresh token revokes every refresh token issued since the same login, and
+// the access tokens issued to its subject so far.
+// Invalid tokens are ignored, so the response is the same whether the token was known or not.
+func RevokeToken(tp TokenProvider, store AuthStore) iris.Handler {
+	return func(ctx iris.Context) {
+		r := ctx.Request()
+		err := r.ParseForm()
+		if err != nil {
+			render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+			return
+		}
+		payload := new(revokeRequest)
+		decoder := schema.NewDecoder()
+		decoder.IgnoreUnknownKeys(true)
+
+		err = decoder.Decode(payload, r.Form)
+		if err != nil {
+			render.Error(ctx, apiErrors.ErrUnprocessable.Status, apiErrors.ErrUnprocessable)
+			return
+		}
+
+		if payload.Token == "" {
+			render.Error(ctx, apiErrors.ErrBadRequest.Status, apiErrors.ErrBadRequest)
+			return
+		}
+
+		// The hint only decides which kind of token is looked up first
+		revokers := []func(string) (bool, error){
+			func(token string) (bool, error) { return revokeRefreshToken(store, token) },
+			func(token string) (bool, error) { return revokeAccessToken(tp, store, token) },
+		}
+		if payload.TokenTypeHint == "access_token" {
+			revokers[0], revokers[1] = revokers[1], revokers[0]
+		}
+
+		for _, revoke := range revokers {
+			found, err := revoke(payload.Token)
+			if err != nil {
+				render.Error(ctx, apiErrors.ErrInternal.Status, apiErrors.ErrInternal)
+				return
+			}
+			if found {
+				break
+			}
+		}
+
+		ctx.StatusCode(iris.StatusOK)
+	}
+}
+
+// revokeRefreshToken revokes the family of the refresh token. It returns false if the token is unknown.
+func revokeRefreshToken(store AuthStore, token string) (bool, error) {
+	claimed, err := store.GetRefreshToken(hashRefreshToken(token))
+	if err != nil {
+		// Unknown token
+		return false, nil
+	}
+	return true, revokeRefreshTokenFamily(store, claimed)
+}
+
+// revokeRefreshTokenFamily revokes the family of the refresh token and the access tokens
+// of its subject. Access tokens don't tell which family they were issued with, so the
+// subject's other sessions have to refresh theirs.
+func revokeRefreshTokenFamily(store AuthStore, token *model.RefreshToken) error {
+	if err := store.RevokeRefreshTokenFamily(token.FamilyID); err != nil {
+		return err
+	}
+	return revokeSubjectAccessTokens(store, token.SubjectID)
+}
+
+// revokeAccessToken adds the access token to the revoked tokens until it expires.
+// It returns false if the token was not issued by the provider.
+func revokeAccessToken(tp TokenProvider, store AuthStore, token string) (bool, error) {
+	claims, err := tp.ParseAndExtractClaims(token)
+	if err != nil {
+		return false, nil
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		// Tokens issued before revocation support can't be revoked
+		return true, nil
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return true, nil
+	}
+
+	return true, store.RevokeAccessToken(jti, time.Unix(int64(exp), 0))
+}
+
+// JWKS is a HTTP endpoint that publishes the public keys that verify tokens.
+func JWKS(tp TokenProvider) iris.Handler {
+	return func(ctx iris.Context) {
+		render.JSON(ctx, iris.StatusOK, tp.JWKS())
+	}
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris/v12"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// authTest serves the auth routes backed by an in-memory store holding a user and an API client.
+type authTest struct {
+	t     *testing.T
+	app   *iris.Application
+	store *memory.MemoryDB
+	tp    TokenProvider
+
+	user   *model.User
+	client *model.ProjectClient
+}
+
+func newAuthTest(t *testing.T) *authTest {
+	store := memory.New()
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := store.CreateUser(model.User{Name: "Jane", Email: "jane@example.com", Password: string(hash)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := store.CreateProject(model.Project{Name: "project", Keys: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := store.CreateProjectClient(model.ProjectClient{ProjectID: project.ID, Name: "ci", Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tp := TokenProvider{Name: "test", SigningKey: []byte("signing key")}
+	app := iris.New()
+	app.Configure(NewRouter(store, tp))
+	if err := app.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &authTest{t: t, app: app, store: store, tp: tp, user: user, client: client}
+}
+
+// post sends the form to the path and decodes the payload of the response, if any, into result.
+func (a *authTest) post(path string, form url.Values, result interface{}) int {
+	a.t.Helper()
+	return a.do(newFormRequest(path, form), result)
+}
+
+// do sends the request and decodes the payload of the response, if any, into result.
+func (a *authTest) do(req *http.Request, result interface{}) int {
+	a.t.Helper()
+	rec := httptest.NewRecorder()
+	a.app.ServeHTTP(rec, req)
+
+	if result != nil && rec.Code == http.StatusOK {
+		var body struct {
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			a.t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+		}
+		if err := json.Unmarshal(body.Payload, result); err != nil {
+			a.t.Fatalf("failed to decode payload %q: %v", body.Payload, err)
+		}
+	}
+	return rec.Code
+}
+
+func newFormRequest(path string, form url.Values) *http.Request {
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// login issues tokens with the password grant.
+func (a *authTest) login() tokenResponse {
+	a.t.Helper()
+	var tokens tokenResponse
+	status := a.post("/api/v1/auth/token", url.Values{
+		"grant_type": {"password"}, "username": {"jane@example.com"}, "password": {"password"}}, &tokens)
+	if status != http.StatusOK || tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		a.t.Fatalf("expected tokens but got status %d and %+v", status, tokens)
+	}
+	return tokens
+}
+
+// refresh uses the refresh token grant and returns the status and the new tokens.
+func (a *authTest) refresh(refreshToken string) (int, tokenResponse) {
+	a.t.Helper()
+	var tokens tokenResponse
+	status := a.post("/api/v1/auth/token", url.Values{
+		"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}, &tokens)
+	return status, tokens
+}
+
+// isActive introspects the token with the credentials of the API client.
+func (a *authTest) isActive(token string) bool {
+	a.t.Helper()
+	var result map[string]interface{}
+	status := a.post("/api/v1/auth/introspect", url.Values{
+		"token": {token}, "client_id": {a.client.ClientID}, "client_secret": {"secret"}}, &result)
+	if status != http.StatusOK {
+		a.t.Fatalf("expected introspection to succeed but got status %d", status)
+	}
+	active, _ := result["active"].(bool)
+	return active
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	a := newAuthTest(t)
+	first := a.login()
+
+	status, second := a.refresh(first.RefreshToken)
+	if status != http.StatusOK {
+		t.Fatalf("expected refresh to succeed but got status %d", status)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("expected a new refresh token but got %q", second.RefreshToken)
+	}
+
+	status, third := a.refresh(second.RefreshToken)
+	if status != http.StatusOK || third.AccessToken == "" {
+		t.Fatalf("expected the rotated token to be usable but got status %d", status)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	a := newAuthTest(t)
+	first := a.login()
+	other := a.login()
+
+	_, second := a.refresh(first.RefreshToken)
+	if status, _ := a.refresh(first.RefreshToken); status != http.StatusUnauthorized {
+		t.Fatalf("expected reused token to be refused but got status %d", status)
+	}
+
+	if status, _ := a.refresh(second.RefreshToken); status != http.StatusUnauthorized {
+		t.Fatalf("expected the family to be revoked but got status %d", status)
+	}
+	if a.isActive(second.AccessToken) {
+		t.Fatal("expected the access tokens of the subject to be revoked")
+	}
+	if status, _ := a.refresh(other.RefreshToken); status != http.StatusOK {
+		t.Fatalf("expected other families to be kept but got status %d", status)
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	a := newAuthTest(t)
+	tokens := a.login()
+
+	if status := a.post("/api/v1/auth/revoke", url.Values{"token": {"unknown"}}, nil); status != http.StatusOK {
+		t.Fatalf("expected unknown tokens to be ignored but got status %d", status)
+	}
+
+	if !a.isActive(tokens.AccessToken) {
+		t.Fatal("expected access token to be active")
+	}
+	if status := a.post("/api/v1/auth/revoke", url.Values{"token": {tokens.RefreshToken}}, nil); status != http.StatusOK {
+		t.Fatalf("expected revocation to succeed but got status %d", status)
+	}
+	if status, _ := a.refresh(tokens.RefreshToken); status != http.StatusUnauthorized {
+		t.Fatalf("expected revoked refresh token to be refused but got status %d", status)
+	}
+	if a.isActive(tokens.AccessToken) {
+		t.Fatal("expected the access tokens of the family to be revoked with it")
+	}
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	a := newAuthTest(t)
+	tokens := a.login()
+
+	status := a.post("/api/v1/auth/revoke", url.Values{"token": {tokens.AccessToken}, "token_type_hint": {"access_token"}}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("expected revocation to succeed but got status %d", status)
+	}
+	if a.isActive(tokens.AccessToken) {
+		t.Fatal("expected access token to be revoked")
+	}
+	if status, _ := a.refresh(tokens.RefreshToken); status != http.StatusOK {
+		t.Fatalf("expected refresh token to be kept but got status %d", status)
+	}
+}
+
+func TestIntrospectToken(t *testing.T) {
+	a := newAuthTest(t)
+	tokens := a.login()
+
+	cases := map[string]url.Values{
+		"no credentials": {"token": {tokens.AccessToken}},
+		"wrong secret":   {"token": {tokens.AccessToken}, "client_id": {a.client.ClientID}, "client_secret": {"wrong"}},
+		"unknown client": {"token": {tokens.AccessToken}, "client_id": {"unknown"}, "client_secret": {"secret"}},
+	}
+	for name, form := range cases {
+		if status := a.post("/api/v1/auth/introspect", form, nil); status != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d but got %d", name, http.StatusUnauthorized, status)
+		}
+	}
+
+	req := newFormRequest("/api/v1/auth/introspect", url.Values{"token": {tokens.AccessToken}})
+	req.SetBasicAuth(a.client.ClientID, "secret")
+	var result map[string]interface{}
+	if status := a.do(req, &result); status != http.StatusOK || result["active"] != true || result["sub"] != a.user.ID {
+		t.Fatalf("expected active token of the user but got %v", result)
+	}
+
+	expired, err := createAccessTokenWithLifetime(a.tp, a.user.ID, -accessTokenLifetime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{expired, "malformed"} {
+		var result map[string]interface{}
+		status := a.post("/api/v1/auth/introspect", url.Values{
+			"token": {token}, "client_id": {a.client.ClientID}, "client_secret": {"secret"}}, &result)
+		if status != http.StatusOK || len(result) != 1 || result["active"] != false {
+			t.Errorf("expected only an inactive status but got status %d and %v", status, result)
+		}
+	}
+}
+
+func TestRevokeSubject(t *testing.T) {
+	a := newAuthTest(t)
+	first := a.login()
+	second := a.login()
+
+	if err := RevokeSubject(a.store, a.user.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, tokens := range []tokenResponse{first, second} {
+		if a.isActive(tokens.AccessToken) {
+			t.Error("expected access token to be revoked")
+		}
+		if status, _ := a.refresh(tokens.RefreshToken); status != http.StatusUnauthorized {
+			t.Errorf("expected refresh token to be revoked but got status %d", status)
+		}
+	}
+}
+
+// createAccessTokenWithLifetime creates an access token for the user that expires after the lifetime.
+func createAccessTokenWithLifetime(tp TokenProvider, userID string, lifetime time.Duration) (string, error) {
+	previous := accessTokenLifetime
+	accessTokenLifetime = lifetime
+	defer func() { accessTokenLifetime = previous }()
+
+	data, err := createAccessToken(tp, "user", userID)
+	return data.AccessToken, err
+}
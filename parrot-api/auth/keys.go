@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// SigningKey holds a key used to sign and verify tokens, identified by its ID ('kid').
+// Keys without a private part can only verify tokens, which keeps tokens signed
+// by a retired key valid until they expire.
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private interface{}
+	Public  interface{}
+}
+
+// CanSign returns true if the key holds a private part.
+func (k *SigningKey) CanSign() bool {
+	return k.Private != nil
+}
+
+// NewHMACKey creates a HS256 signing key from a shared secret.
+func NewHMACKey(id string, secret []byte) SigningKey {
+	return SigningKey{ID: id, Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+}
+
+// LoadPEMKey loads a RSA or ECDSA key from a PEM file. Private keys are used with RS256,
+// or ES256, ES384 and ES512 depending on the curve. Public keys only verify tokens.
+func LoadPEMKey(id, path string) (SigningKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return ParsePEMKey(id, data)
+}
+
+// LoadPEMKeys loads the keys listed in spec, a comma separated list of 'kid=path' entries.
+func LoadPEMKeys(spec string) ([]SigningKey, error) {
+	var keys []SigningKey
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid key entry %q, expected 'kid=path'", entry)
+		}
+		key, err := LoadPEMKey(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParsePEMKey parses a RSA or ECDSA key from PEM encoded data.
+func ParsePEMKey(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("key %s: no PEM data found", id)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("key %s: unsupported PEM block type %q", id, block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("key %s: %v", id, err)
+	}
+
+	k := SigningKey{ID: id}
+	switch v := key.(type) {
+	case *rsa.PrivateKey:
+		k.Method, k.Private, k.Public = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.Method, k.Public = jwt.SigningMethodRS256, v
+	case *ecdsa.PrivateKey:
+		k.Private, k.Public = v, &v.PublicKey
+		k.Method, err = ecdsaMethod(v.Curve)
+	case *ecdsa.PublicKey:
+		k.Public = v
+		k.Method, err = ecdsaMethod(v.Curve)
+	default:
+		return SigningKey{}, fmt.Errorf("key %s: unsupported key type %T", id, key)
+	}
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("key %s: %v", id, err)
+	}
+
+	return k, nil
+}
+
+// ecdsaMethod returns the signing method matching the curve.
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	}
+	return nil, fmt.Errorf("unsupported curve %s", curve.Params().Name)
+}
+
+// JWK is the JSON Web Key representation of a public key, as specified by RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWK returns the public part of the key as a JWK, or false for shared HMAC secrets.
+func (k *SigningKey) JWK() (JWK, bool) {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Method.Alg()}
+
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(padBytes(pub.X.Bytes(), size))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(padBytes(pub.Y.Bytes(), size))
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// padBytes left pads b with zeros up to size bytes, as required for EC coordinates.
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -9,5 +9,8 @@ import (
 func NewRouter(ds AuthStore, tp TokenProvider) iris.Configurator {
 	return func(app *iris.Application) {
 		app.Post("/api/v1/auth/token", IssueToken(tp, ds))
+		app.Post("/api/v1/auth/introspect", IntrospectToken(tp, ds))
+		app.Post("/api/v1/auth/revoke", RevokeToken(tp, ds))
+		app.Get("/.well-known/jwks.json", JWKS(tp))
 	}
 }
@@ -7,10 +7,15 @@ import (
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
-// TokenProvider holds the Auth Provider's name and Signing Key.
+// TokenProvider holds the Auth Provider's name and signing keys.
+// Tokens are signed with the key identified by ActiveKeyID, or the first key
+// that can sign, and carry its ID in the 'kid' header. The shared SigningKey
+// is used for HS256 tokens without a 'kid' and when no other key can sign.
 type TokenProvider struct {
-	Name       string
-	SigningKey []byte
+	Name        string
+	SigningKey  []byte
+	Keys        []SigningKey
+	ActiveKeyID string
 }
 
 // AuthStore is the interface that an Auth Provider implementation requires to retrieve
@@ -18,14 +23,73 @@ type TokenProvider struct {
 type AuthStore interface {
 	model.UserStorer
 	model.ProjectClientStorer
+	model.TokenStorer
 	Ping() error
 	Close() error
 }
 
 // CreateToken creates a new token with the provided claims and signs it.
 func (p *TokenProvider) CreateToken(claims jwt.Claims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(p.SigningKey)
+	key := p.activeKey()
+	if key == nil {
+		if len(p.SigningKey) == 0 {
+			return "", fmt.Errorf("no signing key")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(p.SigningKey)
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// activeKey returns the key used to sign new tokens, or nil if there is none.
+func (p *TokenProvider) activeKey() *SigningKey {
+	for i := range p.Keys {
+		k := &p.Keys[i]
+		if !k.CanSign() {
+			continue
+		}
+		if p.ActiveKeyID == "" || k.ID == p.ActiveKeyID {
+			return k
+		}
+	}
+	return nil
+}
+
+// verificationKey returns the key that verifies the token, based on its 'kid' header.
+func (p *TokenProvider) verificationKey(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok || len(p.SigningKey) == 0 {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return p.SigningKey, nil
+	}
+
+	for _, k := range p.Keys {
+		if k.ID != kid {
+			continue
+		}
+		if t.Method.Alg() != k.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return k.Public, nil
+	}
+	return nil, fmt.Errorf("unknown key %s", kid)
+}
+
+// JWKS returns the public keys that verify tokens, as a JSON Web Key Set.
+// Shared HMAC secrets are never published.
+func (p *TokenProvider) JWKS() map[string][]JWK {
+	keys := make([]JWK, 0, len(p.Keys))
+	for _, k := range p.Keys {
+		if jwk, ok := k.JWK(); ok {
+			keys = append(keys, jwk)
+		}
+	}
+	return map[string][]JWK{"keys": keys}
 }
 
 // ParseAndVerifyToken parses, verifies and validates the claims of the token.
@@ -45,7 +109,7 @@ func (p *TokenProvider) ParseAndVerifyToken(tokenString string) (jwt.MapClaims,
 // ParseAndExtractClaims parses the claims of the token and its signature without validating the claims.
 // It returns the claims or an error.
 func (p *TokenProvider) ParseAndExtractClaims(tokenString string) (jwt.MapClaims, error) {
-	token, err := parseToken(tokenString, p.SigningKey)
+	token, err := parseToken(tokenString, p.verificationKey)
 	if err != nil {
 		return nil, err
 	}
@@ -58,15 +122,10 @@ func (p *TokenProvider) ParseAndExtractClaims(tokenString string) (jwt.MapClaims
 	return claims, nil
 }
 
-// parseToken parses and verifies the signature of the token.
+// parseToken parses and verifies the signature of the token with the key returned by keyFunc.
 // It returns the parsed token or an error.
-func parseToken(tokenString string, signingKey []byte) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return signingKey, nil
-	})
+func parseToken(tokenString string, keyFunc jwt.Keyfunc) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, keyFunc)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestTokenProviderKeyRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldKey, err := ParsePEMKey("old", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := ParsePEMKey("new", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if oldKey.Method.Alg() != "RS256" || newKey.Method.Alg() != "ES256" {
+		t.Fatalf("unexpected methods %s and %s", oldKey.Method.Alg(), newKey.Method.Alg())
+	}
+
+	claims := jwt.StandardClaims{Subject: "user", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	tp := TokenProvider{Name: "test", Keys: []SigningKey{oldKey}}
+	oldToken, err := tp.CreateToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: sign with the new key, keep the old public key for verification only
+	oldPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, err := ParsePEMKey("old", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: oldPublic}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp = TokenProvider{Name: "test", Keys: []SigningKey{retired, newKey}}
+
+	newToken, err := tp.CreateToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		parsed, err := tp.ParseAndVerifyToken(token)
+		if err != nil {
+			t.Fatalf("%s token: expected to be valid but got %v", name, err)
+		}
+		if parsed["sub"] != "user" {
+			t.Fatalf("%s token: unexpected subject %v", name, parsed["sub"])
+		}
+	}
+
+	// Tokens signed with the shared secret are not accepted without one
+	hmacToken, err := (&TokenProvider{SigningKey: []byte("secret")}).CreateToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tp.ParseAndVerifyToken(hmacToken); err == nil {
+		t.Fatal("expected HMAC token to be rejected")
+	}
+
+	jwks := tp.JWKS()["keys"]
+	if len(jwks) != 2 || jwks[0].KeyType != "RSA" || jwks[1].KeyType != "EC" || jwks[1].Curve != "P-256" {
+		t.Fatalf("unexpected JWKS %+v", jwks)
+	}
+}
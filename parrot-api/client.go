@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// clientSecretBytes is the size of the generated client secrets, the same as the API's.
+const clientSecretBytes = 32
+
+func runClient(args []string) error {
+	if len(args) > 0 && args[0] == "create" {
+		return createClient(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Usage: parrot-api client create [flags]\n")
+	return errUsage
+}
+
+// createClient creates a project client and prints its credentials.
+func createClient(args []string) error {
+	fs := newFlagSet("client create", "client create -project <id> -name <name> [-add-keys] [flags]")
+	db := dbFlags(fs)
+	projectID := fs.String("project", "", "id of the client's project")
+	name := fs.String("name", "", "name of the client")
+	canAddKeys := fs.Bool("add-keys", false, "allow the client to add keys, as 'parrot push' does")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *projectID == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	pc := model.ProjectClient{Name: *name, ProjectID: *projectID, CanAddKeys: *canAddKeys}
+	if err := pc.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	ds, err := db.open(false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	if _, err := ds.GetProject(pc.ProjectID); err != nil {
+		return fmt.Errorf("could not find project %s: %v", pc.ProjectID, err)
+	}
+
+	b := make([]byte, clientSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	pc.Secret = base64.URLEncoding.EncodeToString(b)
+
+	result, err := ds.CreateProjectClient(pc)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(result)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// apiResponse is the envelope of the API's JSON responses.
+type apiResponse struct {
+	Meta struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"meta"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Client calls the Parrot API on behalf of a project client.
+type Client struct {
+	url   string
+	http  *http.Client
+	token string
+}
+
+// NewClient authenticates with the client credentials grant.
+func NewClient(rootURL, clientID, clientSecret string) (*Client, error) {
+	c := &Client{
+		url:  strings.TrimRight(rootURL, "/") + "/api/v1",
+		http: &http.Client{Timeout: time.Minute},
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+	}
+	resp, err := c.http.PostForm(c.url+"/auth/token", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := decodeResponse(resp, &token); err != nil {
+		return nil, fmt.Errorf("authentication failed: %v", err)
+	}
+	c.token = token.AccessToken
+
+	return c, nil
+}
+
+// ExportLocale returns the locale file in the export format.
+func (c *Client) ExportLocale(projectID, ident, i18nType string) ([]byte, error) {
+	resp, err := c.do("GET", fmt.Sprintf("/projects/%s/locales/%s/export/%s",
+		url.PathEscape(projectID), url.PathEscape(ident), url.PathEscape(i18nType)), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, decodeResponse(resp, nil)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// GetProject returns the project, with its keys.
+func (c *Client) GetProject(projectID string) (*model.Project, error) {
+	resp, err := c.do("GET", "/projects/"+url.PathEscape(projectID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var project model.Project
+	if err := decodeResponse(resp, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// AddKey adds a key to the project, holding plural forms if plural is set.
+func (c *Client) AddKey(projectID, key string, plural bool) error {
+	body, err := json.Marshal(map[string]interface{}{"key": key, "plural": plural})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do("POST", "/projects/"+url.PathEscape(projectID)+"/keys", body)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, nil)
+}
+
+func (c *Client) do(method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.http.Do(req)
+}
+
+// decodeResponse closes the response body after decoding its payload into v,
+// if not nil. API errors are returned as errors.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("%s", resp.Status)
+		}
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		if body.Meta.Error != nil {
+			return fmt.Errorf("%s: %s", resp.Status, body.Meta.Error.Message)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(body.Payload, v)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/iris-contrib/parrot/parrot-api/export"
+)
+
+// Config is the content of a .parrot.yml file.
+type Config struct {
+	// URL is the root URL of the Parrot server, e.g. 'https://parrot.example.com'.
+	URL      string          `yaml:"url"`
+	Projects []ProjectConfig `yaml:"projects"`
+}
+
+// ProjectConfig maps the locales of a project to local files.
+type ProjectConfig struct {
+	ID string `yaml:"id"`
+	// ClientID and ClientSecret are the credentials of one of the project's API clients.
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// Type is the export format of the files.
+	Type string `yaml:"type"`
+	// Source is the locale whose file holds the keys uploaded by push.
+	Source string `yaml:"source"`
+	// Locales maps locale idents to file paths, relative to the config file.
+	Locales map[string]string `yaml:"locales"`
+}
+
+// LoadConfig reads and validates the config file. Environment variables such as
+// '${PARROT_CLIENT_SECRET}' in the url and credentials are expanded, so that
+// secrets don't need to be committed.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	c.URL = os.ExpandEnv(c.URL)
+	dir := filepath.Dir(path)
+	for i := range c.Projects {
+		p := &c.Projects[i]
+		p.ClientID = os.ExpandEnv(p.ClientID)
+		p.ClientSecret = os.ExpandEnv(p.ClientSecret)
+		for ident, file := range p.Locales {
+			if !filepath.IsAbs(file) {
+				p.Locales[ident] = filepath.Join(dir, file)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &c, nil
+}
+
+// Validate returns an error if a required field is missing.
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return errors.New("no url set")
+	}
+	if len(c.Projects) == 0 {
+		return errors.New("no projects set")
+	}
+
+	for _, p := range c.Projects {
+		switch {
+		case p.ID == "":
+			return errors.New("project without id")
+		case p.ClientID == "" || p.ClientSecret == "":
+			return fmt.Errorf("project %s: no client credentials set", p.ID)
+		case len(p.Locales) == 0:
+			return fmt.Errorf("project %s: no locales set", p.ID)
+		}
+		if _, ok := export.NewExporter(p.Type); !ok {
+			return fmt.Errorf("project %s: unknown type '%s'", p.ID, p.Type)
+		}
+		if _, ok := p.Locales[p.Source]; p.Source != "" && !ok {
+			return fmt.Errorf("project %s: source locale %s has no file", p.ID, p.Source)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Command parrot syncs the translations of a repository with a Parrot server.
+//
+// The projects, locales and files are set in a .parrot.yml file:
+//
+//	url: https://parrot.example.com
+//	projects:
+//	  - id: 6f7c0d0e-8c4b-4a4e-9a8e-0d6f5d1c2b3a
+//	    client_id: ${PARROT_CLIENT_ID}
+//	    client_secret: ${PARROT_CLIENT_SECRET}
+//	    type: keyvaluejson
+//	    source: en
+//	    locales:
+//	      en: locales/en.json
+//	      fr: locales/fr.json
+//
+// 'parrot pull' writes the exported locales to their files and 'parrot push'
+// adds the keys of the source locale's file that are missing from the project.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	command := os.Args[1]
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	configPath := fs.String("config", ".parrot.yml", "config file")
+	dryRun := fs.Bool("dry-run", false, "list the new keys without adding them (push only)")
+	fs.Parse(os.Args[2:])
+
+	if command != "pull" && command != "push" {
+		usage()
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		fail(err)
+	}
+
+	if command == "pull" {
+		err = Pull(config, os.Stdout)
+	} else {
+		err = Push(config, *dryRun, os.Stdout)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: parrot pull|push [-config .parrot.yml] [-dry-run]\n")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "parrot: %v\n", err)
+	os.Exit(1)
+}
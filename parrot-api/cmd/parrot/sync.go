@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/export"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+var pluralCategories = []string{
+	model.PluralZero,
+	model.PluralOne,
+	model.PluralTwo,
+	model.PluralFew,
+	model.PluralMany,
+	model.PluralOther,
+}
+
+// Pull downloads every locale of the config's projects to its file.
+func Pull(c *Config, out io.Writer) error {
+	for _, p := range c.Projects {
+		client, err := NewClient(c.URL, p.ClientID, p.ClientSecret)
+		if err != nil {
+			return fmt.Errorf("project %s: %v", p.ID, err)
+		}
+
+		for _, ident := range sortedIdents(p.Locales) {
+			data, err := client.ExportLocale(p.ID, ident, p.Type)
+			if err != nil {
+				return fmt.Errorf("project %s: locale %s: %v", p.ID, ident, err)
+			}
+
+			path := p.Locales[ident]
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(path, data, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "pulled %s to %s\n", ident, path)
+		}
+	}
+
+	return nil
+}
+
+// Push adds the keys of each project's source locale file that the project
+// doesn't have yet. Values are not uploaded. With dryRun set, the new keys
+// are only listed.
+func Push(c *Config, dryRun bool, out io.Writer) error {
+	for _, p := range c.Projects {
+		if p.Source == "" {
+			fmt.Fprintf(out, "project %s: no source locale set, skipping\n", p.ID)
+			continue
+		}
+
+		importer, ok := export.NewImporter(p.Type)
+		if !ok {
+			return fmt.Errorf("project %s: type '%s' can't be imported", p.ID, p.Type)
+		}
+		data, err := ioutil.ReadFile(p.Locales[p.Source])
+		if err != nil {
+			return err
+		}
+
+		client, err := NewClient(c.URL, p.ClientID, p.ClientSecret)
+		if err != nil {
+			return fmt.Errorf("project %s: %v", p.ID, err)
+		}
+		project, err := client.GetProject(p.ID)
+		if err != nil {
+			return fmt.Errorf("project %s: %v", p.ID, err)
+		}
+
+		if nester, ok := importer.(export.Nester); ok {
+			nester.SetKeyDelimiter(project.KeyDelimiter)
+		}
+		locale, err := importer.Import(data)
+		if err != nil {
+			return fmt.Errorf("project %s: %s: %v", p.ID, p.Locales[p.Source], err)
+		}
+
+		keys := newKeys(project, locale)
+		for _, key := range keys {
+			_, plural := locale.Plurals[key]
+			if !dryRun {
+				if err := client.AddKey(p.ID, key, plural); err != nil {
+					return fmt.Errorf("project %s: key %s: %v", p.ID, key, err)
+				}
+			}
+			fmt.Fprintf(out, "new key %s\n", key)
+		}
+		fmt.Fprintf(out, "project %s: %d new keys\n", p.ID, len(keys))
+	}
+
+	return nil
+}
+
+// newKeys returns the keys of the locale that the project doesn't have, sorted.
+// Plural forms exported with a category suffix, such as 'items_one', belong
+// to the project's plural key 'items'.
+func newKeys(project *model.Project, locale *model.Locale) []string {
+	known := make(map[string]bool, len(project.Keys))
+	for _, k := range project.Keys {
+		known[k] = true
+	}
+	for _, k := range project.PluralKeys {
+		for _, c := range pluralCategories {
+			known[k+"_"+c] = true
+		}
+	}
+
+	var keys []string
+	add := func(k string) {
+		if k = strings.Trim(k, " "); k != "" && !known[k] {
+			known[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range locale.Pairs {
+		add(k)
+	}
+	for k := range locale.Plurals {
+		add(k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedIdents(locales map[string]string) []string {
+	idents := make([]string, 0, len(locales))
+	for ident := range locales {
+		idents = append(idents, ident)
+	}
+	sort.Strings(idents)
+	return idents
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeServer serves the routes used by the sync client for a single project.
+type fakeServer struct {
+	keys        []string
+	pluralKeys  []string
+	added       []string
+	addedPlural []string
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	write := func(status int, payload interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"meta":    map[string]interface{}{"status": status},
+			"payload": payload,
+		})
+	}
+
+	if r.URL.Path == "/api/v1/auth/token" {
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_secret") != "secret" {
+			write(http.StatusUnauthorized, nil)
+			return
+		}
+		write(http.StatusOK, map[string]string{"access_token": "token"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" {
+		write(http.StatusUnauthorized, nil)
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/v1/projects/p1":
+		write(http.StatusOK, map[string]interface{}{"id": "p1", "keys": s.keys, "plural_keys": s.pluralKeys})
+	case r.Method == "POST" && r.URL.Path == "/api/v1/projects/p1/keys":
+		var body struct {
+			Key    string
+			Plural bool
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.added = append(s.added, body.Key)
+		if body.Plural {
+			s.addedPlural = append(s.addedPlural, body.Key)
+		}
+		write(http.StatusOK, nil)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/api/v1/projects/p1/locales/"):
+		parts := strings.Split(r.URL.Path, "/")
+		w.Write([]byte(`{"hello": "` + parts[6] + `"}`))
+	default:
+		write(http.StatusNotFound, nil)
+	}
+}
+
+func setup(t *testing.T, s *fakeServer) (*Config, string, func()) {
+	srv := httptest.NewServer(s)
+	dir, err := ioutil.TempDir("", "parrot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	}
+
+	os.Setenv("PARROT_TEST_SECRET", "secret")
+	config := `url: ` + srv.URL + `
+projects:
+  - id: p1
+    client_id: c1
+    client_secret: ${PARROT_TEST_SECRET}
+    type: keyvaluejson
+    source: en
+    locales:
+      en: locales/en.json
+      fr: locales/fr.json
+`
+	path := filepath.Join(dir, ".parrot.yml")
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return c, dir, cleanup
+}
+
+func TestPull(t *testing.T) {
+	c, dir, cleanup := setup(t, &fakeServer{})
+	defer cleanup()
+
+	if err := Pull(c, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "locales", "fr.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"hello": "fr"}` {
+		t.Errorf("unexpected fr file: %s", data)
+	}
+}
+
+func TestPush(t *testing.T) {
+	s := &fakeServer{keys: []string{"hello", "items"}, pluralKeys: []string{"items"}}
+	c, dir, cleanup := setup(t, s)
+	defer cleanup()
+
+	os.MkdirAll(filepath.Join(dir, "locales"), 0755)
+	source := `{"hello": "Hello", "items_one": "One item", "items_other": "{{count}} items", "bye": "Bye", "menu": {"title": "Menu"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "locales", "en.json"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Push(c, true, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.added) != 0 {
+		t.Fatalf("dry run added keys: %v", s.added)
+	}
+
+	if err := Push(c, false, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"bye", "menu.title"}
+	if !reflect.DeepEqual(s.added, expected) {
+		t.Errorf("expected added keys %v, got %v", expected, s.added)
+	}
+	if len(s.addedPlural) != 0 {
+		t.Errorf("expected no plural keys, got %v", s.addedPlural)
+	}
+}
+
+func TestPushPluralKeys(t *testing.T) {
+	s := &fakeServer{keys: []string{"items"}, pluralKeys: []string{"items"}}
+	c, dir, cleanup := setup(t, s)
+	defer cleanup()
+	c.Projects[0].Type = "i18next"
+
+	os.MkdirAll(filepath.Join(dir, "locales"), 0755)
+	source := `{"items_one": "One item", "items_other": "{{count}} items", "apples_one": "One apple", "apples_other": "{{count}} apples", "bye": "Bye"}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "locales", "en.json"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Push(c, false, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"apples", "bye"}; !reflect.DeepEqual(s.added, expected) {
+		t.Errorf("expected added keys %v, got %v", expected, s.added)
+	}
+	if expected := []string{"apples"}; !reflect.DeepEqual(s.addedPlural, expected) {
+		t.Errorf("expected added plural keys %v, got %v", expected, s.addedPlural)
+	}
+}
+
+func TestInvalidCredentials(t *testing.T) {
+	c, _, cleanup := setup(t, &fakeServer{})
+	defer cleanup()
+
+	c.Projects[0].ClientSecret = "wrong"
+	if err := Pull(c, ioutil.Discard); err == nil {
+		t.Fatal("expected an authentication error")
+	}
+}
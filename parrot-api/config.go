@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kataras/golog"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore"
+)
+
+// newFlagSet creates the flag set of a command, printing its usage line first on errors.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: parrot-api %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// envString defines a string flag whose default value is taken from
+// the environment variable env, or value if it isn't set.
+func envString(fs *flag.FlagSet, name, env, value, usage string) *string {
+	if v := os.Getenv(env); v != "" {
+		value = v
+	}
+	return fs.String(name, value, fmt.Sprintf("%s (env %s)", usage, env))
+}
+
+// dbConfig holds the flags shared by the commands that use the datastore.
+type dbConfig struct {
+	name          *string
+	url           *string
+	migrationsDir *string
+}
+
+func dbFlags(fs *flag.FlagSet) *dbConfig {
+	return &dbConfig{
+		name:          envString(fs, "db", "PARROT_API_DB", "", "datastore: postgres, sqlite or memory"),
+		url:           envString(fs, "db-url", "PARROT_API_DB_URL", "", "datastore connection url"),
+		migrationsDir: envString(fs, "migrations-dir", "PARROT_DB_MIGRATIONS_DIR", "", "migrations directory (default ./datastore/<db>/migrations)"),
+	}
+}
+
+// open creates the datastore. If retry is set, it blocks until the datastore
+// answers, otherwise it fails on the first failed ping.
+func (c *dbConfig) open(retry bool) (datastore.Store, error) {
+	if *c.name == "" || (*c.url == "" && *c.name != "memory") {
+		return nil, errors.New("no db set, use -db and -db-url")
+	}
+
+	ds, err := datastore.NewDatastore(*c.name, *c.url)
+	if err != nil {
+		return nil, err
+	}
+
+	if !retry {
+		if err := ds.Ping(); err != nil {
+			ds.Close()
+			return nil, err
+		}
+		return ds, nil
+	}
+
+	// Ping DB until service is up, block meanwhile
+	blockAndRetry(5*time.Second, func() bool {
+		if err = ds.Ping(); err != nil {
+			golog.Error(fmt.Sprintf("failed to ping datastore.\nerr: %s", err))
+			return false
+		}
+		return true
+	})
+
+	return ds, nil
+}
+
+// dir returns the migrations directory.
+func (c *dbConfig) dir() string {
+	if *c.migrationsDir != "" {
+		return *c.migrationsDir
+	}
+	return fmt.Sprintf("./datastore/%s/migrations", *c.name)
+}
+
+func blockAndRetry(d time.Duration, fn func() bool) {
+	for !fn() {
+		golog.Infof("retrying in %s...\n", d.String())
+		time.Sleep(d)
+	}
+}
+
+// printJSON writes v to the standard output, indented.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
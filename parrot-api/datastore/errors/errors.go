@@ -8,4 +8,5 @@ var (
 	ErrNotImplemented = errors.New("database not implemented")
 	ErrNotFound       = errors.New("datastore: entry not found")
 	ErrAlreadyExists  = errors.New("datastore: entry already exists")
+	ErrStaleVersion   = errors.New("datastore: entry was modified since the expected version")
 )
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) AddHistoryEntries(entries []model.HistoryEntry) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.addHistoryEntries(entries)
+	return nil
+}
+
+// addHistoryEntries stores the entries, the caller must hold the write lock.
+func (db *MemoryDB) addHistoryEntries(entries []model.HistoryEntry) {
+	createdAt := now()
+	for _, e := range entries {
+		e.ID = newID()
+		e.CreatedAt = createdAt
+		db.history = append(db.history, e)
+	}
+}
+
+// writeJournal stores the entries recorded in the journal, if any, and queues
+// the deliveries of its events. The caller must hold the write lock.
+func (db *MemoryDB) writeJournal(j *model.Journal) {
+	if j != nil {
+		db.addHistoryEntries(j.Entries)
+		db.enqueueEvents(j.Events())
+	}
+}
+
+func (db *MemoryDB) GetLocaleHistory(projID, localeIdent, key string) ([]model.HistoryEntry, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Entries are appended in order, walk them backwards for the newest first
+	entries := make([]model.HistoryEntry, 0)
+	for i := len(db.history) - 1; i >= 0; i-- {
+		e := db.history[i]
+		if e.ProjectID != projID {
+			continue
+		}
+		if e.LocaleIdent != localeIdent && e.LocaleIdent != "" {
+			continue
+		}
+		if key != "" && e.Key != key && !(e.Action == model.HistoryKeyRenamed && e.NewValue == key) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (db *MemoryDB) GetHistoryEntry(projID, entryID string) (*model.HistoryEntry, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, e := range db.history {
+		if e.ProjectID == projID && e.ID == entryID {
+			return &e, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
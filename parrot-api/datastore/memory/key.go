@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetProjectKeys(projectID string) ([]model.Key, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	keys := make([]model.Key, 0)
+	for _, k := range db.keys[projectID] {
+		keys = append(keys, db.projectKey(k))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+
+	return keys, nil
+}
+
+func (db *MemoryDB) GetProjectKey(projectID, key string) (*model.Key, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	k, ok := db.keys[projectID][key]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	result := db.projectKey(k)
+	return &result, nil
+}
+
+func (db *MemoryDB) UpdateProjectKeyMeta(key model.Key) (*model.Key, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k, ok := db.keys[key.ProjectID][key.Key]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	k.Description = key.Description
+	k.Context = key.Context
+	k.MaxLength = key.MaxLength
+	k.Tags = copyStrings(key.Tags)
+	k.UpdatedAt = now()
+	db.keys[key.ProjectID][key.Key] = k
+
+	result := db.projectKey(k)
+	return &result, nil
+}
+
+// syncKeyRecords creates the metadata records of new project keys and removes
+// the ones of keys that are no longer part of the project.
+func (db *MemoryDB) syncKeyRecords(projectID string) {
+	keys := db.projects[projectID].Keys
+	records, ok := db.keys[projectID]
+	if !ok {
+		records = make(map[string]model.Key)
+		db.keys[projectID] = records
+	}
+
+	for _, key := range keys {
+		if _, ok := records[key]; !ok {
+			createdAt := now()
+			records[key] = model.Key{ProjectID: projectID, Key: key, Tags: []string{}, CreatedAt: createdAt, UpdatedAt: createdAt}
+		}
+	}
+	for key := range records {
+		if !contains(keys, key) {
+			delete(records, key)
+		}
+	}
+}
+
+// projectKey returns a copy of the key record with its plural flag set from the project.
+func (db *MemoryDB) projectKey(k model.Key) model.Key {
+	k.Tags = copyStrings(k.Tags)
+	k.Plural = contains(db.projects[k.ProjectID].PluralKeys, k.Key)
+	return k
+}
+
+// now returns the current time, with the microsecond precision of Postgres timestamps.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
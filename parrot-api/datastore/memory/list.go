@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// page sorts the indexes of n items by the sort value and id returned by key,
+// skips the ones up to the cursor and applies the limit. It returns the indexes
+// of the page along with the cursor of the next one.
+func page(n int, key func(i int) (string, string), opts model.ListOptions) ([]int, *model.Cursor) {
+	less := func(v1, id1, v2, id2 string) bool {
+		if v1 == v2 {
+			v1, v2 = id1, id2
+		}
+		if opts.Desc {
+			return v1 > v2
+		}
+		return v1 < v2
+	}
+
+	idx := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if opts.After != nil {
+			v, id := key(i)
+			if !less(opts.After.Value, opts.After.ID, v, id) {
+				continue
+			}
+		}
+		idx = append(idx, i)
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		v1, id1 := key(idx[i])
+		v2, id2 := key(idx[j])
+		return less(v1, id1, v2, id2)
+	})
+
+	next := opts.NextCursor(len(idx), func(i int) (string, string) { return key(idx[i]) })
+	if next != nil {
+		idx = idx[:opts.Limit]
+	}
+	return idx, next
+}
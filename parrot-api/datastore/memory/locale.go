@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) CreateLocale(loc model.Locale, j *model.Journal) (*model.Locale, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[loc.ProjectID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	if _, ok := db.findLocale(loc.ProjectID, loc.Ident); ok {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	loc.ID = newID()
+	loc.Version = 1
+	stored := copyLocale(loc)
+	stored.Statuses = make(map[string]string)
+	db.locales[loc.ID] = stored
+	j.Emit(loc.ProjectID, model.EventLocaleCreated, map[string]interface{}{"locale": loc.Ident})
+	db.writeJournal(j)
+
+	return &loc, nil
+}
+
+func (db *MemoryDB) UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPairs(copyPairs(pairs))
+	})
+}
+
+func (db *MemoryDB) MergeLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.PutPairs(pairs)
+	})
+}
+
+func (db *MemoryDB) UpdateLocalePlurals(projID string, localeIdent string, plurals map[string]model.PluralForms, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPlurals(plurals)
+	})
+}
+
+func (db *MemoryDB) UpdateLocaleStatuses(projID string, localeIdent string, statuses map[string]string) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, 0, nil, func(loc *model.Locale) {
+		// Translated is the default status of values, so it is not stored
+		for k, v := range statuses {
+			if v == model.StatusTranslated {
+				delete(loc.Statuses, k)
+				continue
+			}
+			loc.Statuses[k] = v
+		}
+	})
+}
+
+func (db *MemoryDB) UpdateLocales(projID string, localeIdents []string, fn func(*model.Locale) error, j *model.Journal) ([]model.Locale, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var ids []string
+	for _, ident := range localeIdents {
+		id, ok := db.findLocale(projID, ident)
+		if !ok {
+			return nil, errors.ErrNotFound
+		}
+		if !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	// Nothing is stored unless fn succeeds for every locale
+	locs := make([]model.Locale, len(ids))
+	for i, id := range ids {
+		locs[i] = copyLocale(db.locales[id])
+		before := locs[i].Copy()
+		if err := fn(&locs[i]); err != nil {
+			return nil, err
+		}
+		locs[i].Version++
+		j.RecordLocale(before, &locs[i])
+	}
+	for i, id := range ids {
+		db.locales[id] = copyLocale(locs[i])
+	}
+	db.writeJournal(j)
+
+	return locs, nil
+}
+
+func (db *MemoryDB) DeleteLocale(projID string, ident string, j *model.Journal) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if id, ok := db.findLocale(projID, ident); ok {
+		delete(db.locales, id)
+		j.Emit(projID, model.EventLocaleDeleted, map[string]interface{}{"locale": ident})
+		db.writeJournal(j)
+	}
+	return nil
+}
+
+func (db *MemoryDB) ImportLocale(projID, localeIdent string, keys, pluralKeys []string, fn func(*model.Project, *model.Locale) error, j *model.Journal) (*model.Locale, []string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	p, ok := db.projects[projID]
+	if !ok {
+		return nil, nil, errors.ErrNotFound
+	}
+	id, ok := db.findLocale(projID, localeIdent)
+	if !ok {
+		return nil, nil, errors.ErrNotFound
+	}
+
+	// Nothing is stored unless fn succeeds
+	previous := p.Keys
+	p = copyProject(p)
+	var added []string
+	for _, k := range keys {
+		if k == "" || contains(p.Keys, k) {
+			continue
+		}
+		p.Keys = append(p.Keys, k)
+		if contains(pluralKeys, k) {
+			p.PluralKeys = append(p.PluralKeys, k)
+		}
+		added = append(added, k)
+	}
+
+	loc := copyLocale(db.locales[id])
+	before := loc.Copy()
+	if err := fn(&p, &loc); err != nil {
+		return nil, nil, err
+	}
+	loc.Version++
+
+	db.projects[projID] = copyProject(p)
+	db.syncKeyRecords(projID)
+	db.locales[id] = copyLocale(loc)
+	j.Record(model.KeyChanges(projID, previous, p.Keys)...)
+	j.RecordLocale(before, &loc)
+	db.writeJournal(j)
+
+	result := copyLocale(loc)
+	return &result, added, nil
+}
+
+// updateLocale applies fn to a copy of the stored locale, then stores it along
+// with the history recorded in the journal.
+// A non-zero version must match the one of the stored locale.
+func (db *MemoryDB) updateLocale(projID, ident string, version int, j *model.Journal, fn func(*model.Locale)) (*model.Locale, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id, ok := db.findLocale(projID, ident)
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	loc := copyLocale(db.locales[id])
+	if version != 0 && loc.Version != version {
+		return nil, errors.ErrStaleVersion
+	}
+	before := loc.Copy()
+	fn(&loc)
+	loc.Version++
+	db.locales[id] = copyLocale(loc)
+	j.RecordLocale(before, &loc)
+	db.writeJournal(j)
+
+	result := copyLocale(loc)
+	return &result, nil
+}
+
+// findLocale returns the id of the project locale with the ident.
+func (db *MemoryDB) findLocale(projID, ident string) (string, bool) {
+	for id, loc := range db.locales {
+		if loc.ProjectID == projID && loc.Ident == ident {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,168 @@
+// Package memory holds an in-memory implementation of the datastore.Store,
+// meant for tests and local development. Data is lost when the process exits.
+package memory
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/migrate"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// MemoryDB implements the datastore.Store interface in memory.
+// It is safe for concurrent use.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	users         map[string]model.User
+	projects      map[string]model.Project
+	locales       map[string]model.Locale
+	projectUsers  map[projectUserKey]string
+	clients       map[string]model.ProjectClient
+	history       []model.HistoryEntry
+	keys          map[string]map[string]model.Key
+	refreshTokens map[string]model.RefreshToken
+	revokedTokens map[string]time.Time
+	// revokedSubjects maps subject ids to the revocation of their access tokens.
+	revokedSubjects map[string]revokedSubject
+	webhooks        map[string]model.Webhook
+	deliveries      []model.WebhookDelivery
+	// translationConfigs maps project ids to their translation config.
+	translationConfigs map[string]model.TranslationConfig
+}
+
+// projectUserKey identifies the role of a user in a project.
+type projectUserKey struct {
+	projectID string
+	userID    string
+}
+
+// New creates an empty in-memory datastore.
+func New() *MemoryDB {
+	db := &MemoryDB{}
+	db.reset()
+	return db
+}
+
+func (db *MemoryDB) reset() {
+	db.users = make(map[string]model.User)
+	db.projects = make(map[string]model.Project)
+	db.locales = make(map[string]model.Locale)
+	db.projectUsers = make(map[projectUserKey]string)
+	db.clients = make(map[string]model.ProjectClient)
+	db.history = nil
+	db.keys = make(map[string]map[string]model.Key)
+	db.refreshTokens = make(map[string]model.RefreshToken)
+	db.revokedTokens = make(map[string]time.Time)
+	db.revokedSubjects = make(map[string]revokedSubject)
+	db.webhooks = make(map[string]model.Webhook)
+	db.deliveries = nil
+	db.translationConfigs = make(map[string]model.TranslationConfig)
+}
+
+func (db *MemoryDB) Ping() error {
+	return nil
+}
+
+func (db *MemoryDB) Close() error {
+	return nil
+}
+
+// MigrateUp is a no-op, the in-memory datastore has no schema.
+func (db *MemoryDB) MigrateUp(string) error {
+	return nil
+}
+
+// MigrateDown drops all the data.
+func (db *MemoryDB) MigrateDown(string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.reset()
+	return nil
+}
+
+// MigrateTo is a no-op, the in-memory datastore has no schema.
+func (db *MemoryDB) MigrateTo(string, int64) error {
+	return nil
+}
+
+// MigrationStatus returns no migrations, the in-memory datastore has no schema.
+func (db *MemoryDB) MigrationStatus(string) ([]migrate.Status, error) {
+	return []migrate.Status{}, nil
+}
+
+// newID returns a random (version 4) UUID, the same format used for ids by Postgres.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// copyStrings returns a copy of s, never nil.
+func copyStrings(s []string) []string {
+	result := make([]string, len(s))
+	copy(result, s)
+	return result
+}
+
+// copyPairs returns a copy of m, never nil.
+func copyPairs(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+// copyProject returns a deep copy of p, so that callers can't modify the stored project.
+func copyProject(p model.Project) model.Project {
+	p.Keys = copyStrings(p.Keys)
+	p.PluralKeys = copyStrings(p.PluralKeys)
+	fallbacks := make(map[string][]string, len(p.Fallbacks))
+	for k, v := range p.Fallbacks {
+		fallbacks[k] = copyStrings(v)
+	}
+	p.Fallbacks = fallbacks
+	return p
+}
+
+// copyLocale returns a deep copy of loc, so that callers can't modify the stored locale.
+func copyLocale(loc model.Locale) model.Locale {
+	loc.Pairs = copyPairs(loc.Pairs)
+	loc.Statuses = copyPairs(loc.Statuses)
+	plurals := make(map[string]model.PluralForms, len(loc.Plurals))
+	for k, v := range loc.Plurals {
+		plurals[k] = model.PluralForms(copyPairs(v))
+	}
+	loc.Plurals = plurals
+	return loc
+}
+
+// contains returns true if s holds v.
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns s without v.
+func remove(s []string, v string) []string {
+	result := make([]string, 0, len(s))
+	for _, e := range s {
+		if e != v {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, memory.New())
+}
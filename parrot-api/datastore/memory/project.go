@@ -0,0 +1,341 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetProjects() ([]model.Project, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	projects := make([]model.Project, 0, len(db.projects))
+	for _, p := range db.projects {
+		projects = append(projects, copyProject(p))
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+func (db *MemoryDB) GetProject(id string) (*model.Project, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	p, ok := db.projects[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	result := copyProject(p)
+	return &result, nil
+}
+
+func (db *MemoryDB) CreateProject(project model.Project) (*model.Project, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	p := model.Project{
+		ID:           newID(),
+		Name:         project.Name,
+		Keys:         copyStrings(project.Keys),
+		KeyDelimiter: model.DefaultKeyDelimiter,
+	}
+	db.projects[p.ID] = copyProject(p)
+	db.syncKeyRecords(p.ID)
+
+	result := copyProject(p)
+	return &result, nil
+}
+
+func (db *MemoryDB) UpdateProjectName(projectID, name string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.Name = name
+		return nil
+	})
+}
+
+func (db *MemoryDB) AddProjectKey(key model.Key, j *model.Journal) (*model.Project, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	p, ok := db.projects[key.ProjectID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	if contains(p.Keys, key.Key) {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	p = copyProject(p)
+	p.Keys = append(p.Keys, key.Key)
+	if key.Plural {
+		p.PluralKeys = append(p.PluralKeys, key.Key)
+	}
+	db.projects[key.ProjectID] = copyProject(p)
+	db.syncKeyRecords(key.ProjectID)
+
+	k := db.keys[key.ProjectID][key.Key]
+	k.Description = key.Description
+	k.Context = key.Context
+	k.MaxLength = key.MaxLength
+	k.Tags = copyStrings(key.Tags)
+	db.keys[key.ProjectID][key.Key] = k
+
+	j.Record(model.KeyChanges(key.ProjectID, nil, []string{key.Key})...)
+	if key.Plural {
+		j.Record(model.KeyPluralUpdated(key.ProjectID, key.Key, true))
+	}
+	db.writeJournal(j)
+
+	return &p, nil
+}
+
+func (db *MemoryDB) UpdateProjectKey(projectID, oldKey, newKey string, j *model.Journal) (*model.Project, int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	p, ok := db.projects[projectID]
+	if !ok {
+		return nil, -1, errors.ErrNotFound
+	}
+	if !contains(p.Keys, oldKey) {
+		return nil, -1, errors.ErrNotFound
+	}
+	if contains(p.Keys, newKey) {
+		return nil, -1, errors.ErrAlreadyExists
+	}
+
+	p = copyProject(p)
+	for i, k := range p.Keys {
+		if k == oldKey {
+			p.Keys[i] = newKey
+		}
+	}
+	for i, k := range p.PluralKeys {
+		if k == oldKey {
+			p.PluralKeys[i] = newKey
+		}
+	}
+	db.projects[projectID] = p
+
+	// Move the key's metadata to the new key
+	if k, ok := db.keys[projectID][oldKey]; ok {
+		delete(db.keys[projectID], oldKey)
+		k.Key = newKey
+		k.UpdatedAt = now()
+		db.keys[projectID][newKey] = k
+	}
+
+	// Rename the key in every project locale, keeping its values
+	count := 0
+	for id, loc := range db.locales {
+		if loc.ProjectID != projectID {
+			continue
+		}
+		count++
+
+		loc = copyLocale(loc)
+		if v, ok := loc.Pairs[oldKey]; ok {
+			delete(loc.Pairs, oldKey)
+			loc.Pairs[newKey] = v
+		}
+		if forms, ok := loc.Plurals[oldKey]; ok {
+			delete(loc.Plurals, oldKey)
+			loc.Plurals[newKey] = forms
+		}
+		if status, ok := loc.Statuses[oldKey]; ok {
+			delete(loc.Statuses, oldKey)
+			loc.Statuses[newKey] = status
+		}
+		loc.Version++
+		db.locales[id] = loc
+	}
+
+	j.Record(model.KeyRenamed(projectID, oldKey, newKey))
+	db.writeJournal(j)
+
+	result := copyProject(p)
+	return &result, count, nil
+}
+
+func (db *MemoryDB) DeleteProjectKey(projectID, key string, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(projectID, j, func(p *model.Project) error {
+		if !contains(p.Keys, key) {
+			return errors.ErrNotFound
+		}
+		p.Keys = remove(p.Keys, key)
+		p.PluralKeys = remove(p.PluralKeys, key)
+		return nil
+	})
+}
+
+func (db *MemoryDB) SetProjectKeyPlural(projectID, key string, plural bool, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(projectID, j, func(p *model.Project) error {
+		if !contains(p.Keys, key) {
+			return errors.ErrNotFound
+		}
+		if contains(p.PluralKeys, key) != plural {
+			j.Record(model.KeyPluralUpdated(projectID, key, plural))
+		}
+		p.PluralKeys = remove(p.PluralKeys, key)
+		if plural {
+			p.PluralKeys = append(p.PluralKeys, key)
+		}
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProjectFallbacks(projectID string, fallbacks map[string][]string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.Fallbacks = fallbacks
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProjectSourceLocale(projectID, ident string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.SourceLocale = ident
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProjectKeyDelimiter(projectID, delimiter string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.KeyDelimiter = delimiter
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProject(project model.Project, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(project.ID, j, func(p *model.Project) error {
+		p.Keys = copyStrings(project.Keys)
+		p.PluralKeys = copyStrings(project.PluralKeys)
+		return nil
+	})
+}
+
+func (db *MemoryDB) DeleteProject(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[id]; !ok {
+		return nil
+	}
+
+	// Same cascades as the foreign keys of the Postgres schema
+	delete(db.projects, id)
+	delete(db.keys, id)
+	for locID, loc := range db.locales {
+		if loc.ProjectID == id {
+			delete(db.locales, locID)
+		}
+	}
+	for k := range db.projectUsers {
+		if k.projectID == id {
+			delete(db.projectUsers, k)
+		}
+	}
+	for clientID, c := range db.clients {
+		if c.ProjectID == id {
+			delete(db.clients, clientID)
+		}
+	}
+	history := make([]model.HistoryEntry, 0, len(db.history))
+	for _, e := range db.history {
+		if e.ProjectID != id {
+			history = append(history, e)
+		}
+	}
+	db.history = history
+	for webhookID, w := range db.webhooks {
+		if w.ProjectID == id {
+			delete(db.webhooks, webhookID)
+		}
+	}
+	db.deleteDeliveries(func(d model.WebhookDelivery) bool { return d.ProjectID == id })
+	delete(db.translationConfigs, id)
+
+	return nil
+}
+
+func (db *MemoryDB) GetProjectLocaleByIdent(projectID string, ident string) (*model.Locale, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	id, ok := db.findLocale(projectID, ident)
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	result := copyLocale(db.locales[id])
+	return &result, nil
+}
+
+func (db *MemoryDB) GetProjectLocales(projID string, localeIdents ...string) ([]model.Locale, error) {
+	locs, _, err := db.FindProjectLocales(projID, model.LocaleQuery{Idents: localeIdents})
+	return locs, err
+}
+
+func (db *MemoryDB) FindProjectLocales(projID string, q model.LocaleQuery) ([]model.Locale, *model.Cursor, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	q.Sort = q.SortField(model.LocaleSortFields)
+	var all []model.Locale
+	for _, loc := range db.locales {
+		if loc.ProjectID != projID {
+			continue
+		}
+		if len(q.Idents) > 0 && !contains(q.Idents, loc.Ident) {
+			continue
+		}
+		all = append(all, loc)
+	}
+
+	idx, next := page(len(all), func(i int) (string, string) {
+		return all[i].SortValue(q.Sort), all[i].ID
+	}, q.ListOptions)
+	locs := make([]model.Locale, 0, len(idx))
+	for _, i := range idx {
+		loc := copyLocale(all[i])
+		if q.WithoutPairs {
+			loc.Pairs = make(map[string]string)
+			loc.Plurals = make(map[string]model.PluralForms)
+			loc.Statuses = make(map[string]string)
+		}
+		locs = append(locs, loc)
+	}
+
+	return locs, next, nil
+}
+
+// updateProject applies fn to a copy of the stored project, then stores it and
+// syncs the key metadata records with its keys. The keys added and deleted are
+// recorded in the journal.
+func (db *MemoryDB) updateProject(projectID string, j *model.Journal, fn func(*model.Project) error) (*model.Project, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	p, ok := db.projects[projectID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	keys := p.Keys
+	p = copyProject(p)
+	if err := fn(&p); err != nil {
+		return nil, err
+	}
+	db.projects[projectID] = copyProject(p)
+	db.syncKeyRecords(projectID)
+	j.Record(model.KeyChanges(projectID, keys, p.Keys)...)
+	db.writeJournal(j)
+
+	return &p, nil
+}
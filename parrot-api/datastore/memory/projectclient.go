@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetProjectClients(projectID string, opts model.ListOptions) ([]model.ProjectClient, *model.Cursor, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	opts.Sort = opts.SortField(model.ProjectClientSortFields)
+	var all []model.ProjectClient
+	for _, c := range db.clients {
+		if c.ProjectID == projectID {
+			all = append(all, c)
+		}
+	}
+
+	idx, next := page(len(all), func(i int) (string, string) {
+		return all[i].SortValue(opts.Sort), all[i].ClientID
+	}, opts)
+	result := make([]model.ProjectClient, 0, len(idx))
+	for _, i := range idx {
+		result = append(result, all[i])
+	}
+
+	return result, next, nil
+}
+
+func (db *MemoryDB) FindOneClient(clientID string) (*model.ProjectClient, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.clients[clientID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (db *MemoryDB) GetProjectClient(projectID, clientID string) (*model.ProjectClient, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.clients[clientID]
+	if !ok || c.ProjectID != projectID {
+		return nil, errors.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (db *MemoryDB) CreateProjectClient(pc model.ProjectClient) (*model.ProjectClient, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[pc.ProjectID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	if db.clientNameTaken(pc.ProjectID, pc.Name, "") {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	pc.ClientID = newID()
+	db.clients[pc.ClientID] = pc
+	return &pc, nil
+}
+
+func (db *MemoryDB) DeleteProjectClient(projectID, clientID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if c, ok := db.clients[clientID]; ok && c.ProjectID == projectID {
+		delete(db.clients, clientID)
+	}
+	return nil
+}
+
+func (db *MemoryDB) UpdateProjectClientSecret(pc model.ProjectClient) (*model.ProjectClient, error) {
+	return db.updateClient(pc, func(c *model.ProjectClient) error {
+		c.Secret = pc.Secret
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProjectClientName(pc model.ProjectClient) (*model.ProjectClient, error) {
+	return db.updateClient(pc, func(c *model.ProjectClient) error {
+		if db.clientNameTaken(pc.ProjectID, pc.Name, pc.ClientID) {
+			return errors.ErrAlreadyExists
+		}
+		c.Name = pc.Name
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateProjectClientCanAddKeys(pc model.ProjectClient) (*model.ProjectClient, error) {
+	return db.updateClient(pc, func(c *model.ProjectClient) error {
+		c.CanAddKeys = pc.CanAddKeys
+		return nil
+	})
+}
+
+// updateClient applies fn to the stored client with the project and client id of pc.
+func (db *MemoryDB) updateClient(pc model.ProjectClient, fn func(*model.ProjectClient) error) (*model.ProjectClient, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	c, ok := db.clients[pc.ClientID]
+	if !ok || c.ProjectID != pc.ProjectID {
+		return nil, errors.ErrNotFound
+	}
+	if err := fn(&c); err != nil {
+		return nil, err
+	}
+	db.clients[c.ClientID] = c
+
+	return &c, nil
+}
+
+// clientNameTaken returns true if a project client other than the one with exceptID has the name.
+func (db *MemoryDB) clientNameTaken(projectID, name, exceptID string) bool {
+	for id, c := range db.clients {
+		if c.ProjectID == projectID && c.Name == name && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetUserProjects(userID string, opts model.ListOptions) ([]model.Project, *model.Cursor, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	opts.Sort = opts.SortField(model.ProjectSortFields)
+	var all []model.Project
+	for k := range db.projectUsers {
+		if k.userID != userID {
+			continue
+		}
+		if p, ok := db.projects[k.projectID]; ok {
+			all = append(all, p)
+		}
+	}
+
+	idx, next := page(len(all), func(i int) (string, string) {
+		return all[i].SortValue(opts.Sort), all[i].ID
+	}, opts)
+	projects := make([]model.Project, 0, len(idx))
+	for _, i := range idx {
+		projects = append(projects, copyProject(all[i]))
+	}
+
+	return projects, next, nil
+}
+
+func (db *MemoryDB) GetProjectUsers(projID string, opts model.ListOptions) ([]model.ProjectUser, *model.Cursor, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	opts.Sort = opts.SortField(model.ProjectUserSortFields)
+	var all []model.ProjectUser
+	for k := range db.projectUsers {
+		if k.projectID == projID {
+			all = append(all, db.projectUser(k))
+		}
+	}
+
+	idx, next := page(len(all), func(i int) (string, string) {
+		return all[i].SortValue(opts.Sort), all[i].UserID
+	}, opts)
+	users := make([]model.ProjectUser, 0, len(idx))
+	for _, i := range idx {
+		users = append(users, all[i])
+	}
+
+	return users, next, nil
+}
+
+func (db *MemoryDB) GetUserProjectRoles(userID string) ([]model.ProjectUser, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	roles := make([]model.ProjectUser, 0)
+	for k, role := range db.projectUsers {
+		if k.userID == userID {
+			roles = append(roles, model.ProjectUser{UserID: k.userID, ProjectID: k.projectID, Role: role})
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ProjectID < roles[j].ProjectID })
+
+	return roles, nil
+}
+
+func (db *MemoryDB) GetProjectUser(projID, userID string) (*model.ProjectUser, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	k := projectUserKey{projectID: projID, userID: userID}
+	if _, ok := db.projectUsers[k]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	u := db.projectUser(k)
+	return &u, nil
+}
+
+func (db *MemoryDB) AssignProjectUser(pu model.ProjectUser) (*model.ProjectUser, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[pu.ProjectID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	if _, ok := db.users[pu.UserID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	k := projectUserKey{projectID: pu.ProjectID, userID: pu.UserID}
+	if _, ok := db.projectUsers[k]; ok {
+		return nil, errors.ErrAlreadyExists
+	}
+	db.projectUsers[k] = pu.Role
+
+	u := db.projectUser(k)
+	return &u, nil
+}
+
+func (db *MemoryDB) RevokeProjectUser(pu model.ProjectUser) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.projectUsers, projectUserKey{projectID: pu.ProjectID, userID: pu.UserID})
+	return nil
+}
+
+func (db *MemoryDB) UpdateProjectUser(pu model.ProjectUser) (*model.ProjectUser, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := projectUserKey{projectID: pu.ProjectID, userID: pu.UserID}
+	if _, ok := db.projectUsers[k]; !ok {
+		return nil, errors.ErrNotFound
+	}
+	db.projectUsers[k] = pu.Role
+
+	u := db.projectUser(k)
+	return &u, nil
+}
+
+// projectUser returns the project user stored under k, along with the user's email and name.
+func (db *MemoryDB) projectUser(k projectUserKey) model.ProjectUser {
+	u := db.users[k.userID]
+	pu := model.ProjectUser{UserID: k.userID, ProjectID: k.projectID, Role: db.projectUsers[k]}
+	pu.Email = u.Email
+	pu.Name = u.Name
+	return pu
+}
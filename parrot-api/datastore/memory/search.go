@@ -0,0 +1,16 @@
+package memory
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+func (db *MemoryDB) SearchProject(projectID string, q model.SearchQuery) ([]model.SearchResult, error) {
+	project, err := db.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	locs, err := db.GetProjectLocales(projectID, q.Locales...)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.Search(project, locs, q)
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) CreateRefreshToken(token model.RefreshToken) (*model.RefreshToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if token.FamilyID == "" {
+		token.FamilyID = newID()
+	}
+	return db.insertRefreshToken(token)
+}
+
+func (db *MemoryDB) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	t, ok := db.refreshTokens[tokenHash]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (db *MemoryDB) RotateRefreshToken(oldTokenHash string, token model.RefreshToken) (*model.RefreshToken, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// Only an active token can be rotated, so concurrent uses of the same token can't both succeed
+	old, ok := db.refreshTokens[oldTokenHash]
+	if !ok || !old.IsActive(time.Now()) {
+		return nil, errors.ErrNotFound
+	}
+	if _, ok := db.refreshTokens[token.TokenHash]; ok {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	revokedAt := now()
+	old.RevokedAt = &revokedAt
+	db.refreshTokens[oldTokenHash] = old
+
+	return db.insertRefreshToken(token)
+}
+
+func (db *MemoryDB) RevokeRefreshTokenFamily(familyID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	revokedAt := now()
+	for hash, t := range db.refreshTokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &revokedAt
+			db.refreshTokens[hash] = t
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) RevokeSubjectRefreshTokens(subjectID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	revokedAt := now()
+	for hash, t := range db.refreshTokens {
+		if t.SubjectID == subjectID && t.RevokedAt == nil {
+			t.RevokedAt = &revokedAt
+			db.refreshTokens[hash] = t
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.revokedTokens[tokenID]; !ok {
+		db.revokedTokens[tokenID] = expiresAt
+	}
+
+	// Expired tokens are rejected anyway, no need to keep them around
+	current := time.Now()
+	for id, exp := range db.revokedTokens {
+		if exp.Before(current) {
+			delete(db.revokedTokens, id)
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) RevokeSubjectAccessTokens(subjectID string, issuedBefore, expiresAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.revokedSubjects[subjectID] = revokedSubject{issuedBefore: issuedBefore, expiresAt: expiresAt}
+
+	current := time.Now()
+	for id, r := range db.revokedSubjects {
+		if r.expiresAt.Before(current) {
+			delete(db.revokedSubjects, id)
+		}
+	}
+	return nil
+}
+
+func (db *MemoryDB) IsAccessTokenRevoked(tokenID, subjectID string, issuedAt time.Time) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if _, revoked := db.revokedTokens[tokenID]; revoked && tokenID != "" {
+		return true, nil
+	}
+	r, ok := db.revokedSubjects[subjectID]
+	return ok && issuedAt.Before(r.issuedBefore), nil
+}
+
+// revokedSubject holds when the access tokens of a subject were revoked.
+type revokedSubject struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+// insertRefreshToken stores a new refresh token, token hashes are unique.
+func (db *MemoryDB) insertRefreshToken(token model.RefreshToken) (*model.RefreshToken, error) {
+	if _, ok := db.refreshTokens[token.TokenHash]; ok {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	token.ID = newID()
+	token.CreatedAt = now()
+	token.RevokedAt = nil
+	db.refreshTokens[token.TokenHash] = token
+
+	return &token, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetTranslationConfig(projectID string) (*model.TranslationConfig, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	c, ok := db.translationConfigs[projectID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (db *MemoryDB) SetTranslationConfig(c model.TranslationConfig) (*model.TranslationConfig, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[c.ProjectID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	c.UpdatedAt = now()
+	db.translationConfigs[c.ProjectID] = c
+	return &c, nil
+}
+
+func (db *MemoryDB) DeleteTranslationConfig(projectID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.translationConfigs, projectID)
+	return nil
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetUserByEmail(email string) (*model.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, u := range db.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (db *MemoryDB) GetUserByID(id string) (*model.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	u, ok := db.users[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (db *MemoryDB) CreateUser(u model.User) (*model.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.emailTaken(u.Email, "") {
+		return nil, errors.ErrAlreadyExists
+	}
+
+	u.ID = newID()
+	db.users[u.ID] = u
+	return &u, nil
+}
+
+func (db *MemoryDB) UpdateUserPassword(u model.User) (*model.User, error) {
+	return db.updateUser(u, func(stored *model.User) error {
+		stored.Password = u.Password
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateUserName(u model.User) (*model.User, error) {
+	return db.updateUser(u, func(stored *model.User) error {
+		stored.Name = u.Name
+		return nil
+	})
+}
+
+func (db *MemoryDB) UpdateUserEmail(u model.User) (*model.User, error) {
+	return db.updateUser(u, func(stored *model.User) error {
+		if db.emailTaken(u.Email, u.ID) {
+			return errors.ErrAlreadyExists
+		}
+		stored.Email = u.Email
+		return nil
+	})
+}
+
+// updateUser applies fn to the stored user with the id of u. Like the Postgres
+// implementation, it returns the stored id, name and email along with the password of u.
+func (db *MemoryDB) updateUser(u model.User, fn func(*model.User) error) (*model.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, ok := db.users[u.ID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	if err := fn(&stored); err != nil {
+		return nil, err
+	}
+	db.users[u.ID] = stored
+
+	result := stored
+	result.Password = u.Password
+	return &result, nil
+}
+
+// emailTaken returns true if a user other than the one with exceptID has the email.
+func (db *MemoryDB) emailTaken(email, exceptID string) bool {
+	for id, u := range db.users {
+		if u.Email == email && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *MemoryDB) GetProjectWebhooks(projectID string) ([]model.Webhook, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	result := make([]model.Webhook, 0)
+	for _, w := range db.webhooks {
+		if w.ProjectID == projectID {
+			result = append(result, copyWebhook(w))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+func (db *MemoryDB) GetProjectWebhook(projectID, webhookID string) (*model.Webhook, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	w, ok := db.webhooks[webhookID]
+	if !ok || w.ProjectID != projectID {
+		return nil, errors.ErrNotFound
+	}
+	result := copyWebhook(w)
+	return &result, nil
+}
+
+func (db *MemoryDB) CreateWebhook(w model.Webhook) (*model.Webhook, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.projects[w.ProjectID]; !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	w.ID = newID()
+	w.CreatedAt = now()
+	db.webhooks[w.ID] = copyWebhook(w)
+
+	result := copyWebhook(w)
+	return &result, nil
+}
+
+func (db *MemoryDB) UpdateWebhook(w model.Webhook) (*model.Webhook, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current, ok := db.webhooks[w.ID]
+	if !ok || current.ProjectID != w.ProjectID {
+		return nil, errors.ErrNotFound
+	}
+
+	current.URL = w.URL
+	current.Secret = w.Secret
+	current.Events = w.Events
+	current.Active = w.Active
+	db.webhooks[w.ID] = copyWebhook(current)
+
+	result := copyWebhook(current)
+	return &result, nil
+}
+
+func (db *MemoryDB) DeleteWebhook(projectID, webhookID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	w, ok := db.webhooks[webhookID]
+	if !ok || w.ProjectID != projectID {
+		return nil
+	}
+
+	delete(db.webhooks, webhookID)
+	db.deleteDeliveries(func(d model.WebhookDelivery) bool { return d.WebhookID == webhookID })
+	return nil
+}
+
+func (db *MemoryDB) CreateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	w, ok := db.webhooks[d.WebhookID]
+	if !ok || w.ProjectID != d.ProjectID {
+		return nil, errors.ErrNotFound
+	}
+
+	result := db.addDelivery(d)
+	return &result, nil
+}
+
+// addDelivery stores the delivery as pending. The caller must hold the write lock.
+func (db *MemoryDB) addDelivery(d model.WebhookDelivery) model.WebhookDelivery {
+	result := model.WebhookDelivery{
+		ID:            newID(),
+		WebhookID:     d.WebhookID,
+		ProjectID:     d.ProjectID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        model.DeliveryPending,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     now(),
+	}
+	db.deliveries = append(db.deliveries, result)
+	return result
+}
+
+// enqueueEvents stores the deliveries of the events to the webhooks subscribed
+// to them. The caller must hold the write lock.
+// Event data are plain maps, which always marshal, so the change the events
+// describe is never held back by them.
+func (db *MemoryDB) enqueueEvents(events []model.Event) {
+	webhooks := make([]model.Webhook, 0)
+	for _, w := range db.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+
+	for _, e := range events {
+		deliveries, _ := e.Deliveries(webhooks)
+		for _, d := range deliveries {
+			db.addDelivery(d)
+		}
+	}
+}
+
+func (db *MemoryDB) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current := now()
+	var due []int
+	for i, d := range db.deliveries {
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(current) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return db.deliveries[due[i]].NextAttemptAt.Before(db.deliveries[due[j]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	result := make([]model.WebhookDelivery, 0, len(due))
+	for _, i := range due {
+		db.deliveries[i].NextAttemptAt = current.Add(lease)
+		result = append(result, db.deliveries[i])
+	}
+
+	return result, nil
+}
+
+func (db *MemoryDB) UpdateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, current := range db.deliveries {
+		if current.ID != d.ID {
+			continue
+		}
+		current.Status = d.Status
+		current.Attempts = d.Attempts
+		current.NextAttemptAt = d.NextAttemptAt
+		current.LastStatusCode = d.LastStatusCode
+		current.LastError = d.LastError
+		current.DeliveredAt = d.DeliveredAt
+		db.deliveries[i] = current
+		return &current, nil
+	}
+
+	return nil, errors.ErrNotFound
+}
+
+func (db *MemoryDB) GetWebhookDeliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	// Deliveries are stored in creation order
+	result := make([]model.WebhookDelivery, 0)
+	for i := len(db.deliveries) - 1; i >= 0 && len(result) < limit; i-- {
+		if db.deliveries[i].WebhookID == webhookID {
+			result = append(result, db.deliveries[i])
+		}
+	}
+
+	return result, nil
+}
+
+// deleteDeliveries removes the deliveries matched by fn.
+func (db *MemoryDB) deleteDeliveries(fn func(model.WebhookDelivery) bool) {
+	deliveries := make([]model.WebhookDelivery, 0, len(db.deliveries))
+	for _, d := range db.deliveries {
+		if !fn(d) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	db.deliveries = deliveries
+}
+
+// copyWebhook returns a copy of w, so that callers can't modify the stored webhook.
+func copyWebhook(w model.Webhook) model.Webhook {
+	w.Events = copyStrings(w.Events)
+	return w
+}
@@ -0,0 +1,325 @@
+// Package migrate applies versioned SQL migrations and records the applied versions
+// in a schema_migrations table. Each migration runs in its own transaction.
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnknownVersion is returned when migrating to a version without migration files.
+	ErrUnknownVersion = errors.New("migrate: unknown migration version")
+	// ErrMissingDown is returned when an applied migration can't be reverted
+	// because its down migration file is missing.
+	ErrMissingDown = errors.New("migrate: missing down migration")
+
+	fileRegex = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+)
+
+// Migration is a schema change with its version, taken from the migration file names:
+// '<version>_<name>.up.sql' and '<version>_<name>.down.sql'.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	HasDown bool
+}
+
+// Status reports whether a migration has been applied.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Dialect holds the database specific statements used by the runner.
+type Dialect struct {
+	// CreateTable creates the schema_migrations table if it doesn't exist,
+	// with the version, name and applied_at columns.
+	CreateTable string
+	// Lock is run first in every transaction to serialize concurrent runners,
+	// such as several API replicas starting together. Optional.
+	Lock string
+	// Placeholder returns the n-th (1 based) bind parameter.
+	Placeholder func(n int) string
+}
+
+// Load reads the migrations from the directory, ordered by version.
+func Load(dir string) ([]Migration, error) {
+	if dir == "" {
+		return nil, errors.New("no migrations directory specified")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		match := fileRegex.FindStringSubmatch(file.Name())
+		if file.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version in %s: %v", file.Name(), err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d is used by both %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+			m.HasDown = true
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies every pending migration of the directory.
+func Up(db *sql.DB, d Dialect, dir string) error {
+	migrations, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return run(db, d, migrations, migrations[len(migrations)-1].Version)
+}
+
+// Down reverts every applied migration.
+func Down(db *sql.DB, d Dialect, dir string) error {
+	migrations, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	return run(db, d, migrations, 0)
+}
+
+// To applies or reverts migrations so that version is the last applied one.
+// Version 0 reverts every migration.
+func To(db *sql.DB, d Dialect, dir string, version int64) error {
+	migrations, err := Load(dir)
+	if err != nil {
+		return err
+	}
+
+	if version != 0 {
+		found := false
+		for _, m := range migrations {
+			if m.Version == version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrUnknownVersion
+		}
+	}
+
+	return run(db, d, migrations, version)
+}
+
+// GetStatus returns the status of every migration of the directory, along with
+// the applied migrations whose files are missing.
+func GetStatus(db *sql.DB, d Dialect, dir string) ([]Status, error) {
+	migrations, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := createTable(db, d); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		s := Status{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.AppliedAt
+			delete(applied, m.Version)
+		}
+		result = append(result, s)
+	}
+	for _, a := range applied {
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// run applies the pending migrations up to target and reverts the applied ones above it.
+func run(db *sql.DB, d Dialect, migrations []Migration, target int64) error {
+	if err := createTable(db, d); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	// Revert the applied migrations above target, newest first
+	var revert []int64
+	for v := range applied {
+		if v > target {
+			revert = append(revert, v)
+		}
+	}
+	sort.Slice(revert, func(i, j int) bool { return revert[i] > revert[j] })
+	for _, v := range revert {
+		m, ok := byVersion[v]
+		if !ok || !m.HasDown {
+			return fmt.Errorf("%v: version %d", ErrMissingDown, v)
+		}
+		if err := step(db, d, m, false); err != nil {
+			return err
+		}
+	}
+
+	// Apply the pending migrations up to target, oldest first
+	for _, m := range migrations {
+		if m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := step(db, d, m, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// step applies or reverts a single migration in a transaction. The applied state is
+// checked again after taking the lock, in case a concurrent runner already did it.
+func step(db *sql.DB, d Dialect, m Migration, up bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if d.Lock != "" {
+		if _, err := tx.Exec(d.Lock); err != nil {
+			return err
+		}
+	}
+
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = "+d.Placeholder(1), m.Version).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if (count > 0) == up {
+		return nil
+	}
+
+	query := m.Down
+	if up {
+		query = m.Up
+	}
+	if strings.TrimSpace(query) != "" {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("migrate: %d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+
+	if up {
+		_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES("+
+			d.Placeholder(1)+", "+d.Placeholder(2)+", "+d.Placeholder(3)+")", m.Version, m.Name, time.Now().UTC())
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = "+d.Placeholder(1), m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createTable creates the schema_migrations table, under the lock so that
+// concurrent runners don't race to create it.
+func createTable(db *sql.DB, d Dialect) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if d.Lock != "" {
+		if _, err := tx.Exec(d.Lock); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(d.CreateTable); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// appliedVersions returns the status of the applied migrations by version.
+func appliedVersions(db *sql.DB) (map[int64]Status, error) {
+	rows, err := db.Query("SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]Status)
+	for rows.Next() {
+		s := Status{Applied: true}
+		var appliedAt time.Time
+		if err := rows.Scan(&s.Version, &s.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		s.AppliedAt = &appliedAt
+		applied[s.Version] = s
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,177 @@
+package migrate
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var testDialect = Dialect{
+	CreateTable: "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMP NOT NULL)",
+	Placeholder: func(int) string { return "?" },
+}
+
+var testMigrations = map[string]string{
+	"1_CreateA.up.sql":   "CREATE TABLE a (id INTEGER);",
+	"1_CreateA.down.sql": "DROP TABLE a;",
+	"2_CreateB.up.sql":   "CREATE TABLE b (id INTEGER); INSERT INTO b VALUES (1);",
+	"2_CreateB.down.sql": "DROP TABLE b;",
+	"3_CreateC.up.sql":   "CREATE TABLE c (id INTEGER);",
+	"3_CreateC.down.sql": "DROP TABLE c;",
+	"README.md":          "not a migration",
+}
+
+func TestMigrate(t *testing.T) {
+	dir, db, cleanup := setup(t)
+	defer cleanup()
+
+	if err := Up(db, testDialect, dir); err != nil {
+		t.Fatal(err)
+	}
+	expectTables(t, db, "a", "b", "c")
+
+	// Applied migrations are not run again
+	if err := Up(db, testDialect, dir); err != nil {
+		t.Fatal(err)
+	}
+	var rows int
+	if err := db.QueryRow("SELECT COUNT(*) FROM b").Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("expected migration 2 to run once, got %d rows (err %v)", rows, err)
+	}
+
+	if err := To(db, testDialect, dir, 1); err != nil {
+		t.Fatal(err)
+	}
+	expectTables(t, db, "a")
+	expectApplied(t, db, dir, true, false, false)
+
+	if err := To(db, testDialect, dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	expectTables(t, db, "a", "b")
+	expectApplied(t, db, dir, true, true, false)
+
+	if err := To(db, testDialect, dir, 4); err != ErrUnknownVersion {
+		t.Errorf("expected error %v, got %v", ErrUnknownVersion, err)
+	}
+
+	if err := Down(db, testDialect, dir); err != nil {
+		t.Fatal(err)
+	}
+	expectTables(t, db)
+	expectApplied(t, db, dir, false, false, false)
+}
+
+func TestMigrateFailure(t *testing.T) {
+	dir, db, cleanup := setup(t)
+	defer cleanup()
+	write(t, dir, "4_Broken.up.sql", "CREATE TABLE d (id INTEGER); NOT SQL;")
+
+	if err := Up(db, testDialect, dir); err == nil {
+		t.Fatal("expected broken migration to fail")
+	}
+
+	// Migrations before the broken one stay applied, the broken one is rolled back
+	expectTables(t, db, "a", "b", "c")
+	status, err := GetStatus(db, testDialect, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 4 || status[3].Applied {
+		t.Errorf("expected migration 4 not to be applied, got %+v", status)
+	}
+}
+
+func TestMigrateMissingDown(t *testing.T) {
+	dir, db, cleanup := setup(t)
+	defer cleanup()
+	if err := Up(db, testDialect, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "3_CreateC.down.sql")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Down(db, testDialect, dir); err == nil {
+		t.Fatal("expected error reverting migration without down file")
+	}
+	expectTables(t, db, "a", "b", "c")
+}
+
+// setup creates a directory with the test migrations and an empty database.
+func setup(t *testing.T) (string, *sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range testMigrations {
+		write(t, dir, name, data)
+	}
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+
+	return dir, db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func write(t *testing.T, dir, name, data string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectTables(t *testing.T, db *sql.DB, expected ...string) {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name != 'schema_migrations' ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	if len(tables) != len(expected) {
+		t.Fatalf("expected tables %v, got %v", expected, tables)
+	}
+	for i := range tables {
+		if tables[i] != expected[i] {
+			t.Fatalf("expected tables %v, got %v", expected, tables)
+		}
+	}
+}
+
+func expectApplied(t *testing.T, db *sql.DB, dir string, applied ...bool) {
+	t.Helper()
+	status, err := GetStatus(db, testDialect, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != len(applied) {
+		t.Fatalf("expected %d migrations, got %d", len(applied), len(status))
+	}
+	for i, s := range status {
+		if s.Applied != applied[i] {
+			t.Errorf("expected migration %d applied to be %v", s.Version, applied[i])
+		}
+		if s.Applied && s.AppliedAt == nil {
+			t.Errorf("expected migration %d to have an applied time", s.Version)
+		}
+	}
+}
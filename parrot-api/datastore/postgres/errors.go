@@ -18,6 +18,9 @@ func parseError(err error) error {
 		switch e.Code {
 		case "23505":
 			return errors.ErrAlreadyExists
+		case "23503":
+			// The referenced entry, such as the project of a locale, doesn't exist
+			return errors.ErrNotFound
 		}
 	}
 
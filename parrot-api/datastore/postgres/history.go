@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *PostgresDB) AddHistoryEntries(entries []model.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addHistoryEntries(tx, entries); err != nil {
+		return parseError(err)
+	}
+
+	return parseError(tx.Commit())
+}
+
+// writeJournal stores the entries recorded in the journal, if any, and queues
+// the deliveries of its events.
+func writeJournal(tx *sql.Tx, j *model.Journal) error {
+	if j == nil {
+		return nil
+	}
+	if len(j.Entries) > 0 {
+		if err := addHistoryEntries(tx, j.Entries); err != nil {
+			return err
+		}
+	}
+	return enqueueEvents(tx, j.Events())
+}
+
+func addHistoryEntries(tx *sql.Tx, entries []model.HistoryEntry) error {
+	stmt, err := tx.Prepare(`INSERT INTO history (project_id, locale_ident, key, action, old_value, new_value, subject_id, subject_type)
+							VALUES($1, $2, $3, $4, $5, $6, $7, $8)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		_, err := stmt.Exec(e.ProjectID, e.LocaleIdent, e.Key, e.Action, e.OldValue, e.NewValue, e.SubjectID, e.SubjectType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetLocaleHistory(projID, localeIdent, key string) ([]model.HistoryEntry, error) {
+	rows, err := db.Query(`SELECT id, project_id, locale_ident, key, action, old_value, new_value, subject_id, subject_type, created_at
+							FROM history
+							WHERE project_id = $1 AND (locale_ident = $2 OR locale_ident = '')
+							AND ($3 = '' OR key = $3 OR (action = 'key_renamed' AND new_value = $3))
+							ORDER BY created_at DESC`, projID, localeIdent, key)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.HistoryEntry, 0)
+	for rows.Next() {
+		e, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		entries = append(entries, *e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return entries, nil
+}
+
+func (db *PostgresDB) GetHistoryEntry(projID, entryID string) (*model.HistoryEntry, error) {
+	row := db.QueryRow(`SELECT id, project_id, locale_ident, key, action, old_value, new_value, subject_id, subject_type, created_at
+							FROM history
+							WHERE project_id = $1 AND id = $2`, projID, entryID)
+	e, err := scanHistoryEntry(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return e, nil
+}
+
+// scanHistoryEntry scans a history entry from a single result row.
+func scanHistoryEntry(row scanner) (*model.HistoryEntry, error) {
+	e := model.HistoryEntry{}
+	err := row.Scan(&e.ID, &e.ProjectID, &e.LocaleIdent, &e.Key, &e.Action, &e.OldValue, &e.NewValue,
+		&e.SubjectID, &e.SubjectType, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/lib/pq"
+)
+
+// keyColumns lists the key columns in the order expected by scanKey.
+const keyColumns = "k.project_id, k.key, k.description, k.context, k.max_length, k.tags, k.created_at, k.updated_at, k.key = ANY(p.plural_keys)"
+
+func (db *PostgresDB) GetProjectKeys(projectID string) ([]model.Key, error) {
+	rows, err := db.Query(`SELECT `+keyColumns+` FROM project_keys k
+							JOIN projects p ON p.id = k.project_id
+							WHERE k.project_id = $1 AND k.key = ANY(p.keys)
+							ORDER BY k.key`, projectID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	keys := make([]model.Key, 0)
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		keys = append(keys, *k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return keys, nil
+}
+
+func (db *PostgresDB) GetProjectKey(projectID, key string) (*model.Key, error) {
+	row := db.QueryRow(`SELECT `+keyColumns+` FROM project_keys k
+						JOIN projects p ON p.id = k.project_id
+						WHERE k.project_id = $1 AND k.key = $2 AND k.key = ANY(p.keys)`, projectID, key)
+	k, err := scanKey(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return k, nil
+}
+
+func (db *PostgresDB) UpdateProjectKeyMeta(key model.Key) (*model.Key, error) {
+	result, err := updateKeyMeta(db, key)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// updateKeyMeta stores the description, context, max length and tags of the key's record.
+func updateKeyMeta(db rowQuerier, key model.Key) (*model.Key, error) {
+	tags, err := pq.StringArray(key.Tags).Value()
+	if err != nil {
+		return nil, err
+	}
+
+	row := db.QueryRow(`WITH k AS (
+							UPDATE project_keys SET description = $1, context = $2, max_length = $3, tags = $4, updated_at = now()
+							WHERE project_id = $5 AND key = $6 RETURNING *)
+						SELECT `+keyColumns+` FROM k JOIN projects p ON p.id = k.project_id`,
+		key.Description, key.Context, key.MaxLength, tags, key.ProjectID, key.Key)
+	return scanKey(row)
+}
+
+// syncKeyRecords creates the metadata records of new project keys and removes
+// the ones of keys that are no longer part of the project.
+func syncKeyRecords(db execer, projectID string, keys []string) error {
+	values, err := pq.StringArray(keys).Value()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`INSERT INTO project_keys (project_id, key) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`, projectID, values)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM project_keys WHERE project_id = $1 AND NOT (key = ANY($2::text[]))`, projectID, values)
+	return err
+}
+
+// scanKey scans a key from a single result row selected with keyColumns.
+func scanKey(row scanner) (*model.Key, error) {
+	k := model.Key{}
+	tags := pq.StringArray{}
+
+	err := row.Scan(&k.ProjectID, &k.Key, &k.Description, &k.Context, &k.MaxLength, &tags, &k.CreatedAt, &k.UpdatedAt, &k.Plural)
+	if err != nil {
+		return nil, err
+	}
+
+	k.Tags = make([]string, len(tags))
+	for i, v := range tags {
+		k.Tags[i] = v
+	}
+
+	return &k, nil
+}
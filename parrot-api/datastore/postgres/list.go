@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// keyset returns the condition selecting the rows after the cursor, TRUE for the
+// first page, and the ORDER BY and LIMIT clauses of the page. Ties on the sort
+// column are ordered by the id column, both are compared as text. The returned
+// arguments are args followed by the ones of the clauses.
+func keyset(opts model.ListOptions, column, idColumn string, args []interface{}) (string, string, []interface{}) {
+	dir, op := "ASC", ">"
+	if opts.Desc {
+		dir, op = "DESC", "<"
+	}
+	column += "::text"
+	idColumn += "::text"
+
+	cond := "TRUE"
+	if opts.After != nil {
+		args = append(args, opts.After.Value, opts.After.ID)
+		cond = fmt.Sprintf("(%s, %s) %s ($%d, $%d)", column, idColumn, op, len(args)-1, len(args))
+	}
+
+	clauses := fmt.Sprintf("ORDER BY %s %s, %s %s", column, dir, idColumn, dir)
+	if opts.Limit > 0 {
+		// One more row tells whether there is a next page
+		args = append(args, opts.Limit+1)
+		clauses += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return cond, clauses, args
+}
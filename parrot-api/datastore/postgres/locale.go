@@ -2,57 +2,422 @@ package postgres
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
 	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/lib/pq"
 	"github.com/lib/pq/hstore"
 )
 
-func (db *PostgresDB) CreateLocale(loc model.Locale) (*model.Locale, error) {
-	h := hstore.Hstore{}
-	h.Map = make(map[string]sql.NullString)
-	for k, v := range loc.Pairs {
-		h.Map[k] = sql.NullString{String: v, Valid: true}
+// localeColumns lists the locale columns in the order expected by scanLocale.
+const localeColumns = "id, ident, language, country, pairs, plurals, statuses, project_id, version"
+
+// localeSummaryColumns selects the same columns as localeColumns, without loading
+// the pairs, plural forms and statuses.
+const localeSummaryColumns = "id, ident, language, country, NULL, NULL, NULL, project_id, version"
+
+func (db *PostgresDB) CreateLocale(loc model.Locale, j *model.Journal) (*model.Locale, error) {
+	values, err := pairsValue(loc.Pairs)
+	if err != nil {
+		return nil, parseError(err)
 	}
-	values, err := h.Value()
+	plurals, err := pluralsValue(loc.Plurals)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	tx, err := db.Begin()
 	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("INSERT INTO locales (ident, language, country, pairs, plurals, project_id) VALUES($1, $2, $3, $4, $5, $6) RETURNING id, version",
+		loc.Ident, loc.Language, loc.Country, values, plurals, loc.ProjectID)
+	if err := row.Scan(&loc.ID, &loc.Version); err != nil {
+		return nil, parseError(err)
+	}
+
+	j.Emit(loc.ProjectID, model.EventLocaleCreated, map[string]interface{}{"locale": loc.Ident})
+	if err := writeJournal(tx, j); err != nil {
 		return nil, parseError(err)
 	}
 
-	row := db.QueryRow("INSERT INTO locales (ident, language, country, pairs, project_id) VALUES($1, $2, $3, $4, $5) RETURNING id",
-		loc.Ident, loc.Language, loc.Country, values, loc.ProjectID)
-	err = row.Scan(&loc.ID)
-	return &loc, parseError(err)
+	return &loc, parseError(tx.Commit())
 }
 
-func (db *PostgresDB) UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string) (*model.Locale, error) {
-	h := hstore.Hstore{}
-	h.Map = make(map[string]sql.NullString)
-	for k, v := range pairs {
-		h.Map[k] = sql.NullString{String: v, Valid: true}
+func (db *PostgresDB) UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPairs(pairs)
+	})
+}
+
+// MergeLocalePairs concatenates the pairs to the stored ones in a single statement,
+// so that concurrent merges of other keys aren't lost. The values they replace are
+// only read for the journal. A non-zero version must match the one of the stored locale.
+func (db *PostgresDB) MergeLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	values, err := pairsValue(pairs)
+	if err != nil {
+		return nil, parseError(err)
 	}
 
-	values, err := h.Value()
+	tx, err := db.Begin()
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	row := db.QueryRow("UPDATE locales SET pairs = $1 WHERE project_id = $2 AND ident = $3 RETURNING *", values, projID, localeIdent)
-	loc := model.Locale{}
-	err = row.Scan(&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &h, &loc.ProjectID)
+	// Values that change lose their review status, as with Locale.PutPairs
+	old := hstore.Hstore{}
+	row := tx.QueryRow(`WITH previous (locale_id, old_pairs) AS (
+			SELECT id, slice(pairs, akeys($1::hstore)) FROM locales WHERE project_id = $2 AND ident = $3)
+		UPDATE locales SET pairs = pairs || $1::hstore,
+			statuses = statuses - ARRAY(SELECT n.key FROM each($1::hstore) AS n WHERE pairs -> n.key IS DISTINCT FROM n.value),
+			version = version + 1
+		FROM previous WHERE id = previous.locale_id AND ($4 = 0 OR version = $4)
+		RETURNING `+localeColumns+`, old_pairs`,
+		values, projID, localeIdent, version)
+	loc, err := scanLocale(row, &old)
+	if err == sql.ErrNoRows && version != 0 {
+		return nil, db.staleOrMissing(projID, localeIdent)
+	}
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	before := loc.Copy()
+	for k := range pairs {
+		if v, ok := old.Map[k]; ok && v.Valid {
+			before.Pairs[k] = v.String
+		} else {
+			delete(before.Pairs, k)
+		}
+	}
+	j.RecordLocale(before, loc)
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return loc, nil
+}
+
+// staleOrMissing returns the error of an update that matched no locale: the locale
+// is either missing or at another version than the expected one.
+func (db *PostgresDB) staleOrMissing(projID, ident string) error {
+	var id string
+	err := db.QueryRow("SELECT id FROM locales WHERE project_id = $1 AND ident = $2", projID, ident).Scan(&id)
+	if err != nil {
+		return parseError(err)
+	}
+	return errors.ErrStaleVersion
+}
+
+func (db *PostgresDB) UpdateLocalePlurals(projID string, localeIdent string, plurals map[string]model.PluralForms, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPlurals(plurals)
+	})
+}
+
+// updateLocale locks the stored locale in a transaction and applies fn to it, then
+// saves it along with the history recorded in the journal.
+// A non-zero version must match the one of the stored locale.
+func (db *PostgresDB) updateLocale(projID, ident string, version int, j *model.Journal, fn func(*model.Locale)) (*model.Locale, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("SELECT "+localeColumns+" FROM locales WHERE project_id = $1 AND ident = $2 FOR UPDATE", projID, ident)
+	loc, err := scanLocale(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if version != 0 && loc.Version != version {
+		return nil, errors.ErrStaleVersion
+	}
+
+	before := loc.Copy()
+	fn(loc)
+	if err := saveLocale(tx, loc); err != nil {
+		return nil, parseError(err)
+	}
+	j.RecordLocale(before, loc)
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return loc, nil
+}
+
+func (db *PostgresDB) UpdateLocaleStatuses(projID string, localeIdent string, statuses map[string]string) (*model.Locale, error) {
+	// Translated is the default status of values, so it is not stored
+	review := make(map[string]string)
+	cleared := make(pq.StringArray, 0)
+	for k, v := range statuses {
+		if v == model.StatusTranslated {
+			cleared = append(cleared, k)
+			continue
+		}
+		review[k] = v
+	}
+
+	values, err := pairsValue(review)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := cleared.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	row := db.QueryRow("UPDATE locales SET statuses = (statuses || $1::hstore) - $2::text[], version = version + 1 WHERE project_id = $3 AND ident = $4 RETURNING "+localeColumns,
+		values, keys, projID, localeIdent)
+	loc, err := scanLocale(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return loc, nil
+}
+
+func (db *PostgresDB) DeleteLocale(projID string, ident string, j *model.Journal) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("DELETE FROM locales WHERE project_id = $1 AND ident = $2", projID, ident)
+	if err != nil {
+		return parseError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return parseError(err)
+	}
+
+	j.Emit(projID, model.EventLocaleDeleted, map[string]interface{}{"locale": ident})
+	if err := writeJournal(tx, j); err != nil {
+		return parseError(err)
+	}
+
+	return parseError(tx.Commit())
+}
+
+func (db *PostgresDB) ImportLocale(projID, localeIdent string, keys, pluralKeys []string, fn func(*model.Project, *model.Locale) error, j *model.Journal) (*model.Locale, []string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the project, so that the recorded key changes are the ones applied
+	previous := pq.StringArray{}
+	err = tx.QueryRow("SELECT keys FROM projects WHERE id = $1 FOR UPDATE", projID).Scan(&previous)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	// Only the missing keys are appended, like with AddProjectKey, and only once
+	unique := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			unique = append(unique, k)
+		}
+	}
+	row := tx.QueryRow(`UPDATE projects SET
+							keys = keys || coalesce((SELECT array_agg(k ORDER BY i) FROM unnest($1::text[]) WITH ORDINALITY AS t(k, i)
+								WHERE k <> '' AND NOT k = ANY(keys)), '{}'),
+							plural_keys = plural_keys || coalesce((SELECT array_agg(k ORDER BY i) FROM unnest($2::text[]) WITH ORDINALITY AS t(k, i)
+								WHERE k <> '' AND NOT k = ANY(keys) AND k = ANY($1::text[])), '{}')
+						WHERE id = $3 RETURNING `+projectColumns,
+		pq.Array(unique), pq.Array(pluralKeys), projID)
+	project, err := scanProject(row)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	if err := syncKeyRecords(tx, projID, project.Keys); err != nil {
+		return nil, nil, parseError(err)
+	}
+	changes := model.KeyChanges(projID, previous, project.Keys)
+	j.Record(changes...)
+	var added []string
+	for _, e := range changes {
+		added = append(added, e.Key)
+	}
+
+	row = tx.QueryRow("SELECT "+localeColumns+" FROM locales WHERE project_id = $1 AND ident = $2 FOR UPDATE", projID, localeIdent)
+	loc, err := scanLocale(row)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	before := loc.Copy()
+	if err := fn(project, loc); err != nil {
+		return nil, nil, err
+	}
+	if err := saveLocale(tx, loc); err != nil {
+		return nil, nil, parseError(err)
+	}
+	j.RecordLocale(before, loc)
+	if err := writeJournal(tx, j); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	return loc, added, nil
+}
+
+func (db *PostgresDB) UpdateLocales(projID string, localeIdents []string, fn func(*model.Locale) error, j *model.Journal) ([]model.Locale, error) {
+	tx, err := db.Begin()
 	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the locales, so that no change is lost
+	rows, err := tx.Query("SELECT "+localeColumns+" FROM locales WHERE project_id = $1 AND ident = ANY($2) ORDER BY ident FOR UPDATE",
+		projID, pq.Array(localeIdents))
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	locs := make([]model.Locale, 0)
+	for rows.Next() {
+		loc, err := scanLocale(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		locs = append(locs, *loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	for _, ident := range localeIdents {
+		found := false
+		for _, loc := range locs {
+			found = found || loc.Ident == ident
+		}
+		if !found {
+			return nil, errors.ErrNotFound
+		}
+	}
+
+	for i := range locs {
+		before := locs[i].Copy()
+		if err := fn(&locs[i]); err != nil {
+			return nil, err
+		}
+		if err := saveLocale(tx, &locs[i]); err != nil {
+			return nil, parseError(err)
+		}
+		j.RecordLocale(before, &locs[i])
+	}
+
+	if err := writeJournal(tx, j); err != nil {
 		return nil, parseError(err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return locs, nil
+}
+
+// saveLocale stores the pairs, plural forms and statuses of the locale and
+// increments its version.
+func saveLocale(tx *sql.Tx, loc *model.Locale) error {
+	pairs, err := pairsValue(loc.Pairs)
+	if err != nil {
+		return err
+	}
+	plurals, err := pluralsValue(loc.Plurals)
+	if err != nil {
+		return err
+	}
+	statuses, err := pairsValue(loc.Statuses)
+	if err != nil {
+		return err
+	}
+
+	return tx.QueryRow("UPDATE locales SET pairs = $1, plurals = $2, statuses = $3, version = version + 1 WHERE id = $4 RETURNING version",
+		pairs, plurals, statuses, loc.ID).Scan(&loc.Version)
+}
+
+// scanLocale scans a locale from a single result row selected with localeColumns,
+// followed by the extra columns, if any.
+func scanLocale(row scanner, extra ...interface{}) (*model.Locale, error) {
+	loc := model.Locale{}
+	pairs := hstore.Hstore{}
+	statuses := hstore.Hstore{}
+	var plurals []byte
+
+	dest := []interface{}{&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &pairs, &plurals, &statuses, &loc.ProjectID, &loc.Version}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
 	loc.Pairs = make(map[string]string)
-	for k, v := range h.Map {
+	for k, v := range pairs.Map {
 		if v.Valid {
 			loc.Pairs[k] = v.String
 		}
 	}
+
+	loc.Statuses = make(map[string]string)
+	for k, v := range statuses.Map {
+		if v.Valid {
+			loc.Statuses[k] = v.String
+		}
+	}
+
+	loc.Plurals = make(map[string]model.PluralForms)
+	if len(plurals) > 0 {
+		if err := json.Unmarshal(plurals, &loc.Plurals); err != nil {
+			return nil, err
+		}
+	}
+
 	return &loc, nil
 }
 
-func (db *PostgresDB) DeleteLocale(projID string, ident string) error {
-	_, err := db.Exec("DELETE FROM locales WHERE project_id = $1 AND ident = $2", projID, ident)
-	return parseError(err)
+// pairsValue encodes locale pairs as an hstore value.
+func pairsValue(pairs map[string]string) (driver.Value, error) {
+	h := hstore.Hstore{}
+	h.Map = make(map[string]sql.NullString)
+	for k, v := range pairs {
+		h.Map[k] = sql.NullString{String: v, Valid: true}
+	}
+	return h.Value()
+}
+
+// pluralsValue encodes locale plural forms as a jsonb value.
+func pluralsValue(plurals map[string]model.PluralForms) (driver.Value, error) {
+	if plurals == nil {
+		plurals = make(map[string]model.PluralForms)
+	}
+	b, err := json.Marshal(plurals)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
 }
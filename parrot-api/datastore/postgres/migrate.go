@@ -1,62 +1,36 @@
 package postgres
 
 import (
-	"bytes"
-	"errors"
-	"io"
-	"io/ioutil"
-	"os"
-	"path"
-	"regexp"
-)
+	"strconv"
 
-var (
-	upRegex   = regexp.MustCompile(`^([0-9]+)_(.*).up.sql$`)
-	downRegex = regexp.MustCompile(`^([0-9]+)_(.*).down.sql$`)
+	"github.com/iris-contrib/parrot/parrot-api/datastore/migrate"
 )
 
-func (ds *PostgresDB) MigrateUp(migrationsDir string) error {
-	return ds.migrate(migrationsDir, upRegex)
+// migrationLockID is the advisory lock key held while migrating, so that several
+// API replicas starting together apply each migration once.
+const migrationLockID = 7274681
+
+var dialect = migrate.Dialect{
+	CreateTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+					version BIGINT PRIMARY KEY,
+					name TEXT NOT NULL,
+					applied_at TIMESTAMP WITH TIME ZONE NOT NULL)`,
+	Lock:        "SELECT pg_advisory_xact_lock(" + strconv.Itoa(migrationLockID) + ")",
+	Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+}
+
+func (db *PostgresDB) MigrateUp(migrationsDir string) error {
+	return migrate.Up(db.DB, dialect, migrationsDir)
+}
 
+func (db *PostgresDB) MigrateDown(migrationsDir string) error {
+	return migrate.Down(db.DB, dialect, migrationsDir)
 }
 
-func (ds *PostgresDB) MigrateDown(migrationsDir string) error {
-	return ds.migrate(migrationsDir, downRegex)
+func (db *PostgresDB) MigrateTo(migrationsDir string, version int64) error {
+	return migrate.To(db.DB, dialect, migrationsDir, version)
 }
 
-func (ds *PostgresDB) migrate(migrationsDir string, fileMatcher *regexp.Regexp) error {
-	if migrationsDir == "" {
-		return errors.New("no migrations directory specified")
-	}
-
-	files, err := ioutil.ReadDir(migrationsDir)
-	if err != nil {
-		return err
-	}
-
-	buf := bytes.NewBuffer(nil)
-
-	for _, file := range files {
-		fileName := file.Name()
-		if file.IsDir() || !fileMatcher.MatchString(fileName) {
-			continue
-		}
-		data, err := os.Open(path.Join(migrationsDir, fileName))
-		_, err = io.Copy(buf, data)
-		if err != nil {
-			return err
-		}
-		err = data.Close()
-		if err != nil {
-			return err
-		}
-		buf.WriteString("\n")
-	}
-
-	_, err = ds.Exec(buf.String())
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (db *PostgresDB) MigrationStatus(migrationsDir string) ([]migrate.Status, error) {
+	return migrate.GetStatus(db.DB, dialect, migrationsDir)
 }
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID REFERENCES projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    locale_ident TEXT NOT NULL DEFAULT '',
+    key TEXT NOT NULL,
+    action TEXT NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    subject_id TEXT NOT NULL,
+    subject_type TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS history_project_locale_key_idx ON history (project_id, locale_ident, key);
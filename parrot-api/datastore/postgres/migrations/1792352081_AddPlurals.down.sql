@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS projects DROP COLUMN IF EXISTS plural_keys;
+
+ALTER TABLE IF EXISTS locales DROP COLUMN IF EXISTS plurals;
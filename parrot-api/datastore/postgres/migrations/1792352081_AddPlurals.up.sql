@@ -0,0 +1,3 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS plural_keys text[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE locales ADD COLUMN IF NOT EXISTS plurals jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS project_keys;
\ No newline at end of file
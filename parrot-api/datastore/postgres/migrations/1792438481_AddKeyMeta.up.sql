@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS project_keys (
+    project_id UUID REFERENCES projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    context TEXT NOT NULL DEFAULT '',
+    max_length INTEGER NOT NULL DEFAULT 0,
+    tags text[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, key)
+);
+
+INSERT INTO project_keys (project_id, key)
+    SELECT id, unnest(keys) FROM projects
+    ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS locales DROP COLUMN IF EXISTS statuses;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE locales ADD COLUMN IF NOT EXISTS statuses hstore NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS fallbacks jsonb NOT NULL DEFAULT '{}';
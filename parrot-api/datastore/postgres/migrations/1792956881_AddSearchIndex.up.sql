@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS locales_pairs_trgm_idx ON locales USING gin ((pairs::text) gin_trgm_ops);
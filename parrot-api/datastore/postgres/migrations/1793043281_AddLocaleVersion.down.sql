@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS locales DROP COLUMN IF EXISTS version;
\ No newline at end of file
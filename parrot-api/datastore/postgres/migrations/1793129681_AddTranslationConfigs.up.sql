@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS translation_configs (
+    project_id UUID PRIMARY KEY REFERENCES projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    api_key TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS source_locale text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS key_delimiter text NOT NULL DEFAULT '.';
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS project_clients DROP COLUMN IF EXISTS can_add_keys;
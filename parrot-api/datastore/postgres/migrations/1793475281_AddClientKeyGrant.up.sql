@@ -0,0 +1 @@
+ALTER TABLE project_clients ADD COLUMN IF NOT EXISTS can_add_keys boolean NOT NULL DEFAULT false;
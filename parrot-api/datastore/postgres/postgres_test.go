@@ -0,0 +1,32 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/postgres"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/storetest"
+)
+
+// TestStore runs the conformance suite against the database set in PARROT_TEST_DB_URL.
+// Migrations are applied first, existing data is left untouched.
+func TestStore(t *testing.T) {
+	url := os.Getenv("PARROT_TEST_DB_URL")
+	if url == "" {
+		t.Skip("PARROT_TEST_DB_URL not set")
+	}
+
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &postgres.PostgresDB{DB: conn}
+	defer db.Close()
+
+	if err := db.MigrateUp("migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, db)
+}
@@ -2,29 +2,47 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
 	"github.com/iris-contrib/parrot/parrot-api/model"
 	"github.com/lib/pq"
-	"github.com/lib/pq/hstore"
 )
 
-func (db *PostgresDB) GetProject(id string) (*model.Project, error) {
-	p := model.Project{}
-	row := db.QueryRow("SELECT * FROM projects WHERE id = $1", id)
+// projectColumns lists the project columns in the order expected by scanProject.
+const projectColumns = "id, name, keys, plural_keys, fallbacks, source_locale, key_delimiter"
 
-	keys := pq.StringArray{}
-	err := row.Scan(&p.ID, &p.Name, &keys)
+func (db *PostgresDB) GetProjects() ([]model.Project, error) {
+	rows, err := db.Query("SELECT " + projectColumns + " FROM projects ORDER BY name, id")
 	if err != nil {
 		return nil, parseError(err)
 	}
+	defer rows.Close()
 
-	p.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		p.Keys[i] = v
+	projects := make([]model.Project, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		projects = append(projects, *p)
 	}
 
-	return &p, nil
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return projects, nil
+}
+
+func (db *PostgresDB) GetProject(id string) (*model.Project, error) {
+	row := db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1", id)
+	p, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return p, nil
 }
 
 func (db *PostgresDB) CreateProject(project model.Project) (*model.Project, error) {
@@ -38,68 +56,84 @@ func (db *PostgresDB) CreateProject(project model.Project) (*model.Project, erro
 		return nil, parseError(err)
 	}
 
-	row := db.QueryRow("INSERT INTO projects (name, keys) VALUES($1, $2) RETURNING *", project.Name, values)
-	result := model.Project{}
-	keys = pq.StringArray{}
-	err = row.Scan(&result.ID, &result.Name, &keys)
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("INSERT INTO projects (name, keys) VALUES($1, $2) RETURNING "+projectColumns, project.Name, values)
+	result, err := scanProject(row)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	result.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		result.Keys[i] = v
+	if err := syncKeyRecords(tx, result.ID, result.Keys); err != nil {
+		return nil, parseError(err)
 	}
 
-	return &result, nil
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
 }
 
 func (db *PostgresDB) UpdateProjectName(projectID, name string) (*model.Project, error) {
-	row := db.QueryRow("UPDATE projects SET name = $1 WHERE id = $2 RETURNING id, name, keys", name, projectID)
-	keys := pq.StringArray{}
-	result := model.Project{}
-	err := row.Scan(&result.ID, &result.Name, &keys)
+	row := db.QueryRow("UPDATE projects SET name = $1 WHERE id = $2 RETURNING "+projectColumns, name, projectID)
+	result, err := scanProject(row)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	result.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		result.Keys[i] = v
-	}
-
-	return &result, nil
+	return result, nil
 }
 
-func (db *PostgresDB) AddProjectKey(projectID, key string) (*model.Project, error) {
-	// TODO optimize this
-	project, err := db.GetProject(projectID)
+func (db *PostgresDB) AddProjectKey(key model.Key, j *model.Journal) (*model.Project, error) {
+	tx, err := db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	for _, v := range project.Keys {
-		if v == key {
-			return nil, parseError(errors.ErrAlreadyExists)
+	defer tx.Rollback()
+
+	row := tx.QueryRow(`UPDATE projects SET keys = keys || array[$1],
+							plural_keys = CASE WHEN $3 THEN plural_keys || array[$1] ELSE plural_keys END
+						WHERE id = $2 AND NOT(keys @> array[$1]) RETURNING `+projectColumns, key.Key, key.ProjectID, key.Plural)
+	result, err := scanProject(row)
+	if err == sql.ErrNoRows {
+		// Either the project is missing or it has the key already
+		if _, err := db.GetProject(key.ProjectID); err != nil {
+			return nil, err
 		}
+		return nil, errors.ErrAlreadyExists
 	}
-
-	row := db.QueryRow("UPDATE projects SET keys = keys || array[$1] WHERE id = $2 AND NOT(keys @> array[$1]) RETURNING *", key, projectID)
-	keys := pq.StringArray{}
-	result := model.Project{}
-	err = row.Scan(&result.ID, &result.Name, &keys)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	result.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		result.Keys[i] = v
+	if err := syncKeyRecords(tx, key.ProjectID, result.Keys); err != nil {
+		return nil, parseError(err)
+	}
+	if _, err := updateKeyMeta(tx, key); err != nil {
+		return nil, parseError(err)
 	}
 
-	return &result, nil
+	j.Record(model.KeyChanges(key.ProjectID, nil, []string{key.Key})...)
+	if key.Plural {
+		j.Record(model.KeyPluralUpdated(key.ProjectID, key.Key, true))
+	}
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
 }
 
-func (db *PostgresDB) UpdateProjectKey(projectID, oldKey, newKey string) (*model.Project, int, error) {
+func (db *PostgresDB) UpdateProjectKey(projectID, oldKey, newKey string, j *model.Journal) (*model.Project, int, error) {
 	// TODO: optimize this
 	// Before transaction begin, check if project key is present and newKey is not present
 	project, err := db.GetProject(projectID)
@@ -130,21 +164,21 @@ func (db *PostgresDB) UpdateProjectKey(projectID, oldKey, newKey string) (*model
 	defer tx.Rollback()
 
 	// Step 1, get project keys and update
-	row := tx.QueryRow("UPDATE projects SET keys = array_replace(keys, $1, $2) WHERE id = $3 RETURNING *", oldKey, newKey, projectID)
-	keys := pq.StringArray{}
-	result := model.Project{}
-	err = row.Scan(&result.ID, &result.Name, &keys)
+	row := tx.QueryRow(`UPDATE projects SET keys = array_replace(keys, $1, $2), plural_keys = array_replace(plural_keys, $1, $2)
+						WHERE id = $3 RETURNING `+projectColumns, oldKey, newKey, projectID)
+	result, err := scanProject(row)
 	if err != nil {
 		return nil, -1, parseError(err)
 	}
 
-	result.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		result.Keys[i] = v
+	// Step 2, move the key's metadata to the new key
+	_, err = tx.Exec("UPDATE project_keys SET key = $1, updated_at = now() WHERE project_id = $2 AND key = $3", newKey, projectID, oldKey)
+	if err != nil {
+		return nil, -1, parseError(err)
 	}
 
-	// Step 2, find all project locales and update pairs
-	rows, err := tx.Query("SELECT * FROM locales WHERE project_id = $1", projectID)
+	// Step 3, find all project locales and update pairs
+	rows, err := tx.Query("SELECT "+localeColumns+" FROM locales WHERE project_id = $1", projectID)
 	if err != nil {
 		return nil, -1, parseError(err)
 	}
@@ -152,22 +186,12 @@ func (db *PostgresDB) UpdateProjectKey(projectID, oldKey, newKey string) (*model
 
 	locales := make([]model.Locale, 0)
 	for rows.Next() {
-		loc := model.Locale{}
-		pairs := hstore.Hstore{}
-
-		err := rows.Scan(&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &pairs, &loc.ProjectID)
+		loc, err := scanLocale(rows)
 		if err != nil {
 			return nil, -1, parseError(err)
 		}
 
-		loc.Pairs = make(map[string]string)
-		for k, v := range pairs.Map {
-			if v.Valid {
-				loc.Pairs[k] = v.String
-			}
-		}
-
-		locales = append(locales, loc)
+		locales = append(locales, *loc)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -175,34 +199,58 @@ func (db *PostgresDB) UpdateProjectKey(projectID, oldKey, newKey string) (*model
 	}
 
 	for _, locale := range locales {
-		h := hstore.Hstore{}
-		h.Map = make(map[string]sql.NullString)
+		pairs := make(map[string]string)
 		for k, v := range locale.Pairs {
 			// replace old key with new one when found, keep value
 			if k == oldKey {
 				k = newKey
 			}
-			h.Map[k] = sql.NullString{String: v, Valid: true}
+			pairs[k] = v
+		}
+		if forms, ok := locale.Plurals[oldKey]; ok {
+			delete(locale.Plurals, oldKey)
+			locale.Plurals[newKey] = forms
+		}
+		if status, ok := locale.Statuses[oldKey]; ok {
+			delete(locale.Statuses, oldKey)
+			locale.Statuses[newKey] = status
+		}
+
+		values, err := pairsValue(pairs)
+		if err != nil {
+			return nil, -1, err
+		}
+		plurals, err := pluralsValue(locale.Plurals)
+		if err != nil {
+			return nil, -1, err
 		}
 
-		values, err := h.Value()
+		statuses, err := pairsValue(locale.Statuses)
 		if err != nil {
 			return nil, -1, err
 		}
 
-		_, err = tx.Exec("UPDATE locales SET pairs = $1 WHERE project_id = $2 AND id = $3", values, projectID, locale.ID)
+		_, err = tx.Exec("UPDATE locales SET pairs = $1, plurals = $2, statuses = $3, version = version + 1 WHERE project_id = $4 AND id = $5",
+			values, plurals, statuses, projectID, locale.ID)
 		if err != nil {
 			return nil, -1, parseError(err)
 		}
 
 	}
 
-	tx.Commit()
+	j.Record(model.KeyRenamed(projectID, oldKey, newKey))
+	if err := writeJournal(tx, j); err != nil {
+		return nil, -1, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, -1, parseError(err)
+	}
 
-	return &result, len(locales), nil
+	return result, len(locales), nil
 }
 
-func (db *PostgresDB) DeleteProjectKey(projectID, key string) (*model.Project, error) {
+func (db *PostgresDB) DeleteProjectKey(projectID, key string, j *model.Journal) (*model.Project, error) {
 	project, err := db.GetProject(projectID)
 	if err != nil {
 		return nil, err
@@ -227,44 +275,166 @@ func (db *PostgresDB) DeleteProjectKey(projectID, key string) (*model.Project, e
 		return nil, parseError(err)
 	}
 
-	row := db.QueryRow("UPDATE projects SET keys = $1 WHERE id = $2 RETURNING *", values, projectID)
-	err = row.Scan(&project.ID, &project.Name, &keys)
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("UPDATE projects SET keys = $1, plural_keys = array_remove(plural_keys, $2) WHERE id = $3 RETURNING "+projectColumns,
+		values, key, projectID)
+	result, err := scanProject(row)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	project.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		project.Keys[i] = v
+	if err := syncKeyRecords(tx, projectID, result.Keys); err != nil {
+		return nil, parseError(err)
+	}
+
+	j.Record(model.KeyChanges(projectID, []string{key}, nil)...)
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) SetProjectKeyPlural(projectID, key string, plural bool, j *model.Journal) (*model.Project, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var found, wasPlural bool
+	err = tx.QueryRow("SELECT $1 = ANY(keys), $1 = ANY(plural_keys) FROM projects WHERE id = $2 FOR UPDATE", key, projectID).Scan(&found, &wasPlural)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if !found {
+		return nil, errors.ErrNotFound
+	}
+
+	query := "UPDATE projects SET plural_keys = array_remove(plural_keys, $1) WHERE id = $2 RETURNING " + projectColumns
+	if plural {
+		query = "UPDATE projects SET plural_keys = array_remove(plural_keys, $1) || array[$1] WHERE id = $2 RETURNING " + projectColumns
+	}
+
+	row := tx.QueryRow(query, key, projectID)
+	result, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	if wasPlural != plural {
+		j.Record(model.KeyPluralUpdated(projectID, key, plural))
+	}
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) UpdateProjectFallbacks(projectID string, fallbacks map[string][]string) (*model.Project, error) {
+	if fallbacks == nil {
+		fallbacks = make(map[string][]string)
+	}
+	values, err := json.Marshal(fallbacks)
+	if err != nil {
+		return nil, err
 	}
 
-	return project, nil
+	row := db.QueryRow("UPDATE projects SET fallbacks = $1 WHERE id = $2 RETURNING "+projectColumns, string(values), projectID)
+	result, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
 }
 
-func (db *PostgresDB) UpdateProject(project model.Project) (*model.Project, error) {
+func (db *PostgresDB) UpdateProjectSourceLocale(projectID, ident string) (*model.Project, error) {
+	row := db.QueryRow("UPDATE projects SET source_locale = $1 WHERE id = $2 RETURNING "+projectColumns, ident, projectID)
+	result, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) UpdateProjectKeyDelimiter(projectID, delimiter string) (*model.Project, error) {
+	row := db.QueryRow("UPDATE projects SET key_delimiter = $1 WHERE id = $2 RETURNING "+projectColumns, delimiter, projectID)
+	result, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) UpdateProject(project model.Project, j *model.Journal) (*model.Project, error) {
 	keys := make(pq.StringArray, len(project.Keys))
 	for i, v := range project.Keys {
 		keys[i] = v
 	}
+	pluralKeys := make(pq.StringArray, len(project.PluralKeys))
+	for i, v := range project.PluralKeys {
+		pluralKeys[i] = v
+	}
 
 	values, err := keys.Value()
 	if err != nil {
 		return nil, parseError(err)
 	}
+	pluralValues, err := pluralKeys.Value()
+	if err != nil {
+		return nil, parseError(err)
+	}
 
-	row := db.QueryRow("UPDATE projects SET keys = $1 WHERE id = $2 RETURNING *", values, project.ID)
-	keys = pq.StringArray{}
-	err = row.Scan(&project.ID, &project.Name, &keys)
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the project, so that the recorded key changes are the ones applied
+	previous := pq.StringArray{}
+	err = tx.QueryRow("SELECT keys FROM projects WHERE id = $1 FOR UPDATE", project.ID).Scan(&previous)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	project.Keys = make([]string, len(keys))
-	for i, v := range keys {
-		project.Keys[i] = v
+	row := tx.QueryRow("UPDATE projects SET keys = $1, plural_keys = $2 WHERE id = $3 RETURNING "+projectColumns, values, pluralValues, project.ID)
+	result, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := syncKeyRecords(tx, project.ID, result.Keys); err != nil {
+		return nil, parseError(err)
 	}
 
-	return &project, nil
+	j.Record(model.KeyChanges(project.ID, previous, result.Keys)...)
+	if err := writeJournal(tx, j); err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
 }
 
 func (db *PostgresDB) DeleteProject(id string) error {
@@ -276,75 +446,92 @@ func (db *PostgresDB) DeleteProject(id string) error {
 }
 
 func (db *PostgresDB) GetProjectLocaleByIdent(projectID string, ident string) (*model.Locale, error) {
-	loc := model.Locale{}
-	row := db.QueryRow("SELECT * FROM locales WHERE project_id = $1 AND ident = $2", projectID, ident)
-	pairs := hstore.Hstore{}
-	err := row.Scan(&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &pairs, &loc.ProjectID)
+	row := db.QueryRow("SELECT "+localeColumns+" FROM locales WHERE project_id = $1 AND ident = $2", projectID, ident)
+	loc, err := scanLocale(row)
 	if err != nil {
 		return nil, parseError(err)
 	}
 
-	loc.Pairs = make(map[string]string)
-	for k, v := range pairs.Map {
-		if v.Valid {
-			loc.Pairs[k] = v.String
-		}
-	}
-
-	return &loc, nil
+	return loc, nil
 }
 
 func (db *PostgresDB) GetProjectLocales(projID string, localeIdents ...string) ([]model.Locale, error) {
-	rows, err := db.Query("SELECT * FROM locales WHERE project_id = $1", projID)
+	locs, _, err := db.FindProjectLocales(projID, model.LocaleQuery{Idents: localeIdents})
+	return locs, err
+}
+
+func (db *PostgresDB) FindProjectLocales(projID string, q model.LocaleQuery) ([]model.Locale, *model.Cursor, error) {
+	columns := localeColumns
+	if q.WithoutPairs {
+		columns = localeSummaryColumns
+	}
+
+	where := "project_id = $1"
+	args := []interface{}{projID}
+	if len(q.Idents) > 0 {
+		where += " AND ident = ANY($2)"
+		args = append(args, pq.Array(q.Idents))
+	}
+
+	q.Sort = q.SortField(model.LocaleSortFields)
+	cond, clauses, args := keyset(q.ListOptions, q.Sort, "id", args)
+	rows, err := db.Query("SELECT "+columns+" FROM locales WHERE "+where+" AND "+cond+" "+clauses, args...)
 	if err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 	defer rows.Close()
 
 	locs := make([]model.Locale, 0)
 	for rows.Next() {
-		loc := model.Locale{}
-		pairs := hstore.Hstore{}
-
-		err := rows.Scan(&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &pairs, &loc.ProjectID)
+		loc, err := scanLocale(rows)
 		if err != nil {
-			return nil, parseError(err)
-		}
-
-		loc.Pairs = make(map[string]string)
-		for k, v := range pairs.Map {
-			if v.Valid {
-				loc.Pairs[k] = v.String
-			}
+			return nil, nil, parseError(err)
 		}
 
-		locs = append(locs, loc)
+		locs = append(locs, *loc)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 
-	if len(localeIdents) > 0 {
-		locs = filterLocales(locs, func(doc model.Locale) bool {
-			for _, locIdent := range localeIdents {
-				if doc.Ident == locIdent {
-					return true
-				}
-			}
-			return false
-		})
+	next := q.NextCursor(len(locs), func(i int) (string, string) {
+		return locs[i].SortValue(q.Sort), locs[i].ID
+	})
+	if next != nil {
+		locs = locs[:q.Limit]
 	}
 
-	return locs, nil
+	return locs, next, nil
 }
 
-func filterLocales(locs []model.Locale, fn func(model.Locale) bool) []model.Locale {
-	result := make([]model.Locale, 0)
-	for _, v := range locs {
-		if fn(v) {
-			result = append(result, v)
+// scanProject scans a project from a single result row selected with projectColumns.
+func scanProject(row scanner) (*model.Project, error) {
+	p := model.Project{}
+	keys := pq.StringArray{}
+	pluralKeys := pq.StringArray{}
+	var fallbacks []byte
+
+	err := row.Scan(&p.ID, &p.Name, &keys, &pluralKeys, &fallbacks, &p.SourceLocale, &p.KeyDelimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Fallbacks = make(map[string][]string)
+	if len(fallbacks) > 0 {
+		if err := json.Unmarshal(fallbacks, &p.Fallbacks); err != nil {
+			return nil, err
 		}
 	}
-	return result
+
+	p.Keys = make([]string, len(keys))
+	for i, v := range keys {
+		p.Keys[i] = v
+	}
+	p.PluralKeys = make([]string, len(pluralKeys))
+	for i, v := range pluralKeys {
+		p.PluralKeys[i] = v
+	}
+
+	return &p, nil
 }
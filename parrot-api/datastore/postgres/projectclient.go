@@ -2,29 +2,46 @@ package postgres
 
 import "github.com/iris-contrib/parrot/parrot-api/model"
 
-func (db *PostgresDB) GetProjectClients(projectID string) ([]model.ProjectClient, error) {
-	rows, err := db.Query("SELECT client_id, project_id, name, secret FROM project_clients WHERE project_id = $1", projectID)
+// clientSortColumns maps the project client sort fields to their columns.
+var clientSortColumns = map[string]string{"name": "name", "id": "client_id"}
+
+func (db *PostgresDB) GetProjectClients(projectID string, opts model.ListOptions) ([]model.ProjectClient, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectClientSortFields)
+	cond, clauses, args := keyset(opts, clientSortColumns[opts.Sort], "client_id", []interface{}{projectID})
+	rows, err := db.Query("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE project_id = $1 AND "+cond+" "+clauses, args...)
 	if err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 	defer rows.Close()
 
 	result := make([]model.ProjectClient, 0)
 	for rows.Next() {
 		r := model.ProjectClient{}
-		err = rows.Scan(&r.ClientID, &r.ProjectID, &r.Name, &r.Secret)
+		err = rows.Scan(&r.ClientID, &r.ProjectID, &r.Name, &r.Secret, &r.CanAddKeys)
 		if err != nil {
-			return nil, parseError(err)
+			return nil, nil, parseError(err)
 		}
 		result = append(result, r)
 	}
-	return result, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	next := opts.NextCursor(len(result), func(i int) (string, string) {
+		return result[i].SortValue(opts.Sort), result[i].ClientID
+	})
+	if next != nil {
+		result = result[:opts.Limit]
+	}
+
+	return result, next, nil
 }
 
 func (db *PostgresDB) FindOneClient(clientID string) (*model.ProjectClient, error) {
-	row := db.QueryRow("SELECT client_id, project_id, name, secret FROM project_clients WHERE client_id = $1", clientID)
+	row := db.QueryRow("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE client_id = $1", clientID)
 	result := model.ProjectClient{}
-	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret)
+	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret, &result.CanAddKeys)
 	if err != nil {
 		return nil, parseError(err)
 	}
@@ -32,10 +49,10 @@ func (db *PostgresDB) FindOneClient(clientID string) (*model.ProjectClient, erro
 }
 
 func (db *PostgresDB) GetProjectClient(projectID, clientID string) (*model.ProjectClient, error) {
-	row := db.QueryRow("SELECT client_id, project_id, name, secret FROM project_clients WHERE project_id = $1 AND client_id = $2",
+	row := db.QueryRow("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE project_id = $1 AND client_id = $2",
 		projectID, clientID)
 	result := model.ProjectClient{}
-	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret)
+	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret, &result.CanAddKeys)
 	if err != nil {
 		return nil, parseError(err)
 	}
@@ -43,10 +60,10 @@ func (db *PostgresDB) GetProjectClient(projectID, clientID string) (*model.Proje
 }
 
 func (db *PostgresDB) CreateProjectClient(pc model.ProjectClient) (*model.ProjectClient, error) {
-	row := db.QueryRow("INSERT INTO project_clients (project_id, name, secret) VALUES($1, $2, $3) RETURNING client_id, project_id, name, secret",
-		pc.ProjectID, pc.Name, pc.Secret)
+	row := db.QueryRow("INSERT INTO project_clients (project_id, name, secret, can_add_keys) VALUES($1, $2, $3, $4) RETURNING client_id, project_id, name, secret, can_add_keys",
+		pc.ProjectID, pc.Name, pc.Secret, pc.CanAddKeys)
 	result := model.ProjectClient{}
-	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret)
+	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret, &result.CanAddKeys)
 	if err != nil {
 		return nil, parseError(err)
 	}
@@ -75,3 +92,12 @@ func (db *PostgresDB) UpdateProjectClientName(pc model.ProjectClient) (*model.Pr
 	}
 	return db.GetProjectClient(pc.ProjectID, pc.ClientID)
 }
+
+func (db *PostgresDB) UpdateProjectClientCanAddKeys(pc model.ProjectClient) (*model.ProjectClient, error) {
+	_, err := db.Exec("UPDATE project_clients SET can_add_keys = $1 WHERE project_id = $2 AND client_id = $3",
+		pc.CanAddKeys, pc.ProjectID, pc.ClientID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectClient(pc.ProjectID, pc.ClientID)
+}
@@ -1,52 +1,58 @@
 package postgres
 
-import (
-	"github.com/iris-contrib/parrot/parrot-api/model"
-	"github.com/lib/pq"
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+// projectSortColumns and projectUserSortColumns map the sort fields to their columns.
+var (
+	projectSortColumns     = map[string]string{"name": "projects.name", "id": "projects.id"}
+	projectUserSortColumns = map[string]string{"name": "users.name", "email": "users.email"}
 )
 
-func (db *PostgresDB) GetUserProjects(userID string) ([]model.Project, error) {
-	rows, err := db.Query(`SELECT projects.*
+func (db *PostgresDB) GetUserProjects(userID string, opts model.ListOptions) ([]model.Project, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectSortFields)
+	cond, clauses, args := keyset(opts, projectSortColumns[opts.Sort], "projects.id", []interface{}{userID})
+	rows, err := db.Query(`SELECT `+projectColumns+`
 							FROM projects
 							JOIN projects_users ON projects.id = projects_users.project_id
-							WHERE projects_users.user_id = $1`, userID)
+							WHERE projects_users.user_id = $1 AND `+cond+` `+clauses, args...)
 	if err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 	defer rows.Close()
 
 	projects := make([]model.Project, 0)
 	for rows.Next() {
-		p := model.Project{}
-		keys := pq.StringArray{}
-
-		err := rows.Scan(&p.ID, &p.Name, &keys)
+		p, err := scanProject(rows)
 		if err != nil {
-			return nil, parseError(err)
-		}
-
-		p.Keys = make([]string, len(keys))
-		for i, v := range keys {
-			p.Keys[i] = v
+			return nil, nil, parseError(err)
 		}
 
-		projects = append(projects, p)
+		projects = append(projects, *p)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 
-	return projects, nil
+	next := opts.NextCursor(len(projects), func(i int) (string, string) {
+		return projects[i].SortValue(opts.Sort), projects[i].ID
+	})
+	if next != nil {
+		projects = projects[:opts.Limit]
+	}
+
+	return projects, next, nil
 }
 
-func (db *PostgresDB) GetProjectUsers(projID string) ([]model.ProjectUser, error) {
+func (db *PostgresDB) GetProjectUsers(projID string, opts model.ListOptions) ([]model.ProjectUser, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectUserSortFields)
+	cond, clauses, args := keyset(opts, projectUserSortColumns[opts.Sort], "projects_users.user_id", []interface{}{projID})
 	rows, err := db.Query(`SELECT user_id, project_id, users.email, users.name, role
 							FROM users
 							JOIN projects_users ON users.id = projects_users.user_id
-							WHERE projects_users.project_id = $1`, projID)
+							WHERE projects_users.project_id = $1 AND `+cond+` `+clauses, args...)
 	if err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
 	}
 	defer rows.Close()
 
@@ -56,16 +62,23 @@ func (db *PostgresDB) GetProjectUsers(projID string) ([]model.ProjectUser, error
 
 		err := rows.Scan(&u.UserID, &u.ProjectID, &u.Email, &u.Name, &u.Role)
 		if err != nil {
-			return nil, parseError(err)
+			return nil, nil, parseError(err)
 		}
 		users = append(users, u)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, parseError(err)
+		return nil, nil, parseError(err)
+	}
+
+	next := opts.NextCursor(len(users), func(i int) (string, string) {
+		return users[i].SortValue(opts.Sort), users[i].UserID
+	})
+	if next != nil {
+		users = users[:opts.Limit]
 	}
 
-	return users, nil
+	return users, next, nil
 }
 
 func (db *PostgresDB) GetUserProjectRoles(userID string) ([]model.ProjectUser, error) {
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func (db *PostgresDB) SearchProject(projectID string, q model.SearchQuery) ([]model.SearchResult, error) {
+	match, err := q.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	project, err := db.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if q.Locales == nil {
+		q.Locales = []string{}
+	}
+
+	// Project keys are few, so they are matched here
+	keys := make([]string, 0)
+	for _, k := range project.Keys {
+		if match(k) {
+			keys = append(keys, k)
+		}
+	}
+
+	if !q.Untranslated {
+		matches, err := db.searchValues(projectID, q, match)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		return model.GroupSearchResults(keys, matches), nil
+	}
+
+	matches, err := db.searchUntranslated(project, keys, q)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	// Only the keys with untranslated entries are results
+	keys = nil
+	if q.Text != "" {
+		for k := range matches {
+			keys = append(keys, k)
+		}
+	}
+
+	return model.GroupSearchResults(keys, matches), nil
+}
+
+// searchValues returns the entries whose value, or plural form, matches the search text.
+// Unless the text holds characters that hstore escapes, locales are first filtered on
+// the text of their pairs, which is indexed with trigrams. Regular expressions of
+// Postgres differ from Go's, so values are only filtered on the literal prefix of the
+// expression, ignoring case, and matched here.
+func (db *PostgresDB) searchValues(projectID string, q model.SearchQuery, match func(string) bool) (map[string][]model.SearchMatch, error) {
+	text := q.Text
+	if q.Regex {
+		text = q.Literal()
+	}
+
+	prefilter := "TRUE"
+	args := []interface{}{projectID, pq.Array(q.Locales), text}
+	if text != "" && !strings.ContainsAny(text, `"\`) {
+		op := "LIKE"
+		if q.IgnoreCase || q.Regex {
+			op = "ILIKE"
+		}
+		pattern := strings.NewReplacer("%", `\%`, "_", `\_`).Replace(text)
+		args = append(args, "%"+pattern+"%")
+		prefilter = "l.pairs::text " + op + " $4"
+	}
+
+	rows, err := db.Query(`SELECT e.key, l.ident, '', e.value
+							FROM locales l, each(l.pairs) AS e
+							WHERE l.project_id = $1 AND (cardinality($2::text[]) = 0 OR l.ident = ANY($2))
+							AND `+prefilter+` AND NOT l.plurals ? e.key AND `+matchCondition(q, "e.value", 3)+`
+							UNION ALL
+							SELECT pl.key, l.ident, f.key, f.value
+							FROM locales l, jsonb_each(l.plurals) AS pl, jsonb_each_text(pl.value) AS f
+							WHERE l.project_id = $1 AND (cardinality($2::text[]) = 0 OR l.ident = ANY($2))
+							AND `+matchCondition(q, "f.value", 3), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := scanSearchMatches(rows)
+	if err != nil || !q.Regex {
+		return matches, err
+	}
+	for k, entries := range matches {
+		var kept []model.SearchMatch
+		for _, m := range entries {
+			if match(m.Value) {
+				kept = append(kept, m)
+			}
+		}
+		if kept == nil {
+			delete(matches, k)
+			continue
+		}
+		matches[k] = kept
+	}
+	return matches, nil
+}
+
+// searchUntranslated returns the entries of the keys without a value. Plural keys
+// are untranslated until every plural category of the locale's language has a value.
+func (db *PostgresDB) searchUntranslated(project *model.Project, keys []string, q model.SearchQuery) (map[string][]model.SearchMatch, error) {
+	locs, _, err := db.FindProjectLocales(project.ID, model.LocaleQuery{Idents: q.Locales, WithoutPairs: true})
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[string][]string, len(locs))
+	for _, loc := range locs {
+		categories[loc.Ident] = loc.PluralRule().Categories
+	}
+	categoriesValue, err := json.Marshal(categories)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT k.key, l.ident, '', ''
+							FROM locales l, unnest($3::text[]) AS k(key)
+							WHERE l.project_id = $1 AND (cardinality($2::text[]) = 0 OR l.ident = ANY($2))
+							AND CASE WHEN k.key = ANY($4::text[])
+								THEN EXISTS (SELECT 1 FROM jsonb_array_elements_text($5::jsonb -> l.ident) AS c(category)
+											WHERE coalesce(l.plurals -> k.key ->> c.category, '') = '')
+								ELSE coalesce(l.pairs -> k.key, '') = ''
+							END`, project.ID, pq.Array(q.Locales), pq.Array(keys), pq.Array(project.PluralKeys), string(categoriesValue))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanSearchMatches(rows)
+}
+
+// matchCondition returns the condition matching the column against the search text,
+// the query parameter at index param. With a regular expression, the parameter is
+// its literal prefix, which the values only have to contain, ignoring case.
+func matchCondition(q model.SearchQuery, column string, param int) string {
+	switch {
+	case q.Regex || q.IgnoreCase:
+		return fmt.Sprintf("strpos(lower(%s), lower($%d)) > 0", column, param)
+	}
+	return fmt.Sprintf("strpos(%s, $%d) > 0", column, param)
+}
+
+// scanSearchMatches scans and closes rows of key, locale ident, plural category and value.
+func scanSearchMatches(rows *sql.Rows) (map[string][]model.SearchMatch, error) {
+	defer rows.Close()
+
+	matches := make(map[string][]model.SearchMatch)
+	for rows.Next() {
+		var k string
+		m := model.SearchMatch{}
+		if err := rows.Scan(&k, &m.Locale, &m.Category, &m.Value); err != nil {
+			return nil, err
+		}
+		matches[k] = append(matches[k], m)
+	}
+
+	return matches, rows.Err()
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/lib/pq"
+)
+
+// refreshTokenColumns lists the refresh token columns in the order expected by scanRefreshToken.
+const refreshTokenColumns = "id, token_hash, family_id, subject_id, subject_type, expires_at, created_at, revoked_at"
+
+func (db *PostgresDB) CreateRefreshToken(token model.RefreshToken) (*model.RefreshToken, error) {
+	row := db.QueryRow(`INSERT INTO refresh_tokens (token_hash, family_id, subject_id, subject_type, expires_at)
+						VALUES($1, COALESCE(NULLIF($2, '')::uuid, uuid_generate_v4()), $3, $4, $5)
+						RETURNING `+refreshTokenColumns,
+		token.TokenHash, token.FamilyID, token.SubjectID, token.SubjectType, token.ExpiresAt)
+	result, err := scanRefreshToken(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	row := db.QueryRow("SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash = $1", tokenHash)
+	result, err := scanRefreshToken(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) RotateRefreshToken(oldTokenHash string, token model.RefreshToken) (*model.RefreshToken, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Only an active token can be rotated, so concurrent uses of the same token can't both succeed
+	res, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = now() WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > now()", oldTokenHash)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if n == 0 {
+		return nil, errors.ErrNotFound
+	}
+
+	row := tx.QueryRow(`INSERT INTO refresh_tokens (token_hash, family_id, subject_id, subject_type, expires_at)
+						VALUES($1, $2, $3, $4, $5)
+						RETURNING `+refreshTokenColumns,
+		token.TokenHash, token.FamilyID, token.SubjectID, token.SubjectType, token.ExpiresAt)
+	result, err := scanRefreshToken(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) RevokeRefreshTokenFamily(familyID string) error {
+	_, err := db.Exec("UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL", familyID)
+	return parseError(err)
+}
+
+func (db *PostgresDB) RevokeSubjectRefreshTokens(subjectID string) error {
+	_, err := db.Exec("UPDATE refresh_tokens SET revoked_at = now() WHERE subject_id = $1 AND revoked_at IS NULL", subjectID)
+	return parseError(err)
+}
+
+func (db *PostgresDB) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	_, err := db.Exec("INSERT INTO revoked_tokens (token_id, expires_at) VALUES($1, $2) ON CONFLICT DO NOTHING", tokenID, expiresAt)
+	if err != nil {
+		return parseError(err)
+	}
+
+	// Expired tokens are rejected anyway, no need to keep them around
+	_, err = db.Exec("DELETE FROM revoked_tokens WHERE expires_at < now()")
+	return parseError(err)
+}
+
+func (db *PostgresDB) RevokeSubjectAccessTokens(subjectID string, issuedBefore, expiresAt time.Time) error {
+	_, err := db.Exec(`INSERT INTO revoked_subjects (subject_id, issued_before, expires_at) VALUES($1, $2, $3)
+						ON CONFLICT (subject_id) DO UPDATE SET issued_before = excluded.issued_before, expires_at = excluded.expires_at`,
+		subjectID, issuedBefore, expiresAt)
+	if err != nil {
+		return parseError(err)
+	}
+
+	_, err = db.Exec("DELETE FROM revoked_subjects WHERE expires_at < now()")
+	return parseError(err)
+}
+
+func (db *PostgresDB) IsAccessTokenRevoked(tokenID, subjectID string, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1 AND token_id != '')
+						OR EXISTS (SELECT 1 FROM revoked_subjects WHERE subject_id = $2 AND issued_before > $3)`,
+		tokenID, subjectID, issuedAt).Scan(&revoked)
+	if err != nil {
+		return false, parseError(err)
+	}
+
+	return revoked, nil
+}
+
+// scanRefreshToken scans a refresh token from a single result row selected with refreshTokenColumns.
+func scanRefreshToken(row scanner) (*model.RefreshToken, error) {
+	t := model.RefreshToken{}
+	revokedAt := pq.NullTime{}
+
+	err := row.Scan(&t.ID, &t.TokenHash, &t.FamilyID, &t.SubjectID, &t.SubjectType, &t.ExpiresAt, &t.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+// translationConfigColumns lists the translation config columns in the order expected by scanTranslationConfig.
+const translationConfigColumns = "project_id, provider, url, api_key, updated_at"
+
+func (db *PostgresDB) GetTranslationConfig(projectID string) (*model.TranslationConfig, error) {
+	row := db.QueryRow("SELECT "+translationConfigColumns+" FROM translation_configs WHERE project_id = $1", projectID)
+	result, err := scanTranslationConfig(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) SetTranslationConfig(c model.TranslationConfig) (*model.TranslationConfig, error) {
+	row := db.QueryRow(`INSERT INTO translation_configs (project_id, provider, url, api_key)
+						VALUES($1, $2, $3, $4)
+						ON CONFLICT (project_id) DO UPDATE SET provider = $2, url = $3, api_key = $4, updated_at = now()
+						RETURNING `+translationConfigColumns,
+		c.ProjectID, c.Provider, c.URL, c.APIKey)
+	result, err := scanTranslationConfig(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) DeleteTranslationConfig(projectID string) error {
+	_, err := db.Exec("DELETE FROM translation_configs WHERE project_id = $1", projectID)
+	return parseError(err)
+}
+
+// scanTranslationConfig scans a translation config from a single result row selected with translationConfigColumns.
+func scanTranslationConfig(row scanner) (*model.TranslationConfig, error) {
+	c := model.TranslationConfig{}
+	err := row.Scan(&c.ProjectID, &c.Provider, &c.URL, &c.APIKey, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/lib/pq"
+)
+
+// webhookColumns lists the webhook columns in the order expected by scanWebhook.
+const webhookColumns = "id, project_id, url, secret, events, active, created_at"
+
+// deliveryColumns lists the delivery columns in the order expected by scanDelivery.
+const deliveryColumns = `id, webhook_id, project_id, event, payload, status, attempts, next_attempt_at,
+						last_status_code, last_error, created_at, delivered_at`
+
+func (db *PostgresDB) GetProjectWebhooks(projectID string) ([]model.Webhook, error) {
+	rows, err := db.Query("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = $1 ORDER BY created_at, id", projectID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	result, err := scanWebhooks(rows)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) GetProjectWebhook(projectID, webhookID string) (*model.Webhook, error) {
+	row := db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = $1 AND id = $2", projectID, webhookID)
+	result, err := scanWebhook(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) CreateWebhook(w model.Webhook) (*model.Webhook, error) {
+	row := db.QueryRow(`INSERT INTO webhooks (project_id, url, secret, events, active)
+						VALUES($1, $2, $3, $4, $5)
+						RETURNING `+webhookColumns,
+		w.ProjectID, w.URL, w.Secret, pq.Array(eventsValue(w.Events)), w.Active)
+	result, err := scanWebhook(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) UpdateWebhook(w model.Webhook) (*model.Webhook, error) {
+	row := db.QueryRow(`UPDATE webhooks SET url = $1, secret = $2, events = $3, active = $4
+						WHERE project_id = $5 AND id = $6
+						RETURNING `+webhookColumns,
+		w.URL, w.Secret, pq.Array(eventsValue(w.Events)), w.Active, w.ProjectID, w.ID)
+	result, err := scanWebhook(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) DeleteWebhook(projectID, webhookID string) error {
+	_, err := db.Exec("DELETE FROM webhooks WHERE project_id = $1 AND id = $2", projectID, webhookID)
+	return parseError(err)
+}
+
+func (db *PostgresDB) CreateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	row := db.QueryRow(`INSERT INTO webhook_deliveries (webhook_id, project_id, event, payload, status, next_attempt_at)
+						VALUES($1, $2, $3, $4, $5, $6)
+						RETURNING `+deliveryColumns,
+		d.WebhookID, d.ProjectID, d.Event, d.Payload, model.DeliveryPending, d.NextAttemptAt)
+	result, err := scanDelivery(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	// Rows locked by a concurrent claim are skipped rather than waited for
+	rows, err := db.Query(`UPDATE webhook_deliveries SET next_attempt_at = now() + make_interval(secs => $1)
+						WHERE id IN (
+							SELECT id FROM webhook_deliveries
+							WHERE status = $2 AND next_attempt_at <= now()
+							ORDER BY next_attempt_at
+							LIMIT $3
+							FOR UPDATE SKIP LOCKED)
+						RETURNING `+deliveryColumns,
+		lease.Seconds(), model.DeliveryPending, limit)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (db *PostgresDB) UpdateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	row := db.QueryRow(`UPDATE webhook_deliveries
+						SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5, delivered_at = $6
+						WHERE id = $7
+						RETURNING `+deliveryColumns,
+		d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt, d.ID)
+	result, err := scanDelivery(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *PostgresDB) GetWebhookDeliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := db.Query("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC, id LIMIT $2",
+		webhookID, limit)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+// scanWebhook scans a webhook from a single result row selected with webhookColumns.
+// enqueueEvents stores the deliveries of the events to the webhooks subscribed to them.
+func enqueueEvents(tx *sql.Tx, events []model.Event) error {
+	webhooks := make(map[string][]model.Webhook)
+	for _, e := range events {
+		projectWebhooks, ok := webhooks[e.ProjectID]
+		if !ok {
+			rows, err := tx.Query("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = $1 ORDER BY created_at, id", e.ProjectID)
+			if err != nil {
+				return err
+			}
+			if projectWebhooks, err = scanWebhooks(rows); err != nil {
+				return err
+			}
+			webhooks[e.ProjectID] = projectWebhooks
+		}
+
+		deliveries, err := e.Deliveries(projectWebhooks)
+		if err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			_, err := tx.Exec(`INSERT INTO webhook_deliveries (webhook_id, project_id, event, payload, status, next_attempt_at)
+								VALUES($1, $2, $3, $4, $5, $6)`,
+				d.WebhookID, d.ProjectID, d.Event, d.Payload, model.DeliveryPending, d.NextAttemptAt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanWebhooks reads the webhooks of the rows and closes them.
+func scanWebhooks(rows *sql.Rows) ([]model.Webhook, error) {
+	defer rows.Close()
+
+	result := make([]model.Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *w)
+	}
+
+	return result, rows.Err()
+}
+
+func scanWebhook(row scanner) (*model.Webhook, error) {
+	w := model.Webhook{}
+	err := row.Scan(&w.ID, &w.ProjectID, &w.URL, &w.Secret, pq.Array(&w.Events), &w.Active, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	w.Events = eventsValue(w.Events)
+
+	return &w, nil
+}
+
+// scanDelivery scans a delivery from a single result row selected with deliveryColumns.
+func scanDelivery(row scanner) (*model.WebhookDelivery, error) {
+	d := model.WebhookDelivery{}
+	deliveredAt := pq.NullTime{}
+
+	err := row.Scan(&d.ID, &d.WebhookID, &d.ProjectID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &d, nil
+}
+
+// scanDeliveries scans and closes the rows.
+func scanDeliveries(rows *sql.Rows) ([]model.WebhookDelivery, error) {
+	defer rows.Close()
+
+	result := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		result = append(result, *d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// eventsValue returns the events, never nil.
+func eventsValue(events []string) []string {
+	if events == nil {
+		return []string{}
+	}
+	return events
+}
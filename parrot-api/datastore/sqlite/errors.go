@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/mattn/go-sqlite3"
+)
+
+// parseError maps SQLite errors to datastore errors, the same way the Postgres datastore does.
+func parseError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Check if error is a sqlite3 driver error and match accordingly
+	e, ok := err.(sqlite3.Error)
+	if ok {
+		switch e.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return errors.ErrAlreadyExists
+		case sqlite3.ErrConstraintForeignKey:
+			// The referenced entry, such as the project of a locale, doesn't exist
+			return errors.ErrNotFound
+		}
+	}
+
+	// Otherwise check if error comes from the sql package
+	switch err {
+	case sql.ErrNoRows:
+		return errors.ErrNotFound
+	}
+
+	// If no match could be done, simply return internal error
+	return err
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// historyColumns lists the history columns in the order expected by scanHistoryEntry.
+const historyColumns = "id, project_id, locale_ident, key, action, old_value, new_value, subject_id, subject_type, created_at"
+
+func (db *SQLiteDB) AddHistoryEntries(entries []model.HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := db.transact(func(tx *sql.Tx) error {
+		return addHistoryEntries(tx, entries)
+	})
+	return parseError(err)
+}
+
+// writeJournal stores the entries recorded in the journal, if any, and queues
+// the deliveries of its events.
+func writeJournal(db querier, j *model.Journal) error {
+	if j == nil {
+		return nil
+	}
+	if err := addHistoryEntries(db, j.Entries); err != nil {
+		return err
+	}
+	return enqueueEvents(db, j.Events())
+}
+
+func addHistoryEntries(db querier, entries []model.HistoryEntry) error {
+	createdAt := now()
+	for _, e := range entries {
+		_, err := db.Exec(`INSERT INTO history (id, project_id, locale_ident, key, action, old_value, new_value, subject_id, subject_type, created_at)
+							VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			newID(), e.ProjectID, e.LocaleIdent, e.Key, e.Action, e.OldValue, e.NewValue, e.SubjectID, e.SubjectType, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SQLiteDB) GetLocaleHistory(projID, localeIdent, key string) ([]model.HistoryEntry, error) {
+	rows, err := db.Query(`SELECT `+historyColumns+`
+							FROM history
+							WHERE project_id = ?1 AND (locale_ident = ?2 OR locale_ident = '')
+							AND (?3 = '' OR key = ?3 OR (action = 'key_renamed' AND new_value = ?3))
+							ORDER BY created_at DESC, rowid DESC`, projID, localeIdent, key)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.HistoryEntry, 0)
+	for rows.Next() {
+		e, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		entries = append(entries, *e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return entries, nil
+}
+
+func (db *SQLiteDB) GetHistoryEntry(projID, entryID string) (*model.HistoryEntry, error) {
+	row := db.QueryRow("SELECT "+historyColumns+" FROM history WHERE project_id = ? AND id = ?", projID, entryID)
+	e, err := scanHistoryEntry(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return e, nil
+}
+
+// scanHistoryEntry scans a history entry from a single result row selected with historyColumns.
+func scanHistoryEntry(row scanner) (*model.HistoryEntry, error) {
+	e := model.HistoryEntry{}
+	err := row.Scan(&e.ID, &e.ProjectID, &e.LocaleIdent, &e.Key, &e.Action, &e.OldValue, &e.NewValue,
+		&e.SubjectID, &e.SubjectType, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// keyColumns lists the key columns in the order expected by scanKey.
+const keyColumns = "k.project_id, k.key, k.description, k.context, k.max_length, k.tags, k.created_at, k.updated_at, p.keys, p.plural_keys"
+
+func (db *SQLiteDB) GetProjectKeys(projectID string) ([]model.Key, error) {
+	rows, err := db.Query(`SELECT `+keyColumns+` FROM project_keys k
+							JOIN projects p ON p.id = k.project_id
+							WHERE k.project_id = ?
+							ORDER BY k.key`, projectID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	keys := make([]model.Key, 0)
+	for rows.Next() {
+		k, err := scanKey(rows)
+		if err == errors.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, parseError(err)
+		}
+		keys = append(keys, *k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return keys, nil
+}
+
+func (db *SQLiteDB) GetProjectKey(projectID, key string) (*model.Key, error) {
+	return getProjectKey(db, projectID, key)
+}
+
+func (db *SQLiteDB) UpdateProjectKeyMeta(key model.Key) (*model.Key, error) {
+	var result *model.Key
+	err := db.transact(func(tx *sql.Tx) error {
+		if err := updateKeyMeta(tx, key); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = getProjectKey(tx, key.ProjectID, key.Key)
+		return err
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// updateKeyMeta stores the description, context, max length and tags of the key's record.
+func updateKeyMeta(db querier, key model.Key) error {
+	tags, err := stringsValue(key.Tags)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE project_keys SET description = ?, context = ?, max_length = ?, tags = ?, updated_at = ?
+						WHERE project_id = ? AND key = ?`,
+		key.Description, key.Context, key.MaxLength, tags, now(), key.ProjectID, key.Key)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func getProjectKey(db querier, projectID, key string) (*model.Key, error) {
+	row := db.QueryRow(`SELECT `+keyColumns+` FROM project_keys k
+						JOIN projects p ON p.id = k.project_id
+						WHERE k.project_id = ? AND k.key = ?`, projectID, key)
+	k, err := scanKey(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return k, nil
+}
+
+// syncKeyRecords creates the metadata records of new project keys and removes
+// the ones of keys that are no longer part of the project.
+func syncKeyRecords(db querier, projectID string, keys []string) error {
+	createdAt := now()
+	for _, key := range keys {
+		_, err := db.Exec("INSERT OR IGNORE INTO project_keys (project_id, key, created_at, updated_at) VALUES(?, ?, ?, ?)",
+			projectID, key, createdAt, createdAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := db.Query("SELECT key FROM project_keys WHERE project_id = ?", projectID)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return err
+		}
+		if !contains(keys, key) {
+			stale = append(stale, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range stale {
+		_, err := db.Exec("DELETE FROM project_keys WHERE project_id = ? AND key = ?", projectID, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanKey scans a key from a single result row selected with keyColumns. It returns
+// ErrNotFound for records of keys that are no longer part of the project.
+func scanKey(row scanner) (*model.Key, error) {
+	k := model.Key{}
+	var tags, keys, pluralKeys string
+
+	err := row.Scan(&k.ProjectID, &k.Key, &k.Description, &k.Context, &k.MaxLength, &tags, &k.CreatedAt, &k.UpdatedAt, &keys, &pluralKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.Tags, err = unmarshalStrings(tags); err != nil {
+		return nil, err
+	}
+
+	projectKeys, err := unmarshalStrings(keys)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(projectKeys, k.Key) {
+		return nil, errors.ErrNotFound
+	}
+
+	plural, err := unmarshalStrings(pluralKeys)
+	if err != nil {
+		return nil, err
+	}
+	k.Plural = contains(plural, k.Key)
+
+	return &k, nil
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// keyset returns the condition selecting the rows after the cursor, 1 for the
+// first page, and the ORDER BY and LIMIT clauses of the page. Ties on the sort
+// column are ordered by the id column. The returned arguments are args followed
+// by the ones of the clauses.
+func keyset(opts model.ListOptions, column, idColumn string, args []interface{}) (string, string, []interface{}) {
+	dir, op := "ASC", ">"
+	if opts.Desc {
+		dir, op = "DESC", "<"
+	}
+
+	cond := "1"
+	if opts.After != nil {
+		args = append(args, opts.After.Value, opts.After.ID)
+		cond = fmt.Sprintf("(%s, %s) %s (?, ?)", column, idColumn, op)
+	}
+
+	clauses := fmt.Sprintf("ORDER BY %s %s, %s %s", column, dir, idColumn, dir)
+	if opts.Limit > 0 {
+		// One more row tells whether there is a next page
+		args = append(args, opts.Limit+1)
+		clauses += " LIMIT ?"
+	}
+
+	return cond, clauses, args
+}
@@ -0,0 +1,291 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// localeColumns lists the locale columns in the order expected by scanLocale.
+const localeColumns = "id, ident, language, country, pairs, plurals, statuses, project_id, version"
+
+// localeSummaryColumns selects the same columns as localeColumns, without loading
+// the pairs, plural forms and statuses.
+const localeSummaryColumns = "id, ident, language, country, '{}', '{}', '{}', project_id, version"
+
+func (db *SQLiteDB) CreateLocale(loc model.Locale, j *model.Journal) (*model.Locale, error) {
+	values, err := pairsValue(loc.Pairs)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	plurals, err := pluralsValue(loc.Plurals)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	loc.ID = newID()
+	loc.Version = 1
+	err = db.transact(func(tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT INTO locales (id, ident, language, country, pairs, plurals, project_id) VALUES(?, ?, ?, ?, ?, ?, ?)",
+			loc.ID, loc.Ident, loc.Language, loc.Country, values, plurals, loc.ProjectID)
+		if err != nil {
+			return err
+		}
+		j.Emit(loc.ProjectID, model.EventLocaleCreated, map[string]interface{}{"locale": loc.Ident})
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return &loc, nil
+}
+
+func (db *SQLiteDB) UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPairs(pairs)
+	})
+}
+
+func (db *SQLiteDB) MergeLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.PutPairs(pairs)
+	})
+}
+
+func (db *SQLiteDB) UpdateLocalePlurals(projID string, localeIdent string, plurals map[string]model.PluralForms, version int, j *model.Journal) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, version, j, func(loc *model.Locale) {
+		loc.SetPlurals(plurals)
+	})
+}
+
+func (db *SQLiteDB) UpdateLocaleStatuses(projID string, localeIdent string, statuses map[string]string) (*model.Locale, error) {
+	return db.updateLocale(projID, localeIdent, 0, nil, func(loc *model.Locale) {
+		// Translated is the default status of values, so it is not stored
+		for k, v := range statuses {
+			if v == model.StatusTranslated {
+				delete(loc.Statuses, k)
+				continue
+			}
+			loc.Statuses[k] = v
+		}
+	})
+}
+
+func (db *SQLiteDB) DeleteLocale(projID string, ident string, j *model.Journal) error {
+	err := db.transact(func(tx *sql.Tx) error {
+		res, err := tx.Exec("DELETE FROM locales WHERE project_id = ? AND ident = ?", projID, ident)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+		j.Emit(projID, model.EventLocaleDeleted, map[string]interface{}{"locale": ident})
+		return writeJournal(tx, j)
+	})
+	return parseError(err)
+}
+
+func (db *SQLiteDB) ImportLocale(projID, localeIdent string, keys, pluralKeys []string, fn func(*model.Project, *model.Locale) error, j *model.Journal) (*model.Locale, []string, error) {
+	var result *model.Locale
+	var added []string
+	err := db.transact(func(tx *sql.Tx) error {
+		project, err := getProject(tx, projID)
+		if err != nil {
+			return err
+		}
+		previous := append([]string(nil), project.Keys...)
+		added = nil
+		for _, k := range keys {
+			if k == "" || contains(project.Keys, k) {
+				continue
+			}
+			project.Keys = append(project.Keys, k)
+			if contains(pluralKeys, k) {
+				project.PluralKeys = append(project.PluralKeys, k)
+			}
+			added = append(added, k)
+		}
+		if len(added) > 0 {
+			if err := saveProject(tx, project); err != nil {
+				return err
+			}
+			if err := syncKeyRecords(tx, projID, project.Keys); err != nil {
+				return err
+			}
+			j.Record(model.KeyChanges(projID, previous, project.Keys)...)
+		}
+
+		loc, err := getProjectLocale(tx, projID, localeIdent)
+		if err != nil {
+			return err
+		}
+		before := loc.Copy()
+		if err := fn(project, loc); err != nil {
+			return err
+		}
+		if err := saveLocale(tx, loc); err != nil {
+			return err
+		}
+		j.RecordLocale(before, loc)
+		result = loc
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	return result, added, nil
+}
+
+func (db *SQLiteDB) UpdateLocales(projID string, localeIdents []string, fn func(*model.Locale) error, j *model.Journal) ([]model.Locale, error) {
+	var result []model.Locale
+	err := db.transact(func(tx *sql.Tx) error {
+		result = nil
+		var seen []string
+		for _, ident := range localeIdents {
+			if contains(seen, ident) {
+				continue
+			}
+			seen = append(seen, ident)
+
+			loc, err := getProjectLocale(tx, projID, ident)
+			if err != nil {
+				return err
+			}
+			before := loc.Copy()
+			if err := fn(loc); err != nil {
+				return err
+			}
+			if err := saveLocale(tx, loc); err != nil {
+				return err
+			}
+			j.RecordLocale(before, loc)
+			result = append(result, *loc)
+		}
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// updateLocale applies fn to the stored locale in a transaction, then saves it
+// along with the history recorded in the journal.
+// A non-zero version must match the one of the stored locale.
+func (db *SQLiteDB) updateLocale(projID, ident string, version int, j *model.Journal, fn func(*model.Locale)) (*model.Locale, error) {
+	var result *model.Locale
+	err := db.transact(func(tx *sql.Tx) error {
+		loc, err := getProjectLocale(tx, projID, ident)
+		if err != nil {
+			return err
+		}
+		if version != 0 && loc.Version != version {
+			return errors.ErrStaleVersion
+		}
+		before := loc.Copy()
+		fn(loc)
+		if err := saveLocale(tx, loc); err != nil {
+			return err
+		}
+		j.RecordLocale(before, loc)
+		if err := writeJournal(tx, j); err != nil {
+			return err
+		}
+		result, err = getProjectLocale(tx, projID, ident)
+		return err
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func getProjectLocale(db querier, projID, ident string) (*model.Locale, error) {
+	row := db.QueryRow("SELECT "+localeColumns+" FROM locales WHERE project_id = ? AND ident = ?", projID, ident)
+	return scanLocale(row)
+}
+
+func getProjectLocales(db querier, projID string) ([]model.Locale, error) {
+	rows, err := db.Query("SELECT "+localeColumns+" FROM locales WHERE project_id = ? ORDER BY ident", projID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locs := make([]model.Locale, 0)
+	for rows.Next() {
+		loc, err := scanLocale(rows)
+		if err != nil {
+			return nil, err
+		}
+		locs = append(locs, *loc)
+	}
+
+	return locs, rows.Err()
+}
+
+// saveLocale stores the pairs, plural forms and statuses of the locale and
+// increments its version.
+func saveLocale(db querier, loc *model.Locale) error {
+	pairs, err := pairsValue(loc.Pairs)
+	if err != nil {
+		return err
+	}
+	plurals, err := pluralsValue(loc.Plurals)
+	if err != nil {
+		return err
+	}
+	statuses, err := pairsValue(loc.Statuses)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE locales SET pairs = ?, plurals = ?, statuses = ?, version = version + 1 WHERE id = ?", pairs, plurals, statuses, loc.ID)
+	if err != nil {
+		return err
+	}
+	loc.Version++
+	return nil
+}
+
+// scanLocale scans a locale from a single result row selected with localeColumns.
+func scanLocale(row scanner) (*model.Locale, error) {
+	loc := model.Locale{}
+	var pairs, plurals, statuses string
+
+	err := row.Scan(&loc.ID, &loc.Ident, &loc.Language, &loc.Country, &pairs, &plurals, &statuses, &loc.ProjectID, &loc.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if loc.Pairs, err = unmarshalPairs(pairs); err != nil {
+		return nil, err
+	}
+	if loc.Statuses, err = unmarshalPairs(statuses); err != nil {
+		return nil, err
+	}
+
+	loc.Plurals = make(map[string]model.PluralForms)
+	if plurals != "" {
+		if err := json.Unmarshal([]byte(plurals), &loc.Plurals); err != nil {
+			return nil, err
+		}
+	}
+
+	return &loc, nil
+}
+
+// pluralsValue encodes locale plural forms as JSON text, never null.
+func pluralsValue(plurals map[string]model.PluralForms) (string, error) {
+	if plurals == nil {
+		plurals = make(map[string]model.PluralForms)
+	}
+	return jsonValue(plurals)
+}
@@ -0,0 +1,29 @@
+package sqlite
+
+import "github.com/iris-contrib/parrot/parrot-api/datastore/migrate"
+
+// Transactions take the database write lock when they begin (see Open),
+// which serializes concurrent migration runners without an explicit lock.
+var dialect = migrate.Dialect{
+	CreateTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+					version INTEGER PRIMARY KEY,
+					name TEXT NOT NULL,
+					applied_at TIMESTAMP NOT NULL)`,
+	Placeholder: func(int) string { return "?" },
+}
+
+func (db *SQLiteDB) MigrateUp(migrationsDir string) error {
+	return migrate.Up(db.DB, dialect, migrationsDir)
+}
+
+func (db *SQLiteDB) MigrateDown(migrationsDir string) error {
+	return migrate.Down(db.DB, dialect, migrationsDir)
+}
+
+func (db *SQLiteDB) MigrateTo(migrationsDir string, version int64) error {
+	return migrate.To(db.DB, dialect, migrationsDir, version)
+}
+
+func (db *SQLiteDB) MigrationStatus(migrationsDir string) ([]migrate.Status, error) {
+	return migrate.GetStatus(db.DB, dialect, migrationsDir)
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS project_keys;
+DROP TABLE IF EXISTS history;
+DROP TABLE IF EXISTS project_clients;
+DROP TABLE IF EXISTS projects_users;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS locales;
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,18 @@
+CREATE TABLE locales_without_version (
+    id TEXT PRIMARY KEY,
+    ident TEXT NOT NULL,
+    language TEXT NOT NULL,
+    country TEXT NOT NULL,
+    pairs TEXT NOT NULL DEFAULT '{}',
+    plurals TEXT NOT NULL DEFAULT '{}',
+    statuses TEXT NOT NULL DEFAULT '{}',
+    project_id TEXT NOT NULL REFERENCES projects (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    UNIQUE (ident, project_id)
+);
+
+INSERT INTO locales_without_version (id, ident, language, country, pairs, plurals, statuses, project_id)
+    SELECT id, ident, language, country, pairs, plurals, statuses, project_id FROM locales;
+
+DROP TABLE locales;
+
+ALTER TABLE locales_without_version RENAME TO locales;
@@ -0,0 +1 @@
+ALTER TABLE locales ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
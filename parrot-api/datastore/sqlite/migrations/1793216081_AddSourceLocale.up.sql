@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN source_locale TEXT NOT NULL DEFAULT '';
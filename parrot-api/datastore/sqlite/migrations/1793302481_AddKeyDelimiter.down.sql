@@ -0,0 +1,3 @@
+-- SQLite can't drop columns, and rebuilding the projects table would cascade
+-- deletes to every table referencing it, so the column is only reset.
+UPDATE projects SET key_delimiter = '.';
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN key_delimiter TEXT NOT NULL DEFAULT '.';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS refresh_tokens_subject_idx;
+
+DROP TABLE IF EXISTS revoked_subjects;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS revoked_subjects (
+    subject_id TEXT PRIMARY KEY,
+    issued_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_subject_idx ON refresh_tokens (subject_id);
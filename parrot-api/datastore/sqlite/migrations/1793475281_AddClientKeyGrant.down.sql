@@ -0,0 +1,2 @@
+-- SQLite can't drop columns, so the grant is only taken back from every client.
+UPDATE project_clients SET can_add_keys = false;
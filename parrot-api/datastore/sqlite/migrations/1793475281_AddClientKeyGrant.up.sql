@@ -0,0 +1 @@
+ALTER TABLE project_clients ADD COLUMN can_add_keys BOOLEAN NOT NULL DEFAULT false;
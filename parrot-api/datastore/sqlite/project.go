@@ -0,0 +1,401 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// projectColumns lists the project columns in the order expected by scanProject.
+const projectColumns = "id, name, keys, plural_keys, fallbacks, source_locale, key_delimiter"
+
+func (db *SQLiteDB) GetProjects() ([]model.Project, error) {
+	rows, err := db.Query("SELECT " + projectColumns + " FROM projects ORDER BY name, id")
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	projects := make([]model.Project, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		projects = append(projects, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return projects, nil
+}
+
+func (db *SQLiteDB) GetProject(id string) (*model.Project, error) {
+	return getProject(db, id)
+}
+
+func (db *SQLiteDB) CreateProject(project model.Project) (*model.Project, error) {
+	keys, err := stringsValue(project.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.Project
+	err = db.transact(func(tx *sql.Tx) error {
+		id := newID()
+		_, err := tx.Exec("INSERT INTO projects (id, name, keys) VALUES(?, ?, ?)", id, project.Name, keys)
+		if err != nil {
+			return err
+		}
+
+		result, err = getProject(tx, id)
+		if err != nil {
+			return err
+		}
+
+		return syncKeyRecords(tx, result.ID, result.Keys)
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) UpdateProjectName(projectID, name string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.Name = name
+		return nil
+	})
+}
+
+func (db *SQLiteDB) AddProjectKey(key model.Key, j *model.Journal) (*model.Project, error) {
+	var result *model.Project
+	err := db.transact(func(tx *sql.Tx) error {
+		project, err := getProject(tx, key.ProjectID)
+		if err != nil {
+			return err
+		}
+		if contains(project.Keys, key.Key) {
+			return errors.ErrAlreadyExists
+		}
+
+		project.Keys = append(project.Keys, key.Key)
+		if key.Plural {
+			project.PluralKeys = append(project.PluralKeys, key.Key)
+		}
+		if err := saveProject(tx, project); err != nil {
+			return err
+		}
+		if err := syncKeyRecords(tx, key.ProjectID, project.Keys); err != nil {
+			return err
+		}
+		if err := updateKeyMeta(tx, key); err != nil {
+			return err
+		}
+		result = project
+
+		j.Record(model.KeyChanges(key.ProjectID, nil, []string{key.Key})...)
+		if key.Plural {
+			j.Record(model.KeyPluralUpdated(key.ProjectID, key.Key, true))
+		}
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) UpdateProjectKey(projectID, oldKey, newKey string, j *model.Journal) (*model.Project, int, error) {
+	var result *model.Project
+	count := 0
+
+	err := db.transact(func(tx *sql.Tx) error {
+		project, err := getProject(tx, projectID)
+		if err != nil {
+			return err
+		}
+		if !contains(project.Keys, oldKey) {
+			return errors.ErrNotFound
+		}
+		if contains(project.Keys, newKey) {
+			return errors.ErrAlreadyExists
+		}
+
+		// Step 1, replace the key in the project keys
+		for i, k := range project.Keys {
+			if k == oldKey {
+				project.Keys[i] = newKey
+			}
+		}
+		for i, k := range project.PluralKeys {
+			if k == oldKey {
+				project.PluralKeys[i] = newKey
+			}
+		}
+		if err := saveProject(tx, project); err != nil {
+			return err
+		}
+		result = project
+
+		// Step 2, move the key's metadata to the new key
+		_, err = tx.Exec("UPDATE project_keys SET key = ?, updated_at = ? WHERE project_id = ? AND key = ?", newKey, now(), projectID, oldKey)
+		if err != nil {
+			return err
+		}
+
+		// Step 3, find all project locales and update pairs
+		locales, err := getProjectLocales(tx, projectID)
+		if err != nil {
+			return err
+		}
+
+		for _, locale := range locales {
+			if v, ok := locale.Pairs[oldKey]; ok {
+				delete(locale.Pairs, oldKey)
+				locale.Pairs[newKey] = v
+			}
+			if forms, ok := locale.Plurals[oldKey]; ok {
+				delete(locale.Plurals, oldKey)
+				locale.Plurals[newKey] = forms
+			}
+			if status, ok := locale.Statuses[oldKey]; ok {
+				delete(locale.Statuses, oldKey)
+				locale.Statuses[newKey] = status
+			}
+			if err := saveLocale(tx, &locale); err != nil {
+				return err
+			}
+		}
+		count = len(locales)
+
+		j.Record(model.KeyRenamed(projectID, oldKey, newKey))
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, -1, parseError(err)
+	}
+
+	return result, count, nil
+}
+
+func (db *SQLiteDB) DeleteProjectKey(projectID, key string, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(projectID, j, func(p *model.Project) error {
+		if !contains(p.Keys, key) {
+			return errors.ErrNotFound
+		}
+		p.Keys = remove(p.Keys, key)
+		p.PluralKeys = remove(p.PluralKeys, key)
+		return nil
+	})
+}
+
+func (db *SQLiteDB) SetProjectKeyPlural(projectID, key string, plural bool, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(projectID, j, func(p *model.Project) error {
+		if !contains(p.Keys, key) {
+			return errors.ErrNotFound
+		}
+		if contains(p.PluralKeys, key) != plural {
+			j.Record(model.KeyPluralUpdated(projectID, key, plural))
+		}
+		p.PluralKeys = remove(p.PluralKeys, key)
+		if plural {
+			p.PluralKeys = append(p.PluralKeys, key)
+		}
+		return nil
+	})
+}
+
+func (db *SQLiteDB) UpdateProjectFallbacks(projectID string, fallbacks map[string][]string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.Fallbacks = fallbacks
+		return nil
+	})
+}
+
+func (db *SQLiteDB) UpdateProjectSourceLocale(projectID, ident string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.SourceLocale = ident
+		return nil
+	})
+}
+
+func (db *SQLiteDB) UpdateProjectKeyDelimiter(projectID, delimiter string) (*model.Project, error) {
+	return db.updateProject(projectID, nil, func(p *model.Project) error {
+		p.KeyDelimiter = delimiter
+		return nil
+	})
+}
+
+func (db *SQLiteDB) UpdateProject(project model.Project, j *model.Journal) (*model.Project, error) {
+	return db.updateProject(project.ID, j, func(p *model.Project) error {
+		p.Keys = project.Keys
+		p.PluralKeys = project.PluralKeys
+		return nil
+	})
+}
+
+func (db *SQLiteDB) DeleteProject(id string) error {
+	// Foreign keys cascade the delete to the project's data
+	_, err := db.Exec("DELETE FROM projects WHERE id = ?", id)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) GetProjectLocaleByIdent(projectID string, ident string) (*model.Locale, error) {
+	loc, err := getProjectLocale(db, projectID, ident)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return loc, nil
+}
+
+func (db *SQLiteDB) GetProjectLocales(projID string, localeIdents ...string) ([]model.Locale, error) {
+	locs, _, err := db.FindProjectLocales(projID, model.LocaleQuery{Idents: localeIdents})
+	return locs, err
+}
+
+func (db *SQLiteDB) FindProjectLocales(projID string, q model.LocaleQuery) ([]model.Locale, *model.Cursor, error) {
+	columns := localeColumns
+	if q.WithoutPairs {
+		columns = localeSummaryColumns
+	}
+
+	where := "project_id = ?"
+	args := []interface{}{projID}
+	if len(q.Idents) > 0 {
+		where += " AND ident IN (?" + strings.Repeat(", ?", len(q.Idents)-1) + ")"
+		for _, ident := range q.Idents {
+			args = append(args, ident)
+		}
+	}
+
+	q.Sort = q.SortField(model.LocaleSortFields)
+	cond, clauses, args := keyset(q.ListOptions, q.Sort, "id", args)
+	rows, err := db.Query("SELECT "+columns+" FROM locales WHERE "+where+" AND "+cond+" "+clauses, args...)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	defer rows.Close()
+
+	locs := make([]model.Locale, 0)
+	for rows.Next() {
+		loc, err := scanLocale(rows)
+		if err != nil {
+			return nil, nil, parseError(err)
+		}
+		locs = append(locs, *loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	next := q.NextCursor(len(locs), func(i int) (string, string) {
+		return locs[i].SortValue(q.Sort), locs[i].ID
+	})
+	if next != nil {
+		locs = locs[:q.Limit]
+	}
+
+	return locs, next, nil
+}
+
+// updateProject applies fn to the stored project in a transaction, then saves it
+// and syncs the key metadata records with its keys. The keys added and deleted
+// are recorded in the journal.
+func (db *SQLiteDB) updateProject(projectID string, j *model.Journal, fn func(*model.Project) error) (*model.Project, error) {
+	var result *model.Project
+	err := db.transact(func(tx *sql.Tx) error {
+		project, err := getProject(tx, projectID)
+		if err != nil {
+			return err
+		}
+		keys := append([]string(nil), project.Keys...)
+		if err := fn(project); err != nil {
+			return err
+		}
+		if err := saveProject(tx, project); err != nil {
+			return err
+		}
+		result = project
+
+		if err := syncKeyRecords(tx, projectID, project.Keys); err != nil {
+			return err
+		}
+		j.Record(model.KeyChanges(projectID, keys, project.Keys)...)
+		return writeJournal(tx, j)
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func getProject(db querier, id string) (*model.Project, error) {
+	row := db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = ?", id)
+	p, err := scanProject(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return p, nil
+}
+
+// saveProject stores the name, keys, fallbacks, source locale and key delimiter of the project.
+func saveProject(db querier, p *model.Project) error {
+	keys, err := stringsValue(p.Keys)
+	if err != nil {
+		return err
+	}
+	pluralKeys, err := stringsValue(p.PluralKeys)
+	if err != nil {
+		return err
+	}
+	if p.Fallbacks == nil {
+		p.Fallbacks = make(map[string][]string)
+	}
+	fallbacks, err := jsonValue(p.Fallbacks)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("UPDATE projects SET name = ?, keys = ?, plural_keys = ?, fallbacks = ?, source_locale = ?, key_delimiter = ? WHERE id = ?",
+		p.Name, keys, pluralKeys, fallbacks, p.SourceLocale, p.KeyDelimiter, p.ID)
+	return err
+}
+
+// scanProject scans a project from a single result row selected with projectColumns.
+func scanProject(row scanner) (*model.Project, error) {
+	p := model.Project{}
+	var keys, pluralKeys, fallbacks string
+
+	err := row.Scan(&p.ID, &p.Name, &keys, &pluralKeys, &fallbacks, &p.SourceLocale, &p.KeyDelimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Keys, err = unmarshalStrings(keys); err != nil {
+		return nil, err
+	}
+	if p.PluralKeys, err = unmarshalStrings(pluralKeys); err != nil {
+		return nil, err
+	}
+
+	p.Fallbacks = make(map[string][]string)
+	if fallbacks != "" {
+		if err := json.Unmarshal([]byte(fallbacks), &p.Fallbacks); err != nil {
+			return nil, err
+		}
+	}
+
+	return &p, nil
+}
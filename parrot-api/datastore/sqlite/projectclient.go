@@ -0,0 +1,102 @@
+package sqlite
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+// clientSortColumns maps the project client sort fields to their columns.
+var clientSortColumns = map[string]string{"name": "name", "id": "client_id"}
+
+func (db *SQLiteDB) GetProjectClients(projectID string, opts model.ListOptions) ([]model.ProjectClient, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectClientSortFields)
+	cond, clauses, args := keyset(opts, clientSortColumns[opts.Sort], "client_id", []interface{}{projectID})
+	rows, err := db.Query("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE project_id = ? AND "+cond+" "+clauses, args...)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	defer rows.Close()
+
+	result := make([]model.ProjectClient, 0)
+	for rows.Next() {
+		r := model.ProjectClient{}
+		err = rows.Scan(&r.ClientID, &r.ProjectID, &r.Name, &r.Secret, &r.CanAddKeys)
+		if err != nil {
+			return nil, nil, parseError(err)
+		}
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	next := opts.NextCursor(len(result), func(i int) (string, string) {
+		return result[i].SortValue(opts.Sort), result[i].ClientID
+	})
+	if next != nil {
+		result = result[:opts.Limit]
+	}
+
+	return result, next, nil
+}
+
+func (db *SQLiteDB) FindOneClient(clientID string) (*model.ProjectClient, error) {
+	row := db.QueryRow("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE client_id = ?", clientID)
+	result := model.ProjectClient{}
+	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret, &result.CanAddKeys)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &result, nil
+}
+
+func (db *SQLiteDB) GetProjectClient(projectID, clientID string) (*model.ProjectClient, error) {
+	row := db.QueryRow("SELECT client_id, project_id, name, secret, can_add_keys FROM project_clients WHERE project_id = ? AND client_id = ?",
+		projectID, clientID)
+	result := model.ProjectClient{}
+	err := row.Scan(&result.ClientID, &result.ProjectID, &result.Name, &result.Secret, &result.CanAddKeys)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &result, nil
+}
+
+func (db *SQLiteDB) CreateProjectClient(pc model.ProjectClient) (*model.ProjectClient, error) {
+	pc.ClientID = newID()
+	_, err := db.Exec("INSERT INTO project_clients (client_id, project_id, name, secret, can_add_keys) VALUES(?, ?, ?, ?, ?)",
+		pc.ClientID, pc.ProjectID, pc.Name, pc.Secret, pc.CanAddKeys)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &pc, nil
+}
+
+func (db *SQLiteDB) DeleteProjectClient(projectID, clientID string) error {
+	_, err := db.Exec("DELETE FROM project_clients WHERE project_id = ? AND client_id = ?", projectID, clientID)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) UpdateProjectClientSecret(pc model.ProjectClient) (*model.ProjectClient, error) {
+	_, err := db.Exec("UPDATE project_clients SET secret = ? WHERE project_id = ? AND client_id = ?",
+		pc.Secret, pc.ProjectID, pc.ClientID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectClient(pc.ProjectID, pc.ClientID)
+}
+
+func (db *SQLiteDB) UpdateProjectClientName(pc model.ProjectClient) (*model.ProjectClient, error) {
+	_, err := db.Exec("UPDATE project_clients SET name = ? WHERE project_id = ? AND client_id = ?",
+		pc.Name, pc.ProjectID, pc.ClientID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectClient(pc.ProjectID, pc.ClientID)
+}
+
+func (db *SQLiteDB) UpdateProjectClientCanAddKeys(pc model.ProjectClient) (*model.ProjectClient, error) {
+	_, err := db.Exec("UPDATE project_clients SET can_add_keys = ? WHERE project_id = ? AND client_id = ?",
+		pc.CanAddKeys, pc.ProjectID, pc.ClientID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectClient(pc.ProjectID, pc.ClientID)
+}
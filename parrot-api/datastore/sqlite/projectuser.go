@@ -0,0 +1,146 @@
+package sqlite
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+// projectSortColumns and projectUserSortColumns map the sort fields to their columns.
+var (
+	projectSortColumns     = map[string]string{"name": "projects.name", "id": "projects.id"}
+	projectUserSortColumns = map[string]string{"name": "users.name", "email": "users.email"}
+)
+
+func (db *SQLiteDB) GetUserProjects(userID string, opts model.ListOptions) ([]model.Project, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectSortFields)
+	cond, clauses, args := keyset(opts, projectSortColumns[opts.Sort], "projects.id", []interface{}{userID})
+	rows, err := db.Query(`SELECT `+projectColumns+`
+							FROM projects
+							JOIN projects_users ON projects.id = projects_users.project_id
+							WHERE projects_users.user_id = ? AND `+cond+` `+clauses, args...)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	defer rows.Close()
+
+	projects := make([]model.Project, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, nil, parseError(err)
+		}
+
+		projects = append(projects, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	next := opts.NextCursor(len(projects), func(i int) (string, string) {
+		return projects[i].SortValue(opts.Sort), projects[i].ID
+	})
+	if next != nil {
+		projects = projects[:opts.Limit]
+	}
+
+	return projects, next, nil
+}
+
+func (db *SQLiteDB) GetProjectUsers(projID string, opts model.ListOptions) ([]model.ProjectUser, *model.Cursor, error) {
+	opts.Sort = opts.SortField(model.ProjectUserSortFields)
+	cond, clauses, args := keyset(opts, projectUserSortColumns[opts.Sort], "projects_users.user_id", []interface{}{projID})
+	rows, err := db.Query(`SELECT user_id, project_id, users.email, users.name, role
+							FROM users
+							JOIN projects_users ON users.id = projects_users.user_id
+							WHERE projects_users.project_id = ? AND `+cond+` `+clauses, args...)
+	if err != nil {
+		return nil, nil, parseError(err)
+	}
+	defer rows.Close()
+
+	users := make([]model.ProjectUser, 0)
+	for rows.Next() {
+		u := model.ProjectUser{}
+
+		err := rows.Scan(&u.UserID, &u.ProjectID, &u.Email, &u.Name, &u.Role)
+		if err != nil {
+			return nil, nil, parseError(err)
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, parseError(err)
+	}
+
+	next := opts.NextCursor(len(users), func(i int) (string, string) {
+		return users[i].SortValue(opts.Sort), users[i].UserID
+	})
+	if next != nil {
+		users = users[:opts.Limit]
+	}
+
+	return users, next, nil
+}
+
+func (db *SQLiteDB) GetUserProjectRoles(userID string) ([]model.ProjectUser, error) {
+	rows, err := db.Query(`SELECT user_id, project_id, role
+							FROM projects_users
+							WHERE projects_users.user_id = ?`, userID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	defer rows.Close()
+
+	roles := make([]model.ProjectUser, 0)
+	for rows.Next() {
+		u := model.ProjectUser{}
+
+		err := rows.Scan(&u.UserID, &u.ProjectID, &u.Role)
+		if err != nil {
+			return nil, parseError(err)
+		}
+		roles = append(roles, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, parseError(err)
+	}
+
+	return roles, nil
+}
+
+func (db *SQLiteDB) GetProjectUser(projID, userID string) (*model.ProjectUser, error) {
+	u := model.ProjectUser{}
+	row := db.QueryRow(`SELECT user_id, project_id, users.email, users.name, role
+							FROM users
+							JOIN projects_users ON users.id = projects_users.user_id
+							WHERE projects_users.project_id = ? AND user_id = ?`, projID, userID)
+	err := row.Scan(&u.UserID, &u.ProjectID, &u.Email, &u.Name, &u.Role)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &u, nil
+}
+
+func (db *SQLiteDB) AssignProjectUser(pu model.ProjectUser) (*model.ProjectUser, error) {
+	_, err := db.Exec("INSERT INTO projects_users (project_id, user_id, role) VALUES(?, ?, ?)",
+		pu.ProjectID, pu.UserID, pu.Role)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectUser(pu.ProjectID, pu.UserID)
+}
+
+func (db *SQLiteDB) RevokeProjectUser(pu model.ProjectUser) error {
+	_, err := db.Exec("DELETE FROM projects_users WHERE project_id = ? AND user_id = ?",
+		pu.ProjectID, pu.UserID)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) UpdateProjectUser(pu model.ProjectUser) (*model.ProjectUser, error) {
+	_, err := db.Exec("UPDATE projects_users SET role = ? WHERE project_id = ? AND user_id = ?",
+		pu.Role, pu.ProjectID, pu.UserID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return db.GetProjectUser(pu.ProjectID, pu.UserID)
+}
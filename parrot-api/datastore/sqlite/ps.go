@@ -0,0 +1,151 @@
+// Package sqlite holds an implementation of the datastore.Store backed by a SQLite
+// database file, so that Parrot can be self-hosted as a single binary.
+package sqlite
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	// registers the sqlite3 database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteDB implements the datastore.Store interface for a SQLite Database.
+type SQLiteDB struct {
+	*sql.DB
+}
+
+// Open opens the SQLite database at url, a file path or ':memory:', with foreign keys enforced.
+func Open(url string) (*SQLiteDB, error) {
+	sep := "?"
+	if strings.Contains(url, "?") {
+		sep = "&"
+	}
+
+	// Immediate transactions take the write lock when they begin instead of failing on their first write
+	conn, err := sql.Open("sqlite3", url+sep+"_foreign_keys=on&_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer, and every connection to ':memory:' opens a new database
+	conn.SetMaxOpenConns(1)
+
+	return &SQLiteDB{DB: conn}, nil
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// transact runs fn in a transaction, committed if fn succeeds.
+func (db *SQLiteDB) transact(fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// newID returns a random (version 4) UUID, the same format used for ids by Postgres.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// now returns the current time in UTC, so that stored timestamps compare as text.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// jsonValue encodes arrays and maps, which are stored as JSON text.
+func jsonValue(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// stringsValue encodes a string array as JSON text, never null.
+func stringsValue(s []string) (string, error) {
+	if s == nil {
+		s = []string{}
+	}
+	return jsonValue(s)
+}
+
+// pairsValue encodes a string map as JSON text, never null.
+func pairsValue(m map[string]string) (string, error) {
+	if m == nil {
+		m = map[string]string{}
+	}
+	return jsonValue(m)
+}
+
+// unmarshalStrings decodes a string array stored as JSON text, never nil.
+func unmarshalStrings(data string) ([]string, error) {
+	s := make([]string, 0)
+	if data == "" {
+		return s, nil
+	}
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// unmarshalPairs decodes a string map stored as JSON text, never nil.
+func unmarshalPairs(data string) (map[string]string, error) {
+	m := make(map[string]string)
+	if data == "" {
+		return m, nil
+	}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// contains returns true if s holds v.
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns s without v.
+func remove(s []string, v string) []string {
+	result := make([]string, 0, len(s))
+	for _, e := range s {
+		if e != v {
+			result = append(result, e)
+		}
+	}
+	return result
+}
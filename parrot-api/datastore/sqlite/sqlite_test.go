@@ -0,0 +1,22 @@
+package sqlite_test
+
+import (
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/sqlite"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/storetest"
+)
+
+func TestStore(t *testing.T) {
+	db, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.MigrateUp("migrations"); err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, db)
+}
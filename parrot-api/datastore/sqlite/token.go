@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// refreshTokenColumns lists the refresh token columns in the order expected by scanRefreshToken.
+const refreshTokenColumns = "id, token_hash, family_id, subject_id, subject_type, expires_at, created_at, revoked_at"
+
+func (db *SQLiteDB) CreateRefreshToken(token model.RefreshToken) (*model.RefreshToken, error) {
+	if token.FamilyID == "" {
+		token.FamilyID = newID()
+	}
+
+	result, err := insertRefreshToken(db, token)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	return getRefreshToken(db, tokenHash)
+}
+
+func (db *SQLiteDB) RotateRefreshToken(oldTokenHash string, token model.RefreshToken) (*model.RefreshToken, error) {
+	var result *model.RefreshToken
+	err := db.transact(func(tx *sql.Tx) error {
+		// Only an active token can be rotated, so concurrent uses of the same token can't both succeed
+		current := now()
+		res, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE token_hash = ? AND revoked_at IS NULL AND expires_at > ?",
+			current, oldTokenHash, current)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errors.ErrNotFound
+		}
+
+		result, err = insertRefreshToken(tx, token)
+		return err
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) RevokeRefreshTokenFamily(familyID string) error {
+	_, err := db.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", now(), familyID)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) RevokeSubjectRefreshTokens(subjectID string) error {
+	_, err := db.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE subject_id = ? AND revoked_at IS NULL", now(), subjectID)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	_, err := db.Exec("INSERT OR IGNORE INTO revoked_tokens (token_id, expires_at) VALUES(?, ?)", tokenID, expiresAt.UTC())
+	if err != nil {
+		return parseError(err)
+	}
+
+	// Expired tokens are rejected anyway, no need to keep them around
+	_, err = db.Exec("DELETE FROM revoked_tokens WHERE expires_at < ?", now())
+	return parseError(err)
+}
+
+func (db *SQLiteDB) RevokeSubjectAccessTokens(subjectID string, issuedBefore, expiresAt time.Time) error {
+	_, err := db.Exec(`INSERT INTO revoked_subjects (subject_id, issued_before, expires_at) VALUES(?, ?, ?)
+						ON CONFLICT (subject_id) DO UPDATE SET issued_before = excluded.issued_before, expires_at = excluded.expires_at`,
+		subjectID, issuedBefore.UTC(), expiresAt.UTC())
+	if err != nil {
+		return parseError(err)
+	}
+
+	_, err = db.Exec("DELETE FROM revoked_subjects WHERE expires_at < ?", now())
+	return parseError(err)
+}
+
+func (db *SQLiteDB) IsAccessTokenRevoked(tokenID, subjectID string, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = ? AND token_id != '')
+						OR EXISTS (SELECT 1 FROM revoked_subjects WHERE subject_id = ? AND issued_before > ?)`,
+		tokenID, subjectID, issuedAt.UTC()).Scan(&revoked)
+	if err != nil {
+		return false, parseError(err)
+	}
+
+	return revoked, nil
+}
+
+func insertRefreshToken(db querier, token model.RefreshToken) (*model.RefreshToken, error) {
+	_, err := db.Exec(`INSERT INTO refresh_tokens (id, token_hash, family_id, subject_id, subject_type, expires_at, created_at)
+						VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		newID(), token.TokenHash, token.FamilyID, token.SubjectID, token.SubjectType, token.ExpiresAt.UTC(), now())
+	if err != nil {
+		return nil, err
+	}
+
+	return getRefreshToken(db, token.TokenHash)
+}
+
+func getRefreshToken(db querier, tokenHash string) (*model.RefreshToken, error) {
+	row := db.QueryRow("SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash = ?", tokenHash)
+	result, err := scanRefreshToken(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// scanRefreshToken scans a refresh token from a single result row selected with refreshTokenColumns.
+func scanRefreshToken(row scanner) (*model.RefreshToken, error) {
+	t := model.RefreshToken{}
+	revokedAt := sql.NullTime{}
+
+	err := row.Scan(&t.ID, &t.TokenHash, &t.FamilyID, &t.SubjectID, &t.SubjectType, &t.ExpiresAt, &t.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+
+	return &t, nil
+}
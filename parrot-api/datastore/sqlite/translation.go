@@ -0,0 +1,44 @@
+package sqlite
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+// translationConfigColumns lists the translation config columns in the order expected by scanTranslationConfig.
+const translationConfigColumns = "project_id, provider, url, api_key, updated_at"
+
+func (db *SQLiteDB) GetTranslationConfig(projectID string) (*model.TranslationConfig, error) {
+	row := db.QueryRow("SELECT "+translationConfigColumns+" FROM translation_configs WHERE project_id = ?", projectID)
+	result, err := scanTranslationConfig(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) SetTranslationConfig(c model.TranslationConfig) (*model.TranslationConfig, error) {
+	_, err := db.Exec(`INSERT INTO translation_configs (project_id, provider, url, api_key, updated_at)
+						VALUES(?, ?, ?, ?, ?)
+						ON CONFLICT (project_id) DO UPDATE SET provider = excluded.provider, url = excluded.url,
+						api_key = excluded.api_key, updated_at = excluded.updated_at`,
+		c.ProjectID, c.Provider, c.URL, c.APIKey, now())
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return db.GetTranslationConfig(c.ProjectID)
+}
+
+func (db *SQLiteDB) DeleteTranslationConfig(projectID string) error {
+	_, err := db.Exec("DELETE FROM translation_configs WHERE project_id = ?", projectID)
+	return parseError(err)
+}
+
+// scanTranslationConfig scans a translation config from a single result row selected with translationConfigColumns.
+func scanTranslationConfig(row scanner) (*model.TranslationConfig, error) {
+	c := model.TranslationConfig{}
+	err := row.Scan(&c.ProjectID, &c.Provider, &c.URL, &c.APIKey, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
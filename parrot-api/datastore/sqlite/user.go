@@ -0,0 +1,64 @@
+package sqlite
+
+import "github.com/iris-contrib/parrot/parrot-api/model"
+
+func (db *SQLiteDB) GetUserByEmail(email string) (*model.User, error) {
+	u := model.User{}
+	row := db.QueryRow("SELECT id, name, email, password FROM users WHERE email = ?", email)
+
+	err := row.Scan(&u.ID, &u.Name, &u.Email, &u.Password)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return &u, nil
+}
+
+func (db *SQLiteDB) GetUserByID(id string) (*model.User, error) {
+	u := model.User{}
+	row := db.QueryRow("SELECT id, name, email, password FROM users WHERE id = ?", id)
+
+	err := row.Scan(&u.ID, &u.Name, &u.Email, &u.Password)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return &u, nil
+}
+
+func (db *SQLiteDB) CreateUser(u model.User) (*model.User, error) {
+	u.ID = newID()
+	_, err := db.Exec("INSERT INTO users (id, name, email, password) VALUES(?, ?, ?, ?)", u.ID, u.Name, u.Email, u.Password)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &u, nil
+}
+
+func (db *SQLiteDB) UpdateUserPassword(u model.User) (*model.User, error) {
+	return db.updateUser(u, "UPDATE users SET password = ? WHERE id = ?", u.Password)
+}
+
+func (db *SQLiteDB) UpdateUserName(u model.User) (*model.User, error) {
+	return db.updateUser(u, "UPDATE users SET name = ? WHERE id = ?", u.Name)
+}
+
+func (db *SQLiteDB) UpdateUserEmail(u model.User) (*model.User, error) {
+	return db.updateUser(u, "UPDATE users SET email = ? WHERE id = ?", u.Email)
+}
+
+// updateUser sets the value of a single column of the user with the id of u. Like the Postgres
+// implementation, it returns the stored id, name and email along with the password of u.
+func (db *SQLiteDB) updateUser(u model.User, query string, value string) (*model.User, error) {
+	_, err := db.Exec(query, value, u.ID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	row := db.QueryRow("SELECT id, name, email FROM users WHERE id = ?", u.ID)
+	err = row.Scan(&u.ID, &u.Name, &u.Email)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	return &u, nil
+}
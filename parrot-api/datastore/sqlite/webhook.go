@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// webhookColumns lists the webhook columns in the order expected by scanWebhook.
+const webhookColumns = "id, project_id, url, secret, events, active, created_at"
+
+// deliveryColumns lists the delivery columns in the order expected by scanDelivery.
+const deliveryColumns = `id, webhook_id, project_id, event, payload, status, attempts, next_attempt_at,
+						last_status_code, last_error, created_at, delivered_at`
+
+func (db *SQLiteDB) GetProjectWebhooks(projectID string) ([]model.Webhook, error) {
+	result, err := getProjectWebhooks(db, projectID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) GetProjectWebhook(projectID, webhookID string) (*model.Webhook, error) {
+	row := db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = ? AND id = ?", projectID, webhookID)
+	result, err := scanWebhook(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) CreateWebhook(w model.Webhook) (*model.Webhook, error) {
+	events, err := stringsValue(w.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	id := newID()
+	_, err = db.Exec("INSERT INTO webhooks (id, project_id, url, secret, events, active, created_at) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		id, w.ProjectID, w.URL, w.Secret, events, w.Active, now())
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return db.GetProjectWebhook(w.ProjectID, id)
+}
+
+func (db *SQLiteDB) UpdateWebhook(w model.Webhook) (*model.Webhook, error) {
+	events, err := stringsValue(w.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec("UPDATE webhooks SET url = ?, secret = ?, events = ?, active = ? WHERE project_id = ? AND id = ?",
+		w.URL, w.Secret, events, w.Active, w.ProjectID, w.ID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return db.GetProjectWebhook(w.ProjectID, w.ID)
+}
+
+func (db *SQLiteDB) DeleteWebhook(projectID, webhookID string) error {
+	_, err := db.Exec("DELETE FROM webhooks WHERE project_id = ? AND id = ?", projectID, webhookID)
+	return parseError(err)
+}
+
+func (db *SQLiteDB) CreateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	id, err := insertDelivery(db, d)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return getDelivery(db, id)
+}
+
+func (db *SQLiteDB) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	var result []model.WebhookDelivery
+	err := db.transact(func(tx *sql.Tx) error {
+		current := now()
+		rows, err := tx.Query("SELECT "+deliveryColumns+` FROM webhook_deliveries
+							WHERE status = ? AND next_attempt_at <= ?
+							ORDER BY next_attempt_at
+							LIMIT ?`,
+			model.DeliveryPending, current, limit)
+		if err != nil {
+			return err
+		}
+		result, err = scanDeliveries(rows)
+		if err != nil {
+			return err
+		}
+
+		// The transaction holds the write lock, so concurrent claims wait for it
+		for i := range result {
+			result[i].NextAttemptAt = current.Add(lease)
+			_, err := tx.Exec("UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?", result[i].NextAttemptAt, result[i].ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func (db *SQLiteDB) UpdateWebhookDelivery(d model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	var deliveredAt interface{}
+	if d.DeliveredAt != nil {
+		deliveredAt = d.DeliveredAt.UTC()
+	}
+
+	_, err := db.Exec(`UPDATE webhook_deliveries
+						SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?
+						WHERE id = ?`,
+		d.Status, d.Attempts, d.NextAttemptAt.UTC(), d.LastStatusCode, d.LastError, deliveredAt, d.ID)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return getDelivery(db, d.ID)
+}
+
+func (db *SQLiteDB) GetWebhookDeliveries(webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := db.Query("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC, rowid DESC LIMIT ?",
+		webhookID, limit)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	result, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+func getProjectWebhooks(db querier, projectID string) ([]model.Webhook, error) {
+	rows, err := db.Query("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = ? ORDER BY created_at, id", projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]model.Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *w)
+	}
+
+	return result, rows.Err()
+}
+
+// insertDelivery stores the delivery as pending and returns its id.
+func insertDelivery(db querier, d model.WebhookDelivery) (string, error) {
+	id := newID()
+	_, err := db.Exec(`INSERT INTO webhook_deliveries (id, webhook_id, project_id, event, payload, status, next_attempt_at, created_at)
+						VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, d.WebhookID, d.ProjectID, d.Event, d.Payload, model.DeliveryPending, d.NextAttemptAt.UTC(), now())
+	return id, err
+}
+
+// enqueueEvents stores the deliveries of the events to the webhooks subscribed to them.
+func enqueueEvents(db querier, events []model.Event) error {
+	webhooks := make(map[string][]model.Webhook)
+	for _, e := range events {
+		projectWebhooks, ok := webhooks[e.ProjectID]
+		if !ok {
+			var err error
+			if projectWebhooks, err = getProjectWebhooks(db, e.ProjectID); err != nil {
+				return err
+			}
+			webhooks[e.ProjectID] = projectWebhooks
+		}
+
+		deliveries, err := e.Deliveries(projectWebhooks)
+		if err != nil {
+			return err
+		}
+		for _, d := range deliveries {
+			if _, err := insertDelivery(db, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func getDelivery(db querier, id string) (*model.WebhookDelivery, error) {
+	row := db.QueryRow("SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = ?", id)
+	result, err := scanDelivery(row)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return result, nil
+}
+
+// scanWebhook scans a webhook from a single result row selected with webhookColumns.
+func scanWebhook(row scanner) (*model.Webhook, error) {
+	w := model.Webhook{}
+	var events string
+
+	err := row.Scan(&w.ID, &w.ProjectID, &w.URL, &w.Secret, &events, &w.Active, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Events, err = unmarshalStrings(events); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// scanDelivery scans a delivery from a single result row selected with deliveryColumns.
+func scanDelivery(row scanner) (*model.WebhookDelivery, error) {
+	d := model.WebhookDelivery{}
+	deliveredAt := sql.NullTime{}
+
+	err := row.Scan(&d.ID, &d.WebhookID, &d.ProjectID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &d.CreatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &d, nil
+}
+
+// scanDeliveries scans and closes the rows.
+func scanDeliveries(rows *sql.Rows) ([]model.WebhookDelivery, error) {
+	defer rows.Close()
+
+	result := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *d)
+	}
+
+	return result, rows.Err()
+}
@@ -5,7 +5,10 @@ import (
 	"database/sql"
 
 	dbErrors "github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/migrate"
 	"github.com/iris-contrib/parrot/parrot-api/datastore/postgres"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/sqlite"
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
@@ -17,10 +20,18 @@ type Store interface {
 	model.UserStorer
 	model.ProjectUserStorer
 	model.ProjectClientStorer
+	model.HistoryStorer
+	model.KeyStorer
+	model.TokenStorer
+	model.WebhookStorer
+	model.SearchStorer
+	model.TranslationConfigStorer
 	Ping() error
 	Close() error
 	MigrateUp(string) error
 	MigrateDown(string) error
+	MigrateTo(dir string, version int64) error
+	MigrationStatus(dir string) ([]migrate.Status, error)
 }
 
 // Datastore is the provided Store implementation.
@@ -30,7 +41,7 @@ type Datastore struct {
 
 // NewDatastore creates and configures a new datastore based on the
 // parameter name and the connection url.
-// Currently only 'postgres' is supported.
+// Supported names are 'postgres', 'sqlite' and 'memory', the latter ignores the url.
 func NewDatastore(name string, url string) (*Datastore, error) {
 	var ds *Datastore
 
@@ -47,6 +58,15 @@ func NewDatastore(name string, url string) (*Datastore, error) {
 		p.SetMaxOpenConns(1)
 
 		ds = &Datastore{p}
+	case "sqlite":
+		s, err := sqlite.Open(url)
+		if err != nil {
+			return nil, err
+		}
+
+		ds = &Datastore{s}
+	case "memory":
+		ds = &Datastore{memory.New()}
 	default:
 		return nil, dbErrors.ErrNotImplemented
 	}
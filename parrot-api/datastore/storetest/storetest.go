@@ -0,0 +1,1244 @@
+// Package storetest holds a conformance test suite that every datastore.Store
+// implementation must pass, so that backends can be swapped without behaviour changes.
+package storetest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore"
+	"github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// missingID is a well formed id that no entry has.
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+// Run runs the conformance suite against the store. Every test creates its own
+// entries with unique names, so the store doesn't need to be empty.
+func Run(t *testing.T, store datastore.Store) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, datastore.Store)
+	}{
+		{"Users", testUsers},
+		{"ProjectKeys", testProjectKeys},
+		{"RenameProjectKey", testRenameProjectKey},
+		{"Locales", testLocales},
+		{"LocalePages", testLocalePages},
+		{"LocaleStatuses", testLocaleStatuses},
+		{"LocaleVersions", testLocaleVersions},
+		{"Search", testSearch},
+		{"UpdateLocales", testUpdateLocales},
+		{"ImportLocale", testImportLocale},
+		{"ProjectUsers", testProjectUsers},
+		{"ProjectClients", testProjectClients},
+		{"ProjectClientPages", testProjectClientPages},
+		{"History", testHistory},
+		{"Journal", testJournal},
+		{"KeyMeta", testKeyMeta},
+		{"RefreshTokens", testRefreshTokens},
+		{"RevokedAccessTokens", testRevokedAccessTokens},
+		{"RevokeSubjectRefreshTokens", testRevokeSubjectRefreshTokens},
+		{"Webhooks", testWebhooks},
+		{"WebhookEvents", testWebhookEvents},
+		{"TranslationConfig", testTranslationConfig},
+		{"DeleteProject", testDeleteProject},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, store)
+		})
+	}
+}
+
+func testUsers(t *testing.T, store datastore.Store) {
+	email := unique("user") + "@example.com"
+	u, err := store.CreateUser(model.User{Name: "Jane", Email: email, Password: "hash"})
+	mustNotFail(t, err)
+	if u.ID == "" || u.Email != email {
+		t.Fatalf("unexpected created user %+v", u)
+	}
+
+	_, err = store.CreateUser(model.User{Name: "Other", Email: email, Password: "hash"})
+	expectError(t, err, errors.ErrAlreadyExists)
+
+	found, err := store.GetUserByEmail(email)
+	mustNotFail(t, err)
+	if found.ID != u.ID || found.Password != "hash" {
+		t.Errorf("expected user %s with its password, got %+v", u.ID, found)
+	}
+
+	updated, err := store.UpdateUserName(model.User{ID: u.ID, Name: "Janet"})
+	mustNotFail(t, err)
+	if updated.Name != "Janet" || updated.Email != email {
+		t.Errorf("unexpected updated user %+v", updated)
+	}
+
+	other, err := store.CreateUser(model.User{Name: "Other", Email: unique("user") + "@example.com", Password: "hash"})
+	mustNotFail(t, err)
+	_, err = store.UpdateUserEmail(model.User{ID: other.ID, Email: email})
+	expectError(t, err, errors.ErrAlreadyExists)
+
+	_, err = store.UpdateUserPassword(model.User{ID: u.ID, Password: "new"})
+	mustNotFail(t, err)
+	found, err = store.GetUserByID(u.ID)
+	mustNotFail(t, err)
+	if found.Password != "new" {
+		t.Errorf("expected password to be updated, got %q", found.Password)
+	}
+
+	_, err = store.GetUserByID(missingID)
+	expectError(t, err, errors.ErrNotFound)
+	_, err = store.UpdateUserName(model.User{ID: missingID, Name: "Nobody"})
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testProjectKeys(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a", "b")
+	if len(p.PluralKeys) != 0 || len(p.Fallbacks) != 0 {
+		t.Errorf("expected new project without plural keys and fallbacks, got %+v", p)
+	}
+
+	p, err := store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "c"}, nil)
+	mustNotFail(t, err)
+	expectStrings(t, p.Keys, "a", "b", "c")
+
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "c"}, nil)
+	expectError(t, err, errors.ErrAlreadyExists)
+	_, err = store.AddProjectKey(model.Key{ProjectID: missingID, Key: "c"}, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	p, err = store.SetProjectKeyPlural(p.ID, "b", true, nil)
+	mustNotFail(t, err)
+	expectStrings(t, p.PluralKeys, "b")
+	_, err = store.SetProjectKeyPlural(p.ID, "x", true, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	p, err = store.DeleteProjectKey(p.ID, "b", nil)
+	mustNotFail(t, err)
+	expectStrings(t, p.Keys, "a", "c")
+	expectStrings(t, p.PluralKeys)
+	_, err = store.DeleteProjectKey(p.ID, "b", nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	p, err = store.UpdateProject(model.Project{ID: p.ID, Keys: []string{"a", "d"}, PluralKeys: []string{"d"}}, nil)
+	mustNotFail(t, err)
+	expectStrings(t, p.Keys, "a", "d")
+	expectStrings(t, p.PluralKeys, "d")
+
+	p, err = store.UpdateProjectName(p.ID, "renamed")
+	mustNotFail(t, err)
+	if p.Name != "renamed" {
+		t.Errorf("expected project name 'renamed', got %q", p.Name)
+	}
+	_, err = store.UpdateProjectName(missingID, "renamed")
+	expectError(t, err, errors.ErrNotFound)
+
+	fallbacks := map[string][]string{"fr_CA": {"fr_FR"}}
+	p, err = store.UpdateProjectFallbacks(p.ID, fallbacks)
+	mustNotFail(t, err)
+	if !reflect.DeepEqual(p.Fallbacks, fallbacks) {
+		t.Errorf("expected fallbacks %v, got %v", fallbacks, p.Fallbacks)
+	}
+
+	p, err = store.UpdateProjectSourceLocale(p.ID, "en_US")
+	mustNotFail(t, err)
+	p, err = store.UpdateProjectName(p.ID, "renamed again")
+	mustNotFail(t, err)
+	if p.SourceLocale != "en_US" {
+		t.Errorf("expected source locale 'en_US', got %q", p.SourceLocale)
+	}
+	_, err = store.UpdateProjectSourceLocale(missingID, "en_US")
+	expectError(t, err, errors.ErrNotFound)
+
+	if p.KeyDelimiter != model.DefaultKeyDelimiter {
+		t.Errorf("expected default key delimiter %q, got %q", model.DefaultKeyDelimiter, p.KeyDelimiter)
+	}
+	p, err = store.UpdateProjectKeyDelimiter(p.ID, "::")
+	mustNotFail(t, err)
+	if p.KeyDelimiter != "::" {
+		t.Errorf("expected key delimiter '::', got %q", p.KeyDelimiter)
+	}
+	_, err = store.UpdateProjectKeyDelimiter(missingID, "::")
+	expectError(t, err, errors.ErrNotFound)
+
+	_, err = store.GetProject(missingID)
+	expectError(t, err, errors.ErrNotFound)
+
+	projects, err := store.GetProjects()
+	mustNotFail(t, err)
+	found := false
+	for _, v := range projects {
+		found = found || v.ID == p.ID
+	}
+	if !found {
+		t.Errorf("expected project %s to be listed", p.ID)
+	}
+}
+
+func testRenameProjectKey(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "old", "other")
+	_, err := store.SetProjectKeyPlural(p.ID, "old", true, nil)
+	mustNotFail(t, err)
+
+	createLocale(t, store, p.ID, "en_US", map[string]string{"old": "Old", "other": "Other"})
+	createLocale(t, store, p.ID, "de_DE", map[string]string{"other": "Andere"})
+	_, err = store.UpdateLocalePlurals(p.ID, "en_US", map[string]model.PluralForms{"old": {"one": "1 old", "other": "n old"}}, 0, nil)
+	mustNotFail(t, err)
+	_, err = store.UpdateLocaleStatuses(p.ID, "en_US", map[string]string{"old": model.StatusApproved})
+	mustNotFail(t, err)
+
+	_, _, err = store.UpdateProjectKey(p.ID, "missing", "new", nil)
+	expectError(t, err, errors.ErrNotFound)
+	_, _, err = store.UpdateProjectKey(p.ID, "old", "other", nil)
+	expectError(t, err, errors.ErrAlreadyExists)
+
+	p, n, err := store.UpdateProjectKey(p.ID, "old", "new", nil)
+	mustNotFail(t, err)
+	if n != 2 {
+		t.Errorf("expected 2 updated locales, got %d", n)
+	}
+	expectStrings(t, p.Keys, "new", "other")
+	expectStrings(t, p.PluralKeys, "new")
+
+	loc, err := store.GetProjectLocaleByIdent(p.ID, "en_US")
+	mustNotFail(t, err)
+	expectPairs(t, loc.Pairs, map[string]string{"new": "Old", "other": "Other"})
+	expectPairs(t, loc.Statuses, map[string]string{"new": model.StatusApproved})
+	if _, ok := loc.Plurals["new"]; !ok || len(loc.Plurals) != 1 {
+		t.Errorf("expected plural forms to move to the new key, got %v", loc.Plurals)
+	}
+
+	loc, err = store.GetProjectLocaleByIdent(p.ID, "de_DE")
+	mustNotFail(t, err)
+	expectPairs(t, loc.Pairs, map[string]string{"other": "Andere"})
+
+	k, err := store.GetProjectKey(p.ID, "new")
+	mustNotFail(t, err)
+	if !k.Plural {
+		t.Errorf("expected renamed key to stay plural")
+	}
+	_, err = store.GetProjectKey(p.ID, "old")
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testLocales(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+
+	loc := createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A"})
+	if loc.ID == "" {
+		t.Errorf("expected created locale to have an id")
+	}
+	createLocale(t, store, p.ID, "de_DE", nil)
+
+	_, err := store.CreateLocale(model.Locale{Ident: "en_US", Language: "English", Country: "US", ProjectID: p.ID}, nil)
+	expectError(t, err, errors.ErrAlreadyExists)
+	_, err = store.CreateLocale(model.Locale{Ident: "en_US", Language: "English", Country: "US", ProjectID: missingID}, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	locs, err := store.GetProjectLocales(p.ID)
+	mustNotFail(t, err)
+	if len(locs) != 2 {
+		t.Errorf("expected 2 locales, got %d", len(locs))
+	}
+	locs, err = store.GetProjectLocales(p.ID, "de_DE")
+	mustNotFail(t, err)
+	if len(locs) != 1 || locs[0].Ident != "de_DE" {
+		t.Errorf("expected only locale de_DE, got %v", locs)
+	}
+	locs, err = store.GetProjectLocales(missingID)
+	mustNotFail(t, err)
+	if len(locs) != 0 {
+		t.Errorf("expected no locales for a missing project, got %d", len(locs))
+	}
+
+	updated, err := store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"a": "AA"}, 0, nil)
+	mustNotFail(t, err)
+	expectPairs(t, updated.Pairs, map[string]string{"a": "AA"})
+	_, err = store.UpdateLocalePairs(p.ID, "fr_FR", map[string]string{"a": "AA"}, 0, nil)
+	expectError(t, err, errors.ErrNotFound)
+	_, err = store.UpdateLocalePlurals(p.ID, "fr_FR", nil, 0, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	mustNotFail(t, store.DeleteLocale(p.ID, "en_US", nil))
+	_, err = store.GetProjectLocaleByIdent(p.ID, "en_US")
+	expectError(t, err, errors.ErrNotFound)
+	mustNotFail(t, store.DeleteLocale(p.ID, "en_US", nil))
+}
+
+func testLocalePages(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+	for _, ident := range []string{"de_DE", "en_US", "es_ES", "fr_FR", "it_IT"} {
+		createLocale(t, store, p.ID, ident, map[string]string{"a": ident})
+	}
+
+	var idents []string
+	opts := model.ListOptions{Limit: 2, Sort: "ident", Desc: true}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("expected 3 pages, got more")
+		}
+		locs, next, err := store.FindProjectLocales(p.ID, model.LocaleQuery{ListOptions: opts})
+		mustNotFail(t, err)
+		for _, loc := range locs {
+			idents = append(idents, loc.Ident)
+		}
+		if next == nil {
+			break
+		}
+		opts.After = next
+	}
+	expected := []string{"it_IT", "fr_FR", "es_ES", "en_US", "de_DE"}
+	if !reflect.DeepEqual(idents, expected) {
+		t.Errorf("expected locales %v, got %v", expected, idents)
+	}
+
+	locs, next, err := store.FindProjectLocales(p.ID, model.LocaleQuery{
+		ListOptions:  model.ListOptions{Limit: 2},
+		Idents:       []string{"fr_FR", "de_DE"},
+		WithoutPairs: true,
+	})
+	mustNotFail(t, err)
+	if next != nil || len(locs) != 2 || locs[0].Ident != "de_DE" || locs[1].Ident != "fr_FR" {
+		t.Fatalf("expected locales de_DE and fr_FR on a single page, got %v", locs)
+	}
+	if len(locs[0].Pairs) != 0 {
+		t.Errorf("expected pairs not to be loaded, got %v", locs[0].Pairs)
+	}
+}
+
+func testLocaleStatuses(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a", "b", "c")
+	createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A", "b": "B", "c": "C"})
+
+	loc, err := store.UpdateLocaleStatuses(p.ID, "en_US", map[string]string{
+		"a": model.StatusApproved,
+		"b": model.StatusNeedsReview,
+		"c": model.StatusApproved,
+	})
+	mustNotFail(t, err)
+	expectPairs(t, loc.Statuses, map[string]string{"a": model.StatusApproved, "b": model.StatusNeedsReview, "c": model.StatusApproved})
+
+	loc, err = store.UpdateLocaleStatuses(p.ID, "en_US", map[string]string{"c": model.StatusTranslated})
+	mustNotFail(t, err)
+	expectPairs(t, loc.Statuses, map[string]string{"a": model.StatusApproved, "b": model.StatusNeedsReview})
+
+	// Changed values lose their status, unchanged ones keep it
+	loc, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"a": "A", "b": "BB", "c": "C"}, 0, nil)
+	mustNotFail(t, err)
+	expectPairs(t, loc.Statuses, map[string]string{"a": model.StatusApproved})
+
+	_, err = store.UpdateLocaleStatuses(p.ID, "fr_FR", map[string]string{"a": model.StatusApproved})
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testLocaleVersions(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a", "b", "c")
+	created := createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A", "b": "B", "c": "C"})
+	if created.Version != 1 {
+		t.Errorf("expected a new locale at version 1, got %d", created.Version)
+	}
+	_, err := store.UpdateLocaleStatuses(p.ID, "en_US", map[string]string{"a": model.StatusApproved, "b": model.StatusApproved})
+	mustNotFail(t, err)
+
+	// Only the given values are set, the changed ones lose their status
+	loc, err := store.MergeLocalePairs(p.ID, "en_US", map[string]string{"a": "A", "b": "BB"}, 2, nil)
+	mustNotFail(t, err)
+	expectPairs(t, loc.Pairs, map[string]string{"a": "A", "b": "BB", "c": "C"})
+	expectPairs(t, loc.Statuses, map[string]string{"a": model.StatusApproved})
+	if loc.Version != 3 {
+		t.Errorf("expected version 3, got %d", loc.Version)
+	}
+
+	_, err = store.MergeLocalePairs(p.ID, "en_US", map[string]string{"c": "CC"}, 2, nil)
+	expectError(t, err, errors.ErrStaleVersion)
+	_, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"c": "CC"}, 2, nil)
+	expectError(t, err, errors.ErrStaleVersion)
+	_, err = store.UpdateLocalePlurals(p.ID, "en_US", nil, 2, nil)
+	expectError(t, err, errors.ErrStaleVersion)
+	_, err = store.MergeLocalePairs(p.ID, "fr_FR", map[string]string{"c": "CC"}, 1, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	loc, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"c": "CC"}, 3, nil)
+	mustNotFail(t, err)
+	expectPairs(t, loc.Pairs, map[string]string{"c": "CC"})
+	locs, err := store.UpdateLocales(p.ID, []string{"en_US"}, func(*model.Locale) error { return nil }, nil)
+	mustNotFail(t, err)
+	loc, err = store.GetProjectLocaleByIdent(p.ID, "en_US")
+	mustNotFail(t, err)
+	if loc.Version != 5 || locs[0].Version != 5 {
+		t.Errorf("expected version 5, got %d and %d", loc.Version, locs[0].Version)
+	}
+}
+
+func testSearch(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "checkout.title", "cart.total", "greeting")
+	createLocale(t, store, p.ID, "en_US", map[string]string{"checkout.title": "Checkout", "cart.total": "Total", "greeting": "Hello"})
+	createLocale(t, store, p.ID, "de_DE", map[string]string{"checkout.title": "Kasse", "cart.total": "", "greeting": "Hallo"})
+
+	tests := []struct {
+		query    model.SearchQuery
+		expected []model.SearchResult
+	}{
+		{
+			model.SearchQuery{Text: "Checkout"},
+			[]model.SearchResult{{Key: "checkout.title", Matches: []model.SearchMatch{{Locale: "en_US", Value: "Checkout"}}}},
+		},
+		{
+			model.SearchQuery{Text: "checkout", IgnoreCase: true},
+			[]model.SearchResult{{Key: "checkout.title", KeyMatched: true, Matches: []model.SearchMatch{{Locale: "en_US", Value: "Checkout"}}}},
+		},
+		{
+			model.SearchQuery{Text: "^Hal+o$", Regex: true},
+			[]model.SearchResult{{Key: "greeting", Matches: []model.SearchMatch{{Locale: "de_DE", Value: "Hallo"}}}},
+		},
+		{
+			// Go's syntax applies to values too, whatever the store's own regular expressions
+			model.SearchQuery{Text: `\bKass`, Regex: true},
+			[]model.SearchResult{{Key: "checkout.title", Matches: []model.SearchMatch{{Locale: "de_DE", Value: "Kasse"}}}},
+		},
+		{
+			model.SearchQuery{Text: `tot\w+`, Regex: true, IgnoreCase: true},
+			[]model.SearchResult{{Key: "cart.total", KeyMatched: true, Matches: []model.SearchMatch{{Locale: "en_US", Value: "Total"}}}},
+		},
+		{
+			model.SearchQuery{Text: "Total", Locales: []string{"de_DE"}},
+			[]model.SearchResult{},
+		},
+		{
+			model.SearchQuery{Untranslated: true},
+			[]model.SearchResult{{Key: "cart.total", Matches: []model.SearchMatch{{Locale: "de_DE"}}}},
+		},
+	}
+	for _, tt := range tests {
+		results, err := store.SearchProject(p.ID, tt.query)
+		mustNotFail(t, err)
+		if !reflect.DeepEqual(results, tt.expected) {
+			t.Errorf("search %+v: expected %+v, got %+v", tt.query, tt.expected, results)
+		}
+	}
+
+	_, err := store.SearchProject(missingID, model.SearchQuery{Text: "a"})
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testUpdateLocales(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+	createLocale(t, store, p.ID, "en_US", map[string]string{"a": "Acme"})
+	createLocale(t, store, p.ID, "de_DE", map[string]string{"a": "Acme"})
+
+	rename := func(loc *model.Locale) error {
+		loc.Pairs["a"] = "Globex"
+		return nil
+	}
+
+	_, err := store.UpdateLocales(p.ID, []string{"en_US", "fr_FR"}, rename, nil)
+	expectError(t, err, errors.ErrNotFound)
+
+	// Nothing is stored when fn fails for one of the locales
+	failure := fmt.Errorf("failure")
+	_, err = store.UpdateLocales(p.ID, []string{"en_US", "de_DE"}, func(loc *model.Locale) error {
+		if loc.Ident == "de_DE" {
+			return failure
+		}
+		return rename(loc)
+	}, nil)
+	expectError(t, err, failure)
+	loc, err := store.GetProjectLocaleByIdent(p.ID, "en_US")
+	mustNotFail(t, err)
+	expectPairs(t, loc.Pairs, map[string]string{"a": "Acme"})
+
+	locs, err := store.UpdateLocales(p.ID, []string{"en_US", "de_DE"}, rename, nil)
+	mustNotFail(t, err)
+	if len(locs) != 2 {
+		t.Fatalf("expected 2 updated locales, got %d", len(locs))
+	}
+	for _, ident := range []string{"en_US", "de_DE"} {
+		loc, err := store.GetProjectLocaleByIdent(p.ID, ident)
+		mustNotFail(t, err)
+		expectPairs(t, loc.Pairs, map[string]string{"a": "Globex"})
+	}
+}
+
+func testImportLocale(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+	createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A"})
+
+	// Keys added since the project was read are kept
+	_, err := store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "b"}, nil)
+	mustNotFail(t, err)
+
+	// Nothing is stored when fn fails
+	failed := fmt.Errorf("failed")
+	_, _, err = store.ImportLocale(p.ID, "en_US", []string{"c"}, nil, func(*model.Project, *model.Locale) error {
+		return failed
+	}, nil)
+	expectError(t, err, failed)
+	_, _, err = store.ImportLocale(p.ID, "fr_FR", []string{"c"}, nil, func(*model.Project, *model.Locale) error {
+		return nil
+	}, nil)
+	expectError(t, err, errors.ErrNotFound)
+	project, err := store.GetProject(p.ID)
+	mustNotFail(t, err)
+	expectStrings(t, project.Keys, "a", "b")
+
+	j := &model.Journal{SubjectID: "user", SubjectType: "user"}
+	loc, added, err := store.ImportLocale(p.ID, "en_US", []string{"a", "b", "c", "d"}, []string{"d"}, func(p *model.Project, loc *model.Locale) error {
+		expectStrings(t, p.Keys, "a", "b", "c", "d")
+		loc.SyncKeys(p.Keys)
+		loc.SetPairs(map[string]string{"a": "A", "b": "", "c": "C"})
+		return nil
+	}, j)
+	mustNotFail(t, err)
+	expectStrings(t, added, "c", "d")
+	expectPairs(t, loc.Pairs, map[string]string{"a": "A", "b": "", "c": "C"})
+
+	project, err = store.GetProject(p.ID)
+	mustNotFail(t, err)
+	expectStrings(t, project.Keys, "a", "b", "c", "d")
+	expectStrings(t, project.PluralKeys, "d")
+	var actions []string
+	for _, e := range j.Entries {
+		actions = append(actions, e.Action+" "+e.Key)
+	}
+	expectStrings(t, actions, "key_added c", "key_added d", "pair_updated c")
+}
+
+func testProjectUsers(t *testing.T, store datastore.Store) {
+	p := createProject(t, store)
+	u := createUser(t, store)
+
+	pu, err := store.AssignProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID, Role: "owner"})
+	mustNotFail(t, err)
+	if pu.Email != u.Email || pu.Name != u.Name || pu.Role != "owner" {
+		t.Errorf("unexpected project user %+v", pu)
+	}
+
+	_, err = store.AssignProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID, Role: "editor"})
+	expectError(t, err, errors.ErrAlreadyExists)
+	_, err = store.AssignProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: missingID, Role: "editor"})
+	expectError(t, err, errors.ErrNotFound)
+
+	pu, err = store.UpdateProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID, Role: "editor"})
+	mustNotFail(t, err)
+	if pu.Role != "editor" {
+		t.Errorf("expected role 'editor', got %q", pu.Role)
+	}
+	_, err = store.UpdateProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: missingID, Role: "editor"})
+	expectError(t, err, errors.ErrNotFound)
+
+	users, _, err := store.GetProjectUsers(p.ID, model.ListOptions{})
+	mustNotFail(t, err)
+	if len(users) != 1 || users[0].UserID != u.ID {
+		t.Errorf("expected project user %s, got %v", u.ID, users)
+	}
+
+	projects, _, err := store.GetUserProjects(u.ID, model.ListOptions{})
+	mustNotFail(t, err)
+	if len(projects) != 1 || projects[0].ID != p.ID {
+		t.Errorf("expected user project %s, got %v", p.ID, projects)
+	}
+
+	roles, err := store.GetUserProjectRoles(u.ID)
+	mustNotFail(t, err)
+	if len(roles) != 1 || roles[0].ProjectID != p.ID || roles[0].Role != "editor" {
+		t.Errorf("unexpected user project roles %v", roles)
+	}
+
+	mustNotFail(t, store.RevokeProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID}))
+	_, err = store.GetProjectUser(p.ID, u.ID)
+	expectError(t, err, errors.ErrNotFound)
+	mustNotFail(t, store.RevokeProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID}))
+}
+
+func testProjectClients(t *testing.T, store datastore.Store) {
+	p := createProject(t, store)
+
+	c, err := store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: "ci", Secret: "s1"})
+	mustNotFail(t, err)
+	if c.ClientID == "" || c.Secret != "s1" {
+		t.Errorf("unexpected created client %+v", c)
+	}
+	_, err = store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: "ci", Secret: "s2"})
+	expectError(t, err, errors.ErrAlreadyExists)
+	other, err := store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: "deploy", Secret: "s2"})
+	mustNotFail(t, err)
+
+	found, err := store.FindOneClient(c.ClientID)
+	mustNotFail(t, err)
+	if found.ProjectID != p.ID {
+		t.Errorf("expected client of project %s, got %+v", p.ID, found)
+	}
+
+	c, err = store.UpdateProjectClientSecret(model.ProjectClient{ProjectID: p.ID, ClientID: c.ClientID, Secret: "s3"})
+	mustNotFail(t, err)
+	if c.Secret != "s3" {
+		t.Errorf("expected secret 's3', got %q", c.Secret)
+	}
+	if c.CanAddKeys {
+		t.Errorf("expected client not to be allowed to add keys")
+	}
+	c, err = store.UpdateProjectClientCanAddKeys(model.ProjectClient{ProjectID: p.ID, ClientID: c.ClientID, CanAddKeys: true})
+	mustNotFail(t, err)
+	if !c.CanAddKeys || c.Secret != "s3" {
+		t.Errorf("expected client to be allowed to add keys, got %+v", c)
+	}
+	_, err = store.UpdateProjectClientCanAddKeys(model.ProjectClient{ProjectID: p.ID, ClientID: missingID, CanAddKeys: true})
+	expectError(t, err, errors.ErrNotFound)
+	keyClient, err := store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: "push", Secret: "s4", CanAddKeys: true})
+	mustNotFail(t, err)
+	if found, err := store.FindOneClient(keyClient.ClientID); err != nil || !found.CanAddKeys {
+		t.Errorf("expected created client to be allowed to add keys, got %+v, %v", found, err)
+	}
+	_, err = store.UpdateProjectClientName(model.ProjectClient{ProjectID: p.ID, ClientID: other.ClientID, Name: "ci"})
+	expectError(t, err, errors.ErrAlreadyExists)
+	_, err = store.UpdateProjectClientName(model.ProjectClient{ProjectID: p.ID, ClientID: missingID, Name: "x"})
+	expectError(t, err, errors.ErrNotFound)
+
+	clients, _, err := store.GetProjectClients(p.ID, model.ListOptions{})
+	mustNotFail(t, err)
+	if len(clients) != 3 {
+		t.Errorf("expected 3 clients, got %d", len(clients))
+	}
+
+	mustNotFail(t, store.DeleteProjectClient(p.ID, c.ClientID))
+	_, err = store.GetProjectClient(p.ID, c.ClientID)
+	expectError(t, err, errors.ErrNotFound)
+	_, err = store.FindOneClient(c.ClientID)
+	expectError(t, err, errors.ErrNotFound)
+	mustNotFail(t, store.DeleteProjectClient(p.ID, c.ClientID))
+}
+
+func testProjectClientPages(t *testing.T, store datastore.Store) {
+	p := createProject(t, store)
+	for _, name := range []string{"b", "a", "c"} {
+		_, err := store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: name, Secret: "s"})
+		mustNotFail(t, err)
+	}
+
+	clients, next, err := store.GetProjectClients(p.ID, model.ListOptions{Limit: 2})
+	mustNotFail(t, err)
+	if len(clients) != 2 || clients[0].Name != "a" || clients[1].Name != "b" || next == nil {
+		t.Fatalf("expected clients a and b followed by more, got %v", clients)
+	}
+
+	clients, next, err = store.GetProjectClients(p.ID, model.ListOptions{Limit: 2, After: next})
+	mustNotFail(t, err)
+	if len(clients) != 1 || clients[0].Name != "c" || next != nil {
+		t.Errorf("expected the last page to hold client c, got %v", clients)
+	}
+}
+
+func testHistory(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a", "b")
+
+	mustNotFail(t, store.AddHistoryEntries([]model.HistoryEntry{
+		{ProjectID: p.ID, LocaleIdent: "en_US", Key: "a", Action: model.HistoryPairUpdated, OldValue: "", NewValue: "A"},
+		{ProjectID: p.ID, LocaleIdent: "de_DE", Key: "a", Action: model.HistoryPairUpdated, OldValue: "", NewValue: "Ä"},
+	}))
+	// Entries are ordered by creation time, make sure the next ones are newer
+	time.Sleep(10 * time.Millisecond)
+	mustNotFail(t, store.AddHistoryEntries([]model.HistoryEntry{
+		{ProjectID: p.ID, Key: "b", Action: model.HistoryKeyRenamed, OldValue: "b", NewValue: "c"},
+	}))
+
+	entries, err := store.GetLocaleHistory(p.ID, "en_US", "")
+	mustNotFail(t, err)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Action != model.HistoryKeyRenamed {
+		t.Errorf("expected newest entry first, got %+v", entries[0])
+	}
+	if entries[1].ID == "" || entries[1].CreatedAt.IsZero() {
+		t.Errorf("expected entry id and creation time to be set, got %+v", entries[1])
+	}
+
+	entries, err = store.GetLocaleHistory(p.ID, "en_US", "c")
+	mustNotFail(t, err)
+	if len(entries) != 1 || entries[0].NewValue != "c" {
+		t.Errorf("expected the rename to key 'c', got %v", entries)
+	}
+
+	entries, err = store.GetLocaleHistory(p.ID, "de_DE", "a")
+	mustNotFail(t, err)
+	if len(entries) != 1 || entries[0].NewValue != "Ä" {
+		t.Fatalf("expected the de_DE entry, got %v", entries)
+	}
+
+	e, err := store.GetHistoryEntry(p.ID, entries[0].ID)
+	mustNotFail(t, err)
+	if e.LocaleIdent != "de_DE" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	_, err = store.GetHistoryEntry(missingID, entries[0].ID)
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testJournal(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a", "b")
+	createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A", "b": "B"})
+	journal := func() *model.Journal {
+		return &model.Journal{SubjectID: "user", SubjectType: "user"}
+	}
+
+	// The previous values come from the update itself
+	j := journal()
+	_, err := store.MergeLocalePairs(p.ID, "en_US", map[string]string{"a": "AA", "b": "B"}, 0, j)
+	mustNotFail(t, err)
+	if len(j.Entries) != 1 || j.Entries[0].OldValue != "A" || j.Entries[0].NewValue != "AA" || j.Entries[0].SubjectID != "user" {
+		t.Fatalf("expected the change of 'a' to be recorded, got %v", j.Entries)
+	}
+	entries, err := store.GetLocaleHistory(p.ID, "en_US", "a")
+	mustNotFail(t, err)
+	if len(entries) != 1 || entries[0].NewValue != "AA" || entries[0].SubjectType != "user" {
+		t.Fatalf("expected the recorded change to be stored, got %v", entries)
+	}
+
+	// A failed update stores no history
+	j = journal()
+	_, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"a": "stale", "b": "B"}, 1, j)
+	expectError(t, err, errors.ErrStaleVersion)
+	_, err = store.UpdateLocales(p.ID, []string{"en_US"}, func(loc *model.Locale) error {
+		loc.Pairs["b"] = "failed"
+		return errors.ErrNotFound
+	}, journal())
+	expectError(t, err, errors.ErrNotFound)
+	entries, err = store.GetLocaleHistory(p.ID, "en_US", "")
+	mustNotFail(t, err)
+	if len(entries) != 1 {
+		t.Fatalf("expected failed updates to store no history, got %v", entries)
+	}
+
+	j = journal()
+	_, err = store.UpdateLocales(p.ID, []string{"en_US"}, func(loc *model.Locale) error {
+		loc.Pairs["b"] = "BB"
+		return nil
+	}, j)
+	mustNotFail(t, err)
+	if len(j.Entries) != 1 || j.Entries[0].Key != "b" || j.Entries[0].OldValue != "B" {
+		t.Errorf("expected the change of 'b' to be recorded, got %v", j.Entries)
+	}
+
+	j = journal()
+	_, err = store.UpdateLocalePlurals(p.ID, "en_US", map[string]model.PluralForms{"a": {"one": "A", "other": "As"}}, 0, j)
+	mustNotFail(t, err)
+	if len(j.Entries) != 1 || j.Entries[0].Action != model.HistoryPluralUpdated || j.Entries[0].NewValue != `{"one":"A","other":"As"}` {
+		t.Errorf("expected the plural forms of 'a' to be recorded, got %v", j.Entries)
+	}
+
+	// Key changes
+	var actions []string
+	record := func(j *model.Journal) {
+		for _, e := range j.Entries {
+			actions = append(actions, e.Action+" "+e.Key)
+		}
+	}
+	j = journal()
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "c"}, j)
+	mustNotFail(t, err)
+	record(j)
+	j = journal()
+	_, _, err = store.UpdateProjectKey(p.ID, "c", "d", j)
+	mustNotFail(t, err)
+	record(j)
+	j = journal()
+	_, err = store.DeleteProjectKey(p.ID, "d", j)
+	mustNotFail(t, err)
+	record(j)
+	j = journal()
+	_, err = store.UpdateProject(model.Project{ID: p.ID, Keys: []string{"a", "b", "e"}}, j)
+	mustNotFail(t, err)
+	record(j)
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "e"}, journal())
+	expectError(t, err, errors.ErrAlreadyExists)
+	j = journal()
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "f", Plural: true}, j)
+	mustNotFail(t, err)
+	record(j)
+	j = journal()
+	_, err = store.SetProjectKeyPlural(p.ID, "e", true, j)
+	mustNotFail(t, err)
+	record(j)
+	// Setting the flag the key already has changes nothing
+	j = journal()
+	_, err = store.SetProjectKeyPlural(p.ID, "e", true, j)
+	mustNotFail(t, err)
+	record(j)
+	j = journal()
+	_, err = store.SetProjectKeyPlural(p.ID, "f", false, j)
+	mustNotFail(t, err)
+	if len(j.Entries) != 1 || j.Entries[0].OldValue != "true" || j.Entries[0].NewValue != "false" {
+		t.Errorf("expected 'f' to stop being plural, got %v", j.Entries)
+	}
+	record(j)
+	expectStrings(t, actions, "key_added c", "key_renamed c", "key_deleted d", "key_added e",
+		"key_added f", "key_plural_updated f", "key_plural_updated e", "key_plural_updated f")
+
+	entries, err = store.GetLocaleHistory(p.ID, "en_US", "")
+	mustNotFail(t, err)
+	if len(entries) != 11 {
+		t.Errorf("expected 11 stored entries, got %v", entries)
+	}
+}
+
+func testKeyMeta(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "b", "a")
+	_, err := store.SetProjectKeyPlural(p.ID, "b", true, nil)
+	mustNotFail(t, err)
+
+	keys, err := store.GetProjectKeys(p.ID)
+	mustNotFail(t, err)
+	if len(keys) != 2 || keys[0].Key != "a" || keys[1].Key != "b" {
+		t.Fatalf("expected keys sorted by name, got %v", keys)
+	}
+	if keys[0].Plural || !keys[1].Plural {
+		t.Errorf("expected only key 'b' to be plural, got %v", keys)
+	}
+
+	k, err := store.UpdateProjectKeyMeta(model.Key{ProjectID: p.ID, Key: "a", Description: "Greeting", Context: "Home", MaxLength: 10, Tags: []string{"ui"}})
+	mustNotFail(t, err)
+	if k.Description != "Greeting" || k.Context != "Home" || k.MaxLength != 10 {
+		t.Errorf("unexpected updated key %+v", k)
+	}
+	expectStrings(t, k.Tags, "ui")
+
+	_, err = store.UpdateProjectKeyMeta(model.Key{ProjectID: p.ID, Key: "missing"})
+	expectError(t, err, errors.ErrNotFound)
+
+	// Deleted keys lose their metadata, and added ones start without any
+	_, err = store.DeleteProjectKey(p.ID, "a", nil)
+	mustNotFail(t, err)
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "a"}, nil)
+	mustNotFail(t, err)
+	k, err = store.GetProjectKey(p.ID, "a")
+	mustNotFail(t, err)
+	if k.Description != "" || len(k.Tags) != 0 {
+		t.Errorf("expected re-added key without metadata, got %+v", k)
+	}
+
+	// Keys are added along with their metadata
+	added, err := store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "c", Description: "Items", MaxLength: 20, Tags: []string{"cart"}, Plural: true}, nil)
+	mustNotFail(t, err)
+	expectStrings(t, added.PluralKeys, "b", "c")
+	k, err = store.GetProjectKey(p.ID, "c")
+	mustNotFail(t, err)
+	if k.Description != "Items" || k.MaxLength != 20 || !k.Plural {
+		t.Errorf("expected the key to be added with its metadata, got %+v", k)
+	}
+	expectStrings(t, k.Tags, "cart")
+}
+
+func testRefreshTokens(t *testing.T, store datastore.Store) {
+	u := createUser(t, store)
+	expiresAt := time.Now().Add(time.Hour)
+
+	first, err := store.CreateRefreshToken(model.RefreshToken{TokenHash: unique("hash"), SubjectID: u.ID, SubjectType: "user", ExpiresAt: expiresAt})
+	mustNotFail(t, err)
+	if first.ID == "" || first.FamilyID == "" || !first.IsActive(time.Now()) {
+		t.Fatalf("unexpected created token %+v", first)
+	}
+	_, err = store.CreateRefreshToken(model.RefreshToken{TokenHash: first.TokenHash, SubjectID: u.ID, SubjectType: "user", ExpiresAt: expiresAt})
+	expectError(t, err, errors.ErrAlreadyExists)
+
+	next := model.RefreshToken{TokenHash: unique("hash"), FamilyID: first.FamilyID, SubjectID: u.ID, SubjectType: "user", ExpiresAt: expiresAt}
+	second, err := store.RotateRefreshToken(first.TokenHash, next)
+	mustNotFail(t, err)
+	if second.FamilyID != first.FamilyID {
+		t.Errorf("expected rotated token to keep family %s, got %s", first.FamilyID, second.FamilyID)
+	}
+
+	// A replaced token can't be rotated again
+	_, err = store.RotateRefreshToken(first.TokenHash, model.RefreshToken{TokenHash: unique("hash"), FamilyID: first.FamilyID, SubjectID: u.ID, SubjectType: "user", ExpiresAt: expiresAt})
+	expectError(t, err, errors.ErrNotFound)
+
+	mustNotFail(t, store.RevokeRefreshTokenFamily(first.FamilyID))
+	revoked, err := store.GetRefreshToken(second.TokenHash)
+	mustNotFail(t, err)
+	if revoked.IsActive(time.Now()) {
+		t.Errorf("expected token to be revoked with its family")
+	}
+
+	_, err = store.GetRefreshToken(unique("hash"))
+	expectError(t, err, errors.ErrNotFound)
+}
+
+func testRevokedAccessTokens(t *testing.T, store datastore.Store) {
+	jti := unique("jti")
+	subject := unique("subject")
+	issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	revoked, err := store.IsAccessTokenRevoked(jti, subject, issuedAt)
+	mustNotFail(t, err)
+	if revoked {
+		t.Errorf("expected token not to be revoked")
+	}
+
+	mustNotFail(t, store.RevokeAccessToken(jti, time.Now().Add(time.Hour)))
+	mustNotFail(t, store.RevokeAccessToken(jti, time.Now().Add(time.Hour)))
+
+	revoked, err = store.IsAccessTokenRevoked(jti, subject, issuedAt)
+	mustNotFail(t, err)
+	if !revoked {
+		t.Errorf("expected token to be revoked")
+	}
+
+	// Revoking the subject refuses its tokens issued before, whatever their id
+	revokedAt := time.Now().Truncate(time.Second)
+	mustNotFail(t, store.RevokeSubjectAccessTokens(subject, revokedAt, time.Now().Add(time.Hour)))
+	cases := []struct {
+		jti      string
+		subject  string
+		issuedAt time.Time
+		revoked  bool
+	}{
+		{unique("jti"), subject, issuedAt, true},
+		{"", subject, issuedAt, true},
+		{unique("jti"), subject, revokedAt, false},
+		{unique("jti"), subject, revokedAt.Add(time.Second), false},
+		{unique("jti"), unique("subject"), issuedAt, false},
+		{"", unique("subject"), issuedAt, false},
+	}
+	for i, c := range cases {
+		revoked, err := store.IsAccessTokenRevoked(c.jti, c.subject, c.issuedAt)
+		mustNotFail(t, err)
+		if revoked != c.revoked {
+			t.Errorf("case %d: expected revoked to be %v", i, c.revoked)
+		}
+	}
+
+	// A later revocation replaces the previous one
+	mustNotFail(t, store.RevokeSubjectAccessTokens(subject, revokedAt.Add(time.Minute), time.Now().Add(time.Hour)))
+	revoked, err = store.IsAccessTokenRevoked(unique("jti"), subject, revokedAt.Add(time.Second))
+	mustNotFail(t, err)
+	if !revoked {
+		t.Errorf("expected token to be revoked by the later revocation")
+	}
+}
+
+func testRevokeSubjectRefreshTokens(t *testing.T, store datastore.Store) {
+	u := createUser(t, store)
+	other := createUser(t, store)
+	expiresAt := time.Now().Add(time.Hour)
+
+	var hashes []string
+	for i := 0; i < 2; i++ {
+		token, err := store.CreateRefreshToken(model.RefreshToken{TokenHash: unique("hash"), SubjectID: u.ID, SubjectType: "user", ExpiresAt: expiresAt})
+		mustNotFail(t, err)
+		hashes = append(hashes, token.TokenHash)
+	}
+	kept, err := store.CreateRefreshToken(model.RefreshToken{TokenHash: unique("hash"), SubjectID: other.ID, SubjectType: "user", ExpiresAt: expiresAt})
+	mustNotFail(t, err)
+
+	mustNotFail(t, store.RevokeSubjectRefreshTokens(u.ID))
+	for _, hash := range hashes {
+		token, err := store.GetRefreshToken(hash)
+		mustNotFail(t, err)
+		if token.IsActive(time.Now()) {
+			t.Errorf("expected every family of the subject to be revoked")
+		}
+	}
+	token, err := store.GetRefreshToken(kept.TokenHash)
+	mustNotFail(t, err)
+	if !token.IsActive(time.Now()) {
+		t.Errorf("expected tokens of other subjects to be kept")
+	}
+}
+
+func testWebhooks(t *testing.T, store datastore.Store) {
+	p := createProject(t, store)
+
+	_, err := store.CreateWebhook(model.Webhook{ProjectID: missingID, URL: "https://example.com", Secret: "s"})
+	expectError(t, err, errors.ErrNotFound)
+
+	w, err := store.CreateWebhook(model.Webhook{
+		ProjectID: p.ID,
+		URL:       "https://example.com/hook",
+		Secret:    "s1",
+		Events:    []string{model.EventKeyAdded},
+		Active:    true})
+	mustNotFail(t, err)
+	if w.ID == "" || w.Secret != "s1" || !w.Active || w.CreatedAt.IsZero() {
+		t.Errorf("unexpected created webhook %+v", w)
+	}
+	expectStrings(t, w.Events, model.EventKeyAdded)
+
+	w.Events = nil
+	w.Active = false
+	w, err = store.UpdateWebhook(*w)
+	mustNotFail(t, err)
+	if w.Active {
+		t.Error("expected webhook to be disabled")
+	}
+	expectStrings(t, w.Events)
+	_, err = store.UpdateWebhook(model.Webhook{ProjectID: p.ID, ID: missingID, URL: "https://example.com"})
+	expectError(t, err, errors.ErrNotFound)
+
+	webhooks, err := store.GetProjectWebhooks(p.ID)
+	mustNotFail(t, err)
+	if len(webhooks) != 1 || webhooks[0].ID != w.ID {
+		t.Errorf("expected webhook %s, got %+v", w.ID, webhooks)
+	}
+
+	due, err := store.CreateWebhookDelivery(model.WebhookDelivery{
+		WebhookID: w.ID, ProjectID: p.ID, Event: model.EventKeyAdded, Payload: `{"a":1}`,
+		NextAttemptAt: time.Now().Add(-time.Minute)})
+	mustNotFail(t, err)
+	if due.Status != model.DeliveryPending || due.Payload != `{"a":1}` {
+		t.Errorf("unexpected created delivery %+v", due)
+	}
+	later, err := store.CreateWebhookDelivery(model.WebhookDelivery{
+		WebhookID: w.ID, ProjectID: p.ID, Event: model.EventKeyAdded, Payload: "{}",
+		NextAttemptAt: time.Now().Add(time.Hour)})
+	mustNotFail(t, err)
+
+	// Only due deliveries are claimed, and only once per lease
+	claimed, err := store.ClaimWebhookDeliveries(1000, time.Minute)
+	mustNotFail(t, err)
+	if !containsDelivery(claimed, due.ID) || containsDelivery(claimed, later.ID) {
+		t.Errorf("expected to claim delivery %s only, got %+v", due.ID, claimed)
+	}
+	claimed, err = store.ClaimWebhookDeliveries(1000, time.Minute)
+	mustNotFail(t, err)
+	if containsDelivery(claimed, due.ID) {
+		t.Errorf("expected claimed delivery %s to be leased", due.ID)
+	}
+
+	deliveredAt := time.Now()
+	due.Status = model.DeliveryDelivered
+	due.Attempts = 1
+	due.LastStatusCode = 204
+	due.DeliveredAt = &deliveredAt
+	updated, err := store.UpdateWebhookDelivery(*due)
+	mustNotFail(t, err)
+	if updated.Status != model.DeliveryDelivered || updated.Attempts != 1 || updated.LastStatusCode != 204 || updated.DeliveredAt == nil {
+		t.Errorf("unexpected updated delivery %+v", updated)
+	}
+	_, err = store.UpdateWebhookDelivery(model.WebhookDelivery{ID: missingID, Status: model.DeliveryFailed})
+	expectError(t, err, errors.ErrNotFound)
+
+	deliveries, err := store.GetWebhookDeliveries(w.ID, 10)
+	mustNotFail(t, err)
+	if len(deliveries) != 2 || deliveries[0].ID != later.ID || deliveries[1].ID != due.ID {
+		t.Errorf("expected deliveries %s and %s, newest first, got %+v", later.ID, due.ID, deliveries)
+	}
+	deliveries, err = store.GetWebhookDeliveries(w.ID, 1)
+	mustNotFail(t, err)
+	if len(deliveries) != 1 {
+		t.Errorf("expected 1 delivery, got %d", len(deliveries))
+	}
+
+	mustNotFail(t, store.DeleteWebhook(p.ID, w.ID))
+	_, err = store.GetProjectWebhook(p.ID, w.ID)
+	expectError(t, err, errors.ErrNotFound)
+	deliveries, err = store.GetWebhookDeliveries(w.ID, 10)
+	mustNotFail(t, err)
+	if len(deliveries) != 0 {
+		t.Errorf("expected the deliveries to be deleted with the webhook, got %d", len(deliveries))
+	}
+	mustNotFail(t, store.DeleteWebhook(p.ID, w.ID))
+}
+
+func testDeleteProject(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+	u := createUser(t, store)
+	createLocale(t, store, p.ID, "en_US", map[string]string{"a": "A"})
+	_, err := store.AssignProjectUser(model.ProjectUser{ProjectID: p.ID, UserID: u.ID, Role: "owner"})
+	mustNotFail(t, err)
+	c, err := store.CreateProjectClient(model.ProjectClient{ProjectID: p.ID, Name: "ci", Secret: "s"})
+	mustNotFail(t, err)
+	mustNotFail(t, store.AddHistoryEntries([]model.HistoryEntry{
+		{ProjectID: p.ID, Key: "a", Action: model.HistoryKeyAdded, NewValue: "a"},
+	}))
+	w, err := store.CreateWebhook(model.Webhook{ProjectID: p.ID, URL: "https://example.com", Secret: "s", Active: true})
+	mustNotFail(t, err)
+
+	mustNotFail(t, store.DeleteProject(p.ID))
+	mustNotFail(t, store.DeleteProject(p.ID))
+
+	_, err = store.GetProject(p.ID)
+	expectError(t, err, errors.ErrNotFound)
+
+	locs, err := store.GetProjectLocales(p.ID)
+	mustNotFail(t, err)
+	roles, err := store.GetUserProjectRoles(u.ID)
+	mustNotFail(t, err)
+	clients, _, err := store.GetProjectClients(p.ID, model.ListOptions{})
+	mustNotFail(t, err)
+	entries, err := store.GetLocaleHistory(p.ID, "en_US", "")
+	mustNotFail(t, err)
+	keys, err := store.GetProjectKeys(p.ID)
+	mustNotFail(t, err)
+	if len(locs)+len(roles)+len(clients)+len(entries)+len(keys) != 0 {
+		t.Errorf("expected project data to be deleted, got %d locales, %d roles, %d clients, %d history entries and %d keys",
+			len(locs), len(roles), len(clients), len(entries), len(keys))
+	}
+
+	_, err = store.FindOneClient(c.ClientID)
+	expectError(t, err, errors.ErrNotFound)
+	_, err = store.GetProjectWebhook(p.ID, w.ID)
+	expectError(t, err, errors.ErrNotFound)
+	if _, err := store.GetUserByID(u.ID); err != nil {
+		t.Errorf("expected user to outlive the project, got %v", err)
+	}
+}
+
+func createProject(t *testing.T, store datastore.Store, keys ...string) *model.Project {
+	t.Helper()
+	if keys == nil {
+		keys = []string{}
+	}
+	p, err := store.CreateProject(model.Project{Name: unique("project"), Keys: keys})
+	mustNotFail(t, err)
+	expectStrings(t, p.Keys, keys...)
+	return p
+}
+
+func createUser(t *testing.T, store datastore.Store) *model.User {
+	t.Helper()
+	u, err := store.CreateUser(model.User{Name: "Jane", Email: unique("user") + "@example.com", Password: "hash"})
+	mustNotFail(t, err)
+	return u
+}
+
+func createLocale(t *testing.T, store datastore.Store, projectID, ident string, pairs map[string]string) *model.Locale {
+	t.Helper()
+	if pairs == nil {
+		pairs = map[string]string{}
+	}
+	loc, err := store.CreateLocale(model.Locale{Ident: ident, Language: ident, Country: ident, Pairs: pairs, ProjectID: projectID}, nil)
+	mustNotFail(t, err)
+	return loc
+}
+
+func testTranslationConfig(t *testing.T, store datastore.Store) {
+	p := createProject(t, store)
+
+	_, err := store.GetTranslationConfig(p.ID)
+	expectError(t, err, errors.ErrNotFound)
+
+	c, err := store.SetTranslationConfig(model.TranslationConfig{ProjectID: p.ID, Provider: model.TranslationPseudo})
+	mustNotFail(t, err)
+	if c.Provider != model.TranslationPseudo || c.UpdatedAt.IsZero() {
+		t.Errorf("unexpected translation config %+v", c)
+	}
+
+	// Setting the config again replaces it
+	_, err = store.SetTranslationConfig(model.TranslationConfig{
+		ProjectID: p.ID,
+		Provider:  model.TranslationHTTP,
+		URL:       "http://localhost:8000/translate",
+		APIKey:    "key"})
+	mustNotFail(t, err)
+	c, err = store.GetTranslationConfig(p.ID)
+	mustNotFail(t, err)
+	if c.Provider != model.TranslationHTTP || c.URL != "http://localhost:8000/translate" || c.APIKey != "key" {
+		t.Errorf("unexpected translation config %+v", c)
+	}
+
+	mustNotFail(t, store.DeleteTranslationConfig(p.ID))
+	_, err = store.GetTranslationConfig(p.ID)
+	expectError(t, err, errors.ErrNotFound)
+	mustNotFail(t, store.DeleteTranslationConfig(p.ID))
+}
+
+func testWebhookEvents(t *testing.T, store datastore.Store) {
+	p := createProject(t, store, "a")
+	all, err := store.CreateWebhook(model.Webhook{ProjectID: p.ID, URL: "https://example.com", Secret: "s", Active: true})
+	mustNotFail(t, err)
+	keys, err := store.CreateWebhook(model.Webhook{
+		ProjectID: p.ID, URL: "https://example.com", Secret: "s", Events: []string{model.EventKeyAdded}, Active: true})
+	mustNotFail(t, err)
+
+	j := func() *model.Journal { return &model.Journal{SubjectID: "user", SubjectType: "user"} }
+	_, err = store.CreateLocale(model.Locale{Ident: "en_US", Language: "en", Country: "US", ProjectID: p.ID}, j())
+	mustNotFail(t, err)
+	_, err = store.AddProjectKey(model.Key{ProjectID: p.ID, Key: "b"}, j())
+	mustNotFail(t, err)
+	_, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"a": "A", "b": "B"}, 0, j())
+	mustNotFail(t, err)
+	// Failed changes queue nothing
+	_, err = store.UpdateLocalePairs(p.ID, "en_US", map[string]string{"a": "AA"}, 100, j())
+	expectError(t, err, errors.ErrStaleVersion)
+	mustNotFail(t, store.DeleteLocale(p.ID, "en_US", j()))
+	mustNotFail(t, store.DeleteLocale(p.ID, "en_US", j()))
+
+	expectEvents := func(webhookID string, expected ...string) {
+		t.Helper()
+		deliveries, err := store.GetWebhookDeliveries(webhookID, 10)
+		mustNotFail(t, err)
+		var events []string
+		for i := len(deliveries) - 1; i >= 0; i-- {
+			events = append(events, deliveries[i].Event)
+			if deliveries[i].Status != model.DeliveryPending || deliveries[i].Payload == "" {
+				t.Errorf("expected a pending delivery with a payload, got %+v", deliveries[i])
+			}
+		}
+		expectStrings(t, events, expected...)
+	}
+	expectEvents(all.ID, model.EventLocaleCreated, model.EventKeyAdded, model.EventLocaleUpdated, model.EventLocaleDeleted)
+	expectEvents(keys.ID, model.EventKeyAdded)
+}
+
+// containsDelivery returns true if deliveries holds the one with the id.
+func containsDelivery(deliveries []model.WebhookDelivery, id string) bool {
+	for _, d := range deliveries {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// unique returns a random name with the prefix, so that tests can share a store.
+func unique(prefix string) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%s-%x", prefix, b)
+}
+
+func mustNotFail(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func expectError(t *testing.T, err, expected error) {
+	t.Helper()
+	if err != expected {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+}
+
+func expectStrings(t *testing.T, got []string, expected ...string) {
+	t.Helper()
+	a := append([]string{}, got...)
+	b := append([]string{}, expected...)
+	sort.Strings(a)
+	sort.Strings(b)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func expectPairs(t *testing.T, got, expected map[string]string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+		return
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("expected %v, got %v", expected, got)
+			return
+		}
+	}
+}
@@ -35,6 +35,22 @@ var (
 		http.StatusUnprocessableEntity,
 		"UnprocessableEntity",
 		http.StatusText(http.StatusUnprocessableEntity))
+	ErrLocaleIncomplete = New(
+		http.StatusUnprocessableEntity,
+		"LocaleIncomplete",
+		"locale translation progress is below the requested minimum")
+	ErrPreconditionFailed = New(
+		http.StatusPreconditionFailed,
+		"PreconditionFailed",
+		"entry was modified since it was retrieved")
+	ErrSourceLocaleInUse = New(
+		http.StatusConflict,
+		"SourceLocaleInUse",
+		"the project's source locale can't be deleted")
+	ErrTranslationFailed = New(
+		http.StatusBadGateway,
+		"TranslationFailed",
+		"the machine translation provider failed")
 	ErrUnsupportedMediaType = New(
 		http.StatusUnsupportedMediaType,
 		"UnsupportedMediaType",
@@ -4,11 +4,18 @@ import (
 	"bytes"
 
 	"encoding/xml"
+	"strings"
 
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
-type Android struct{}
+type Android struct {
+	Comments map[string]string
+}
+
+func (e *Android) SetComments(comments map[string]string) {
+	e.Comments = comments
+}
 
 func (e *Android) FileExtension() string {
 	return "xml"
@@ -31,6 +38,13 @@ func (e *Android) Export(locale *model.Locale) ([]byte, error) {
 	}
 
 	for k, v := range locale.Pairs {
+		if isPlural(locale, k) {
+			continue
+		}
+		err = encodeXMLComment(encoder, e.Comments[k])
+		if err != nil {
+			return nil, err
+		}
 		err = encoder.EncodeToken(xml.StartElement{
 			Name: xml.Name{Local: "string"},
 			Attr: []xml.Attr{xml.Attr{Name: xml.Name{Local: "name"}, Value: k}},
@@ -48,6 +62,42 @@ func (e *Android) Export(locale *model.Locale) ([]byte, error) {
 		}
 	}
 
+	for _, k := range pluralKeys(locale) {
+		err = encodeXMLComment(encoder, e.Comments[k])
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.EncodeToken(xml.StartElement{
+			Name: xml.Name{Local: "plurals"},
+			Attr: []xml.Attr{xml.Attr{Name: xml.Name{Local: "name"}, Value: k}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		forms := locale.Plurals[k]
+		for _, c := range pluralCategories(locale, forms) {
+			err = encoder.EncodeToken(xml.StartElement{
+				Name: xml.Name{Local: "item"},
+				Attr: []xml.Attr{xml.Attr{Name: xml.Name{Local: "quantity"}, Value: c}},
+			})
+			if err != nil {
+				return nil, err
+			}
+			err = encoder.EncodeToken(xml.CharData([]byte(forms[c])))
+			if err != nil {
+				return nil, err
+			}
+			err = encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "item"}})
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "plurals"}})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "resources"}})
 	if err != nil {
 		return nil, err
@@ -60,3 +110,57 @@ func (e *Android) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+type androidDocument struct {
+	Strings []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"string"`
+	Plurals []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Quantity string `xml:"quantity,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"item"`
+	} `xml:"plurals"`
+}
+
+func (e *Android) Import(data []byte) (*model.Locale, error) {
+	doc := androidDocument{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string)
+	for _, s := range doc.Strings {
+		pairs[s.Name] = unescapeAndroid(s.Value)
+	}
+
+	plurals := make(map[string]model.PluralForms)
+	for _, p := range doc.Plurals {
+		forms := make(model.PluralForms)
+		for _, item := range p.Items {
+			forms[item.Quantity] = unescapeAndroid(item.Value)
+		}
+		plurals[p.Name] = forms
+	}
+
+	return &model.Locale{Pairs: pairs, Plurals: plurals}, nil
+}
+
+// unescapeAndroid removes the backslash escaping used by Android string resources.
+func unescapeAndroid(str string) string {
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+	return unescapeC(str)
+}
+
+// encodeXMLComment writes the comment, if any. Double hyphens are not allowed in XML comments.
+func encodeXMLComment(encoder *xml.Encoder, comment string) error {
+	if comment == "" {
+		return nil
+	}
+	comment = strings.Replace(comment, "--", "- -", -1)
+	return encoder.EncodeToken(xml.Comment(" " + comment + " "))
+}
@@ -4,11 +4,18 @@ import (
 	"bytes"
 
 	"fmt"
+	"strings"
 
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
-type AppleStrings struct{}
+type AppleStrings struct {
+	Comments map[string]string
+}
+
+func (e *AppleStrings) SetComments(comments map[string]string) {
+	e.Comments = comments
+}
 
 func (e *AppleStrings) FileExtension() string {
 	return "strings"
@@ -18,6 +25,12 @@ func (e *AppleStrings) Export(locale *model.Locale) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 
 	for k, v := range locale.Pairs {
+		if c := e.Comments[k]; c != "" {
+			_, err := buf.WriteString(fmt.Sprintf("/* %s */\n", strings.Replace(c, "*/", "* /", -1)))
+			if err != nil {
+				return nil, err
+			}
+		}
 		_, err := buf.WriteString(fmt.Sprintf("\"%s\" = \"%s\";\n", k, v))
 		if err != nil {
 			return nil, err
@@ -26,3 +39,35 @@ func (e *AppleStrings) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+func (e *AppleStrings) Import(data []byte) (*model.Locale, error) {
+	s := &textScanner{src: string(data)}
+	pairs := make(map[string]string)
+
+	for {
+		s.skipSpaceAndComments()
+		if s.eof() {
+			break
+		}
+		key, err := s.quoted()
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpaceAndComments()
+		if !s.consume("=") {
+			return nil, ErrMalformedInput
+		}
+		s.skipSpaceAndComments()
+		value, err := s.quoted()
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpaceAndComments()
+		if !s.consume(";") {
+			return nil, ErrMalformedInput
+		}
+		pairs[key] = value
+	}
+
+	return &model.Locale{Pairs: pairs}, nil
+}
@@ -30,3 +30,27 @@ func (e *CSV) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+func (e *CSV) Import(data []byte) (*model.Locale, error) {
+	rd := csv.NewReader(bytes.NewReader(data))
+	rd.FieldsPerRecord = -1
+
+	records, err := rd.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string)
+	for _, r := range records {
+		if len(r) == 0 || r[0] == "" {
+			continue
+		}
+		if len(r) < 2 {
+			pairs[r[0]] = ""
+			continue
+		}
+		pairs[r[0]] = r[1]
+	}
+
+	return &model.Locale{Pairs: pairs}, nil
+}
@@ -1,4 +1,4 @@
-// Package export handles the exporting of API data to common formats.
+// Package export handles the exporting and importing of API data to and from common formats.
 package export
 
 import "github.com/iris-contrib/parrot/parrot-api/model"
@@ -8,3 +8,22 @@ type Exporter interface {
 	FileExtension() string
 	Export(*model.Locale) ([]byte, error)
 }
+
+// Importer specifies the interface that must be specified for every format
+// that can be parsed back into locale pairs.
+type Importer interface {
+	Import([]byte) (*model.Locale, error)
+}
+
+// Commenter is implemented by exporters whose format supports comments.
+// The comments, by key, are written above the values they describe.
+type Commenter interface {
+	SetComments(comments map[string]string)
+}
+
+// Nester is implemented by formats that can write keys as nested objects.
+// The keys are split on the delimiter, which defaults to model.DefaultKeyDelimiter.
+type Nester interface {
+	SetKeyDelimiter(delimiter string)
+	SetNested(nested bool)
+}
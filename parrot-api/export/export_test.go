@@ -0,0 +1,372 @@
+package export
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	formats := map[string]interface {
+		Exporter
+		Importer
+	}{
+		"keyvaluejson":  &JSON{},
+		"i18next":       &JSON{Nested: true},
+		"po":            &Gettext{},
+		"strings":       &AppleStrings{},
+		"properties":    &JavaProperties{},
+		"xmlproperties": &JavaXML{},
+		"android":       &Android{},
+		"php":           &PHP{},
+		"xlsx":          &XLSX{},
+		"csv":           &CSV{},
+		"yaml":          &Yaml{},
+		"nestedyaml":    &Yaml{Nested: true},
+		"ini":           &INI{},
+	}
+
+	in := &model.Locale{
+		Ident: "en_US",
+		Pairs: map[string]string{
+			"greeting":      "Hello",
+			"farewell":      "Goodbye, friend",
+			"checkout.done": "Thanks for your order",
+			"unicode":       "Grüße 🦜",
+		},
+	}
+
+	for name, format := range formats {
+		data, err := format.Export(in)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", name, err)
+		}
+		out, err := format.Import(data)
+		if err != nil {
+			t.Fatalf("%s: import failed: %v", name, err)
+		}
+		if len(out.Pairs) != len(in.Pairs) {
+			t.Fatalf("%s: expected %d pairs, got %d: %v", name, len(in.Pairs), len(out.Pairs), out.Pairs)
+		}
+		for k, v := range in.Pairs {
+			if out.Pairs[k] != v {
+				t.Errorf("%s: expected '%s' for key '%s', got '%s'", name, v, k, out.Pairs[k])
+			}
+		}
+	}
+}
+
+func TestGettextImport(t *testing.T) {
+	data := []byte(`# translator comment
+msgid ""
+msgstr ""
+"Language: fr_FR\n"
+
+#: src/app.js:10
+msgid "multi"
+msgstr ""
+"first line "
+"second line"
+msgid "escaped"
+msgstr "say \"hi\"\n"
+
+msgid "apple"
+msgid_plural "apples"
+msgstr[0] "pomme"
+msgstr[1] "pommes"
+`)
+
+	loc, err := (&Gettext{}).Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"multi":   "first line second line",
+		"escaped": "say \"hi\"\n",
+	}
+	if len(loc.Pairs) != len(expected) {
+		t.Fatalf("expected %d pairs, got %v", len(expected), loc.Pairs)
+	}
+	for k, v := range expected {
+		if loc.Pairs[k] != v {
+			t.Errorf("expected '%s' for key '%s', got '%s'", v, k, loc.Pairs[k])
+		}
+	}
+
+	if loc.Ident != "fr_FR" {
+		t.Errorf("expected ident 'fr_FR', got '%s'", loc.Ident)
+	}
+	apple := loc.Plurals["apple"]
+	if apple["one"] != "pomme" || apple["other"] != "pommes" {
+		t.Errorf("expected plural forms for 'apple', got %v", apple)
+	}
+}
+
+func TestGettextImportRejectsContexts(t *testing.T) {
+	data := []byte(`msgctxt "menu"
+msgid "Open"
+msgstr "Ouvrir"
+
+msgctxt "status"
+msgid "Open"
+msgstr "Ouvert"
+`)
+
+	_, err := (&Gettext{}).Import(data)
+	errs, ok := err.(*errors.MultiError)
+	if !ok {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(errs.Errors) != 1 || errs.Errors[0].Type != ErrDuplicateMsgid.Type {
+		t.Errorf("expected a single duplicate msgid error, got %v", errs.Errors)
+	}
+}
+
+func TestJavaPropertiesImport(t *testing.T) {
+	data := []byte("# comment\n! other comment\nkey\\ one = first\nkey2:second\nkey3 third \\\n    continued\nkey4=\\u00fcber\n")
+
+	loc, err := (&JavaProperties{}).Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"key one": "first",
+		"key2":    "second",
+		"key3":    "third continued",
+		"key4":    "über",
+	}
+	for k, v := range expected {
+		if loc.Pairs[k] != v {
+			t.Errorf("expected '%s' for key '%s', got '%s'", v, k, loc.Pairs[k])
+		}
+	}
+}
+
+func TestPluralExportImportRoundTrip(t *testing.T) {
+	formats := map[string]interface {
+		Exporter
+		Importer
+	}{
+		"po":          &Gettext{},
+		"android":     &Android{},
+		"stringsdict": &Stringsdict{},
+	}
+
+	in := &model.Locale{
+		Ident: "ru_RU",
+		Pairs: map[string]string{"apples": ""},
+		Plurals: map[string]model.PluralForms{
+			"apples": {"one": "яблоко", "few": "яблока", "many": "яблок", "other": "яблока"},
+		},
+	}
+
+	for name, format := range formats {
+		data, err := format.Export(in)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", name, err)
+		}
+		out, err := format.Import(data)
+		if err != nil {
+			t.Fatalf("%s: import failed: %v", name, err)
+		}
+		// Formats without a language header can't tell the rule apart
+		out.Ident = in.Ident
+		forms := out.Plurals["apples"]
+		for c, v := range in.Plurals["apples"] {
+			if forms[c] != v {
+				t.Errorf("%s: expected '%s' for category '%s', got '%s'", name, v, c, forms[c])
+			}
+		}
+	}
+}
+
+func TestBundlePath(t *testing.T) {
+	cases := []struct {
+		i18nType string
+		ident    string
+		expected string
+	}{
+		{"android", "pt_BR", "values-pt-rBR/strings.xml"},
+		{"android", "de", "values-de/strings.xml"},
+		{"strings", "pt_BR", "pt-BR.lproj/Localizable.strings"},
+		{"stringsdict", "en_US", "en-US.lproj/Localizable.stringsdict"},
+		{"po", "fr_FR", "fr_FR/LC_MESSAGES/messages.po"},
+		{"properties", "it_IT", "messages_it_IT.properties"},
+		{"keyvaluejson", "en_US", "en_US.json"},
+	}
+
+	for _, c := range cases {
+		got, err := BundlePath(c.i18nType, c.ident)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error: %v", c.i18nType, c.ident, err)
+			continue
+		}
+		if got != c.expected {
+			t.Errorf("%s %s: expected %q but got %q", c.i18nType, c.ident, c.expected, got)
+		}
+	}
+
+	for _, ident := range []string{"../../x", "en/../../x", "/etc", "e", "en_US/x", ""} {
+		if _, err := BundlePath("po", ident); err != ErrInvalidIdent {
+			t.Errorf("%q: expected ErrInvalidIdent but got %v", ident, err)
+		}
+	}
+}
+
+func TestExportComments(t *testing.T) {
+	formats := map[string]interface {
+		Exporter
+		Importer
+		Commenter
+	}{
+		"po":      &Gettext{},
+		"android": &Android{},
+		"strings": &AppleStrings{},
+	}
+
+	in := &model.Locale{
+		Ident: "en_US",
+		Pairs: map[string]string{"greeting": "Hello", "farewell": "Goodbye"},
+	}
+	comment := "Shown on the home screen -- keep it short */"
+
+	for name, format := range formats {
+		format.SetComments(map[string]string{"greeting": comment})
+		data, err := format.Export(in)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", name, err)
+		}
+		if !strings.Contains(string(data), "Shown on the home screen") {
+			t.Errorf("%s: expected comment in output:\n%s", name, data)
+		}
+
+		out, err := format.Import(data)
+		if err != nil {
+			t.Fatalf("%s: import failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(in.Pairs, out.Pairs) {
+			t.Errorf("%s: expected pairs %v but got %v", name, in.Pairs, out.Pairs)
+		}
+	}
+}
+
+func TestPseudoExport(t *testing.T) {
+	in := &model.Locale{
+		Ident: "en_US",
+		Pairs: map[string]string{
+			"greeting": "Hello {name}",
+			"count":    "%1$d items",
+			"empty":    "",
+		},
+	}
+	expected := map[string]string{
+		"greeting": "[Ĥéļļö {name} ~~~~]",
+		"count":    "[%1$d îţéɱš ~~~]",
+		"empty":    "",
+	}
+
+	for _, name := range []string{"keyvaluejson", "i18next", "po", "strings", "properties", "xmlproperties", "android", "php", "xlsx", "csv", "yaml", "ini"} {
+		exporter, _ := NewExporter(name)
+		importer, _ := NewImporter(name)
+
+		data, err := NewPseudo(exporter, 30).Export(in)
+		if err != nil {
+			t.Fatalf("%s: export failed: %v", name, err)
+		}
+		out, err := importer.Import(data)
+		if err != nil {
+			t.Fatalf("%s: import failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(expected, out.Pairs) {
+			t.Errorf("%s: expected pairs %v but got %v", name, expected, out.Pairs)
+		}
+	}
+	if in.Pairs["greeting"] != "Hello {name}" {
+		t.Errorf("expected the exported locale to be unchanged, got %v", in.Pairs)
+	}
+}
+
+func TestNestedExport(t *testing.T) {
+	in := &model.Locale{
+		Ident: "en_US",
+		Pairs: map[string]string{
+			"checkout::button::submit": "Submit",
+			"checkout::title":          "Checkout",
+			"items":                    "",
+		},
+		Plurals: map[string]model.PluralForms{
+			"items": {model.PluralOne: "{{count}} item", model.PluralOther: "{{count}} items"},
+		},
+	}
+
+	e := &JSON{Nested: true, Delimiter: "::"}
+	data, err := e.Export(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+    "checkout": {
+        "button": {
+            "submit": "Submit"
+        },
+        "title": "Checkout"
+    },
+    "items_one": "{{count}} item",
+    "items_other": "{{count}} items"
+}`
+	if string(data) != expected {
+		t.Errorf("expected nested JSON\n%s\ngot\n%s", expected, data)
+	}
+
+	out, err := e.Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(in.Pairs, "items")
+	if !reflect.DeepEqual(out.Pairs, in.Pairs) || !reflect.DeepEqual(out.Plurals, in.Plurals) {
+		t.Errorf("expected pairs %v and plurals %v, got %v and %v", in.Pairs, in.Plurals, out.Pairs, out.Plurals)
+	}
+
+	conflicting := &model.Locale{Pairs: map[string]string{"a": "A", "a.b": "B", "a.c": "C", "d": "D"}}
+	for _, e := range []Exporter{&JSON{Nested: true}, &Yaml{Nested: true}} {
+		_, err := e.Export(conflicting)
+		if err == nil {
+			t.Fatalf("%T: expected conflicting keys to fail", e)
+		}
+		if msg := err.Error(); !strings.Contains(msg, "'a' conflicts with key 'a.b'") ||
+			!strings.Contains(msg, "'a' conflicts with key 'a.c'") {
+			t.Errorf("%T: expected every conflict to be reported, got %q", e, msg)
+		}
+	}
+	if _, err := (&JSON{}).Export(conflicting); err != nil {
+		t.Errorf("expected flat export to allow conflicting keys, got %v", err)
+	}
+}
+
+func TestNestedImportConflict(t *testing.T) {
+	inputs := map[Importer][]byte{
+		&JSON{}: []byte(`{"a.b": "x", "a": {"b": "y"}}`),
+		&Yaml{}: []byte("a.b: x\na:\n  b: y\n"),
+	}
+	for importer, data := range inputs {
+		_, err := importer.Import(data)
+		errs, ok := err.(*errors.MultiError)
+		if !ok || len(errs.Errors) != 1 || errs.Errors[0].Type != ErrKeyConflict.Type {
+			t.Fatalf("%T: expected a key conflict, got %v", importer, err)
+		}
+		if !strings.Contains(errs.Errors[0].Message, "'a.b'") {
+			t.Errorf("%T: expected the conflicting key to be reported, got %q", importer, errs.Errors[0].Message)
+		}
+	}
+
+	out, err := (&JSON{}).Import([]byte(`{"a.b": "x", "a": {"c": "y"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"a.b": "x", "a.c": "y"}; !reflect.DeepEqual(out.Pairs, expected) {
+		t.Errorf("expected pairs %v, got %v", expected, out.Pairs)
+	}
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+var (
+	ErrInvalidIdent = errors.New("export: invalid locale ident")
+)
+
+// NewExporter returns the exporter for the provided type, or false if the type is unknown.
+func NewExporter(i18nType string) (Exporter, bool) {
+	switch strings.ToLower(i18nType) {
+	case "keyvaluejson":
+		return &JSON{}, true
+	case "i18next":
+		return &JSON{Nested: true}, true
+	case "po":
+		return &Gettext{}, true
+	case "strings":
+		return &AppleStrings{}, true
+	case "stringsdict":
+		return &Stringsdict{}, true
+	case "properties":
+		return &JavaProperties{}, true
+	case "xmlproperties":
+		return &JavaXML{}, true
+	case "android":
+		return &Android{}, true
+	case "php":
+		return &PHP{}, true
+	case "xlsx":
+		return &XLSX{}, true
+	case "csv":
+		return &CSV{}, true
+	case "yaml":
+		return &Yaml{Nested: true}, true
+	case "ini":
+		return &INI{}, true
+	}
+	return nil, false
+}
+
+// NewImporter returns the importer for the provided type, or false if the type is unknown.
+func NewImporter(i18nType string) (Importer, bool) {
+	exporter, ok := NewExporter(i18nType)
+	if !ok {
+		return nil, false
+	}
+	importer, ok := exporter.(Importer)
+	return importer, ok
+}
+
+// BundlePath returns the path of a locale's file inside an archive of every
+// project locale, following the conventions of the platform using the type.
+// Idents that are not valid locale idents are refused, so that the path can
+// never leave the archive.
+func BundlePath(i18nType, ident string) (string, error) {
+	if !model.ValidLocaleIdent(ident) {
+		return "", ErrInvalidIdent
+	}
+	language, region := ident, ""
+	if i := strings.Index(ident, "_"); i >= 0 {
+		language, region = ident[:i], ident[i+1:]
+	}
+
+	switch strings.ToLower(i18nType) {
+	case "android":
+		if region == "" {
+			return fmt.Sprintf("values-%s/strings.xml", language), nil
+		}
+		return fmt.Sprintf("values-%s-r%s/strings.xml", language, region), nil
+	case "strings", "stringsdict":
+		dir := language
+		if region != "" {
+			dir = language + "-" + region
+		}
+		return fmt.Sprintf("%s.lproj/Localizable.%s", dir, strings.ToLower(i18nType)), nil
+	case "po":
+		return fmt.Sprintf("%s/LC_MESSAGES/messages.po", ident), nil
+	case "properties":
+		return fmt.Sprintf("messages_%s.properties", ident), nil
+	case "xmlproperties":
+		return fmt.Sprintf("messages_%s.xml", ident), nil
+	}
+
+	exporter, ok := NewExporter(i18nType)
+	if !ok {
+		return ident, nil
+	}
+	return fmt.Sprintf("%s.%s", ident, exporter.FileExtension()), nil
+}
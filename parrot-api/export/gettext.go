@@ -2,13 +2,27 @@ package export
 
 import (
 	"bytes"
+	"strings"
 
 	"fmt"
 
+	"github.com/iris-contrib/parrot/parrot-api/errors"
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
-type Gettext struct{}
+var (
+	ErrDuplicateMsgid = &errors.Error{
+		Type:    "DuplicateMsgid",
+		Message: "entries with the same msgid can't be told apart, as keys have no context"}
+)
+
+type Gettext struct {
+	Comments map[string]string
+}
+
+func (e *Gettext) SetComments(comments map[string]string) {
+	e.Comments = comments
+}
 
 func (e *Gettext) FileExtension() string {
 	return "po"
@@ -17,14 +31,43 @@ func (e *Gettext) FileExtension() string {
 func (e *Gettext) Export(locale *model.Locale) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 
-	_, err := buf.WriteString(fmt.Sprintf("msgid \"\"\nmsgstr \"\"\n\"MIME-Version: 1.0\"\n\"Content-Type: text/plain; charset=UTF-8\"\n\"Content-Transfer-Encoding: 8bit\"\n\"Language: %s\"\n\n",
-		locale.Ident))
+	rule := locale.PluralRule()
+	_, err := buf.WriteString(fmt.Sprintf("msgid \"\"\nmsgstr \"\"\n\"MIME-Version: 1.0\\n\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n\"Content-Transfer-Encoding: 8bit\\n\"\n\"Language: %s\\n\"\n\"Plural-Forms: %s\\n\"\n\n",
+		locale.Ident, rule.GettextFormula))
 	if err != nil {
 		return nil, err
 	}
 
 	for k, v := range locale.Pairs {
-		_, err := buf.WriteString(fmt.Sprintf("msgid \"%s\"\nmsgstr \"%s\"\n\n", k, v))
+		if isPlural(locale, k) {
+			continue
+		}
+		_, err := buf.WriteString(poComment(e.Comments[k]))
+		if err != nil {
+			return nil, err
+		}
+		_, err = buf.WriteString(fmt.Sprintf("msgid \"%s\"\nmsgstr \"%s\"\n\n", k, v))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, k := range pluralKeys(locale) {
+		_, err := buf.WriteString(poComment(e.Comments[k]))
+		if err != nil {
+			return nil, err
+		}
+		_, err = buf.WriteString(fmt.Sprintf("msgid \"%s\"\nmsgid_plural \"%s\"\n", k, k))
+		if err != nil {
+			return nil, err
+		}
+		for i, c := range rule.Categories {
+			_, err := buf.WriteString(fmt.Sprintf("msgstr[%d] \"%s\"\n", i, locale.Plurals[k][c]))
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = buf.WriteString("\n")
 		if err != nil {
 			return nil, err
 		}
@@ -32,3 +75,165 @@ func (e *Gettext) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// poEntry holds the fields of a single .po entry while it is being parsed.
+type poEntry struct {
+	context string
+	id      string
+	plural  string
+	str     string
+	forms   map[int]*string
+	done    bool
+}
+
+// Import parses a .po file. The header entry and commented out entries are skipped.
+// Plural forms are mapped to categories using the plural rule of the language
+// declared in the header. Keys have no context, so files where a msgid appears
+// more than once, as with entries told apart by msgctxt, are rejected with a
+// validation error listing every such msgid.
+func (e *Gettext) Import(data []byte) (*model.Locale, error) {
+	loc := &model.Locale{
+		Pairs:   make(map[string]string),
+		Plurals: make(map[string]model.PluralForms)}
+	pluralForms := make(map[string]map[int]*string)
+	seen := make(map[string]bool)
+	var errs []errors.Error
+
+	var entry poEntry
+	var field *string
+	flush := func() {
+		duplicate := entry.done && entry.id != "" && seen[entry.id]
+		if duplicate {
+			errs = append(errs, errors.Error{
+				Type:    ErrDuplicateMsgid.Type,
+				Message: fmt.Sprintf("msgid '%s' appears more than once (msgctxt '%s')", entry.id, entry.context)})
+		}
+		if entry.done {
+			seen[entry.id] = true
+		}
+
+		switch {
+		case !entry.done || duplicate:
+			// nothing was parsed since the last entry, or the entry is rejected
+		case entry.id == "":
+			loc.Ident = poHeaderField(entry.str, "Language")
+		case entry.plural != "":
+			pluralForms[entry.id] = entry.forms
+		default:
+			loc.Pairs[entry.id] = entry.str
+		}
+		entry = poEntry{}
+		field = nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		keyword, rest := splitPOLine(line)
+
+		switch keyword {
+		case "":
+			flush()
+		case "#":
+			continue
+		case "msgctxt", "msgid":
+			if entry.done {
+				flush()
+			}
+			field = &entry.id
+			if keyword == "msgctxt" {
+				field = &entry.context
+			}
+		case "msgid_plural":
+			field = &entry.plural
+		case "msgstr":
+			entry.done = true
+			field = &entry.str
+		case "\"":
+			if field != nil {
+				*field += unquotePO(line)
+			}
+			continue
+		default:
+			var i int
+			if _, err := fmt.Sscanf(keyword, "msgstr[%d]", &i); err != nil {
+				return nil, ErrMalformedInput
+			}
+			if entry.forms == nil {
+				entry.forms = make(map[int]*string)
+			}
+			entry.forms[i] = new(string)
+			entry.done = true
+			field = entry.forms[i]
+		}
+
+		if field != nil && rest != "" {
+			*field = unquotePO(rest)
+		}
+	}
+	flush()
+
+	if errs != nil {
+		return nil, model.NewValidationError(errs)
+	}
+
+	rule := loc.PluralRule()
+	for k, indexed := range pluralForms {
+		forms := make(model.PluralForms)
+		for i, v := range indexed {
+			if i < len(rule.Categories) {
+				forms[rule.Categories[i]] = *v
+			}
+		}
+		loc.Plurals[k] = forms
+	}
+
+	return loc, nil
+}
+
+// poComment returns the comment as extracted comment lines, or an empty string if there is none.
+func poComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	result := ""
+	for _, line := range strings.Split(comment, "\n") {
+		result += "#. " + line + "\n"
+	}
+	return result
+}
+
+// poHeaderField returns the value of a field of the .po header entry.
+func poHeaderField(header, name string) string {
+	for _, line := range strings.Split(header, "\n") {
+		if strings.HasPrefix(line, name+":") {
+			return strings.TrimSpace(line[len(name)+1:])
+		}
+	}
+	return ""
+}
+
+// splitPOLine returns the keyword of a .po line and the remaining quoted string.
+// Comment lines return '#' and continuation lines return a double quote as keyword.
+func splitPOLine(line string) (string, string) {
+	switch {
+	case line == "":
+		return "", ""
+	case strings.HasPrefix(line, "#"):
+		return "#", ""
+	case strings.HasPrefix(line, "\""):
+		return "\"", line
+	}
+	i := strings.IndexAny(line, " \t")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], strings.TrimSpace(line[i:])
+}
+
+// unquotePO returns the unescaped content of a quoted .po string.
+func unquotePO(str string) string {
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		str = str[1 : len(str)-1]
+	}
+	return unescapeC(str)
+}
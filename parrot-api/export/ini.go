@@ -33,3 +33,22 @@ func (e *INI) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+func (e *INI) Import(data []byte) (*model.Locale, error) {
+	inFile, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := &model.Locale{Pairs: make(map[string]string)}
+	for _, section := range inFile.Sections() {
+		if section.Name() != ini.DEFAULT_SECTION && loc.Ident == "" {
+			loc.Ident = section.Name()
+		}
+		for _, key := range section.Keys() {
+			loc.Pairs[key.Name()] = key.Value()
+		}
+	}
+
+	return loc, nil
+}
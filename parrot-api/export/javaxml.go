@@ -65,3 +65,24 @@ func (e *JavaXML) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+type javaXMLDocument struct {
+	Entries []struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"entry"`
+}
+
+func (e *JavaXML) Import(data []byte) (*model.Locale, error) {
+	doc := javaXMLDocument{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[string]string)
+	for _, entry := range doc.Entries {
+		pairs[entry.Key] = entry.Value
+	}
+
+	return &model.Locale{Pairs: pairs}, nil
+}
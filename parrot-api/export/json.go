@@ -6,12 +6,67 @@ import (
 	"github.com/iris-contrib/parrot/parrot-api/model"
 )
 
-type JSON struct{}
+// JSON reads and writes locale pairs as a JSON object. With Nested set, keys are
+// written as nested objects, split on the delimiter, the way i18next expects them.
+type JSON struct {
+	Nested    bool
+	Delimiter string
+}
 
 func (e *JSON) FileExtension() string {
 	return "json"
 }
 
+// SetKeyDelimiter implements the Nester interface.
+func (e *JSON) SetKeyDelimiter(delimiter string) {
+	e.Delimiter = delimiter
+}
+
+// SetNested implements the Nester interface.
+func (e *JSON) SetNested(nested bool) {
+	e.Nested = nested
+}
+
+// Export writes the locale pairs as a JSON object. Plural keys are written
+// once per category with an i18next style suffix, e.g. 'key_one' and 'key_other'.
 func (e *JSON) Export(locale *model.Locale) ([]byte, error) {
-	return json.MarshalIndent(locale.Pairs, "", "    ")
+	data := make(map[string]string, len(locale.Pairs))
+	for k, v := range locale.Pairs {
+		if !isPlural(locale, k) {
+			data[k] = v
+		}
+	}
+	for k, forms := range locale.Plurals {
+		for c, v := range forms {
+			data[k+"_"+c] = v
+		}
+	}
+
+	if !e.Nested {
+		return json.MarshalIndent(data, "", "    ")
+	}
+	nested, err := nest(data, keyDelimiter(e.Delimiter))
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(nested, "", "    ")
+}
+
+// Import reads a flat or nested JSON object, joining nested keys with the delimiter.
+// With Nested set, pairs with an i18next plural suffix are read as plural forms.
+func (e *JSON) Import(data []byte) (*model.Locale, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	loc := &model.Locale{Pairs: make(map[string]string)}
+	if err := flattenNested("", doc, keyDelimiter(e.Delimiter), loc.Pairs); err != nil {
+		return nil, err
+	}
+	if e.Nested {
+		splitPlurals(loc)
+	}
+
+	return loc, nil
 }
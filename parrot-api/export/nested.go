@@ -0,0 +1,108 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+var (
+	ErrKeyConflict = &errors.Error{
+		Type:    "KeyConflict",
+		Message: "a key can't hold a value and nested keys"}
+)
+
+// pluralSuffixes lists the i18next plural suffixes by category.
+var pluralSuffixes = []string{
+	model.PluralZero, model.PluralOne, model.PluralTwo,
+	model.PluralFew, model.PluralMany, model.PluralOther,
+}
+
+// keyDelimiter returns the delimiter, or the default one if it is empty.
+func keyDelimiter(delimiter string) string {
+	if delimiter == "" {
+		return model.DefaultKeyDelimiter
+	}
+	return delimiter
+}
+
+// nest converts flat pairs into nested maps by splitting the keys on the delimiter.
+// A key can't be nested if another key is nested under it, as with 'a' and 'a.b':
+// the returned validation error lists every such conflict.
+func nest(pairs map[string]string, delimiter string) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	// Sorting puts every key before the keys nested under it
+	sort.Strings(keys)
+
+	result := make(map[string]interface{})
+	var errs []errors.Error
+	for _, k := range keys {
+		parts := strings.Split(k, delimiter)
+		current, conflict := result, ""
+		for i, part := range parts[:len(parts)-1] {
+			switch node := current[part].(type) {
+			case nil:
+				next := make(map[string]interface{})
+				current[part] = next
+				current = next
+			case map[string]interface{}:
+				current = node
+			default:
+				conflict = strings.Join(parts[:i+1], delimiter)
+			}
+			if conflict != "" {
+				break
+			}
+		}
+		if conflict != "" {
+			errs = append(errs, errors.Error{
+				Type:    ErrKeyConflict.Type,
+				Message: fmt.Sprintf("key '%s' conflicts with key '%s' nested under it", conflict, k)})
+			continue
+		}
+		current[parts[len(parts)-1]] = pairs[k]
+	}
+
+	if errs != nil {
+		return nil, model.NewValidationError(errs)
+	}
+	return result, nil
+}
+
+// duplicateKeyError returns the validation error of a key read more than once, as when
+// a nested key is also written with the delimiter.
+func duplicateKeyError(key string) error {
+	return model.NewValidationError([]errors.Error{{
+		Type:    ErrKeyConflict.Type,
+		Message: fmt.Sprintf("key '%s' is defined more than once", key)}})
+}
+
+// splitPlurals moves the pairs with an i18next plural suffix, e.g. 'key_one', to the
+// plural forms of the locale. Keys without an 'other' form are left as they are.
+func splitPlurals(loc *model.Locale) {
+	for k := range loc.Pairs {
+		base := strings.TrimSuffix(k, "_"+model.PluralOther)
+		if base == k {
+			continue
+		}
+		forms := make(model.PluralForms)
+		for _, c := range pluralSuffixes {
+			if v, ok := loc.Pairs[base+"_"+c]; ok {
+				forms[c] = v
+			}
+		}
+		for c := range forms {
+			delete(loc.Pairs, base+"_"+c)
+		}
+		if loc.Plurals == nil {
+			loc.Plurals = make(map[string]model.PluralForms)
+		}
+		loc.Plurals[base] = forms
+	}
+}
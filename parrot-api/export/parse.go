@@ -0,0 +1,203 @@
+package export
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrMalformedInput = errors.New("export: malformed input")
+)
+
+// textScanner is a minimal scanner for formats made of quoted strings,
+// such as Apple .strings and PHP arrays.
+type textScanner struct {
+	src string
+	pos int
+}
+
+// eof returns true if the whole input has been consumed.
+func (s *textScanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+// peek returns the next byte without consuming it.
+func (s *textScanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+// skipSpaceAndComments advances past whitespace and C-style comments.
+func (s *textScanner) skipSpaceAndComments() {
+	for !s.eof() {
+		switch {
+		case strings.HasPrefix(s.src[s.pos:], "//"):
+			end := strings.IndexByte(s.src[s.pos:], '\n')
+			if end < 0 {
+				s.pos = len(s.src)
+				return
+			}
+			s.pos += end + 1
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			end := strings.Index(s.src[s.pos+2:], "*/")
+			if end < 0 {
+				s.pos = len(s.src)
+				return
+			}
+			s.pos += end + 4
+		case isSpace(s.src[s.pos]):
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume advances past the provided token if it is next in the input.
+func (s *textScanner) consume(token string) bool {
+	if strings.HasPrefix(s.src[s.pos:], token) {
+		s.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// quoted reads a string delimited by the quote character at the current position
+// and returns its unescaped content.
+func (s *textScanner) quoted() (string, error) {
+	quote := s.peek()
+	if quote != '"' && quote != '\'' {
+		return "", ErrMalformedInput
+	}
+	start := s.pos + 1
+	for i := start; i < len(s.src); i++ {
+		switch s.src[i] {
+		case '\\':
+			i++
+		case quote:
+			s.pos = i + 1
+			return unescapeC(s.src[start:i]), nil
+		}
+	}
+	return "", ErrMalformedInput
+}
+
+// unescapeC replaces C-style backslash escape sequences. Unknown sequences
+// are replaced by the escaped character itself.
+func unescapeC(str string) string {
+	if !strings.Contains(str, "\\") {
+		return str
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if c != '\\' || i == len(str)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch str[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u', 'U':
+			size := 4
+			if str[i] == 'U' {
+				size = 8
+			}
+			if i+size < len(str) {
+				if r, err := strconv.ParseUint(str[i+1:i+1+size], 16, 32); err == nil && utf8.ValidRune(rune(r)) {
+					b.WriteRune(rune(r))
+					i += size
+					continue
+				}
+			}
+			b.WriteByte(str[i])
+		default:
+			b.WriteByte(str[i])
+		}
+	}
+	return b.String()
+}
+
+// flattenNested converts a nested map into a flat map with keys joined by the separator.
+// Keys that join to the same key, as 'a.b' and 'b' nested under 'a', are a conflict.
+func flattenNested(prefix string, data map[string]interface{}, separator string, result map[string]string) error {
+	for k, v := range data {
+		key := k
+		if prefix != "" {
+			key = prefix + separator + k
+		}
+		switch v.(type) {
+		case string, nil, bool, int, int64, float64:
+			if _, ok := result[key]; ok {
+				return duplicateKeyError(key)
+			}
+		}
+		switch value := v.(type) {
+		case string:
+			result[key] = value
+		case nil:
+			result[key] = ""
+		case map[string]interface{}:
+			if err := flattenNested(key, value, separator, result); err != nil {
+				return err
+			}
+		case map[interface{}]interface{}:
+			converted, err := stringMap(value)
+			if err != nil {
+				return err
+			}
+			if err := flattenNested(key, converted, separator, result); err != nil {
+				return err
+			}
+		case bool, int, int64, float64:
+			result[key] = toString(value)
+		default:
+			return ErrMalformedInput
+		}
+	}
+	return nil
+}
+
+// stringMap converts the generic maps produced by the yaml decoder.
+func stringMap(data map[interface{}]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		s, ok := k.(string)
+		if !ok {
+			return nil, ErrMalformedInput
+		}
+		result[s] = v
+	}
+	return result, nil
+}
+
+// toString formats scalar values found in structured documents.
+func toString(v interface{}) string {
+	switch value := v.(type) {
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -43,3 +43,33 @@ func (e *PHP) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// Import parses the quoted key value entries of a PHP array,
+// ignoring the surrounding code.
+func (e *PHP) Import(data []byte) (*model.Locale, error) {
+	s := &textScanner{src: string(data)}
+	pairs := make(map[string]string)
+
+	for !s.eof() {
+		if c := s.peek(); c != '"' && c != '\'' {
+			s.pos++
+			continue
+		}
+		key, err := s.quoted()
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpaceAndComments()
+		if !s.consume("=>") {
+			continue
+		}
+		s.skipSpaceAndComments()
+		value, err := s.quoted()
+		if err != nil {
+			return nil, err
+		}
+		pairs[key] = value
+	}
+
+	return &model.Locale{Pairs: pairs}, nil
+}
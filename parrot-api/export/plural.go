@@ -0,0 +1,36 @@
+package export
+
+import (
+	"sort"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// pluralKeys returns the sorted keys of the locale's plural forms.
+func pluralKeys(locale *model.Locale) []string {
+	keys := make([]string, 0, len(locale.Plurals))
+	for k := range locale.Plurals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isPlural returns true if the key holds plural forms in the locale,
+// in which case plural-aware formats export it instead of its pair.
+func isPlural(locale *model.Locale, key string) bool {
+	_, ok := locale.Plurals[key]
+	return ok
+}
+
+// pluralCategories returns the categories of the plural forms in the order
+// given by the locale's plural rule.
+func pluralCategories(locale *model.Locale, forms model.PluralForms) []string {
+	var categories []string
+	for _, c := range locale.PluralRule().Categories {
+		if _, ok := forms[c]; ok {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"github.com/iris-contrib/parrot/parrot-api/model"
+	"github.com/iris-contrib/parrot/parrot-api/translate"
+)
+
+// Pseudo wraps an exporter to export pseudo-localized values instead of the ones of
+// the locale, so that hard-coded strings and truncated layouts stand out in the UI
+// before real translations arrive. Placeholders are kept as they are.
+type Pseudo struct {
+	Exporter
+	// Expansion is the percentage by which values are lengthened.
+	Expansion int
+}
+
+// NewPseudo returns an exporter that pseudo-localizes the values exported by e.
+func NewPseudo(e Exporter, expansion int) *Pseudo {
+	return &Pseudo{Exporter: e, Expansion: expansion}
+}
+
+// Export implements the Exporter interface. The locale is left unchanged.
+func (p *Pseudo) Export(loc *model.Locale) ([]byte, error) {
+	pseudo := *loc
+	pseudo.Pairs = make(map[string]string, len(loc.Pairs))
+	for k, v := range loc.Pairs {
+		pseudo.Pairs[k] = translate.Pseudolocalize(v, p.Expansion)
+	}
+	pseudo.Plurals = make(map[string]model.PluralForms, len(loc.Plurals))
+	for k, forms := range loc.Plurals {
+		pseudo.Plurals[k] = make(model.PluralForms, len(forms))
+		for c, v := range forms {
+			pseudo.Plurals[k][c] = translate.Pseudolocalize(v, p.Expansion)
+		}
+	}
+
+	return p.Exporter.Export(&pseudo)
+}
+
+// SetComments implements the Commenter interface, if the wrapped exporter does.
+func (p *Pseudo) SetComments(comments map[string]string) {
+	if c, ok := p.Exporter.(Commenter); ok {
+		c.SetComments(comments)
+	}
+}
+
+// SetKeyDelimiter implements the Nester interface, if the wrapped exporter does.
+func (p *Pseudo) SetKeyDelimiter(delimiter string) {
+	if n, ok := p.Exporter.(Nester); ok {
+		n.SetKeyDelimiter(delimiter)
+	}
+}
+
+// SetNested implements the Nester interface, if the wrapped exporter does.
+func (p *Pseudo) SetNested(nested bool) {
+	if n, ok := p.Exporter.(Nester); ok {
+		n.SetNested(nested)
+	}
+}
@@ -0,0 +1,211 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// Stringsdict exports the plural keys of a locale as an Apple .stringsdict property list.
+// Regular pairs are not part of the format and should be exported with AppleStrings.
+type Stringsdict struct{}
+
+// stringsdictVariable is the name of the format variable holding the plural forms.
+const stringsdictVariable = "value"
+
+func (e *Stringsdict) FileExtension() string {
+	return "stringsdict"
+}
+
+func (e *Stringsdict) Export(locale *model.Locale) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	encoder := xml.NewEncoder(buf)
+
+	encoder.Indent("", "  ")
+
+	_, err := buf.Write([]byte(xml.Header))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = buf.Write([]byte("<!DOCTYPE plist PUBLIC \"-//Apple//DTD PLIST 1.0//EN\" \"http://www.apple.com/DTDs/PropertyList-1.0.dtd\">\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "plist"},
+		Attr: []xml.Attr{xml.Attr{Name: xml.Name{Local: "version"}, Value: "1.0"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "dict"}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range pluralKeys(locale) {
+		forms := locale.Plurals[k]
+
+		elems := []string{"key", k}
+		if err := encodePlistElements(encoder, elems...); err != nil {
+			return nil, err
+		}
+		if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "dict"}}); err != nil {
+			return nil, err
+		}
+
+		elems = []string{
+			"key", "NSStringLocalizedFormatKey",
+			"string", "%#@" + stringsdictVariable + "@",
+			"key", stringsdictVariable,
+		}
+		if err := encodePlistElements(encoder, elems...); err != nil {
+			return nil, err
+		}
+		if err := encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "dict"}}); err != nil {
+			return nil, err
+		}
+
+		elems = []string{
+			"key", "NSStringFormatSpecTypeKey",
+			"string", "NSStringPluralRuleType",
+			"key", "NSStringFormatValueTypeKey",
+			"string", "d",
+		}
+		for _, c := range pluralCategories(locale, forms) {
+			elems = append(elems, "key", c, "string", forms[c])
+		}
+		if err := encodePlistElements(encoder, elems...); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "dict"}}); err != nil {
+			return nil, err
+		}
+		if err := encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "dict"}}); err != nil {
+			return nil, err
+		}
+	}
+
+	err = encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "dict"}})
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "plist"}})
+	if err != nil {
+		return nil, err
+	}
+
+	err = encoder.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodePlistElements encodes pairs of element names and their text content.
+func encodePlistElements(encoder *xml.Encoder, nameValues ...string) error {
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		start := xml.StartElement{Name: xml.Name{Local: nameValues[i]}}
+		if err := encoder.EncodeElement(nameValues[i+1], start); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import reads the plural forms of every key in a .stringsdict property list.
+func (e *Stringsdict) Import(data []byte) (*model.Locale, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root interface{}
+	for root == nil {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil, ErrMalformedInput
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "dict" {
+			root, err = decodePlistValue(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	loc := &model.Locale{
+		Pairs:   make(map[string]string),
+		Plurals: make(map[string]model.PluralForms)}
+
+	entries, _ := root.(map[string]interface{})
+	for k, v := range entries {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Find the variable that holds the plural rule, whatever its name is
+		for _, variable := range entry {
+			rule, ok := variable.(map[string]interface{})
+			if !ok || rule["NSStringFormatSpecTypeKey"] != "NSStringPluralRuleType" {
+				continue
+			}
+			forms := make(model.PluralForms)
+			for _, c := range []string{model.PluralZero, model.PluralOne, model.PluralTwo, model.PluralFew, model.PluralMany, model.PluralOther} {
+				if value, ok := rule[c].(string); ok {
+					forms[c] = value
+				}
+			}
+			loc.Plurals[k] = forms
+			break
+		}
+	}
+
+	return loc, nil
+}
+
+// decodePlistValue decodes the property list element that starts with start.
+// Dictionaries are decoded as maps and strings as strings, other types are skipped.
+func decodePlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "string":
+		var s string
+		err := decoder.DecodeElement(&s, &start)
+		return s, err
+	case "dict":
+		result := make(map[string]interface{})
+		key := ""
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := decodePlistValue(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				result[key] = v
+			case xml.EndElement:
+				return result, nil
+			}
+		}
+	default:
+		return nil, decoder.Skip()
+	}
+}
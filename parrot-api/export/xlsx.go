@@ -36,3 +36,28 @@ func (e *XLSX) Export(locale *model.Locale) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+func (e *XLSX) Import(data []byte) (*model.Locale, error) {
+	f, err := xlsx.OpenBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Sheets) == 0 {
+		return nil, ErrMalformedInput
+	}
+
+	sheet := f.Sheets[0]
+	pairs := make(map[string]string)
+	for _, r := range sheet.Rows {
+		if len(r.Cells) == 0 || r.Cells[0].Value == "" {
+			continue
+		}
+		value := ""
+		if len(r.Cells) > 1 {
+			value = r.Cells[1].Value
+		}
+		pairs[r.Cells[0].Value] = value
+	}
+
+	return &model.Locale{Ident: sheet.Name, Pairs: pairs}, nil
+}
@@ -1,24 +1,44 @@
 package export
 
 import (
-	"strings"
-
 	"github.com/iris-contrib/parrot/parrot-api/model"
 	"gopkg.in/yaml.v2"
 )
 
-type Yaml struct{}
+// Yaml reads and writes locale pairs as a YAML document under the locale ident.
+// With Nested set, keys are written as nested mappings, split on the delimiter.
+type Yaml struct {
+	Nested    bool
+	Delimiter string
+}
 
 func (e *Yaml) FileExtension() string {
 	return "yaml"
 }
 
-// TODO: allow for non-nested style export.
-// What about formats like excel and apple strings?
+// SetKeyDelimiter implements the Nester interface.
+func (e *Yaml) SetKeyDelimiter(delimiter string) {
+	e.Delimiter = delimiter
+}
+
+// SetNested implements the Nester interface.
+func (e *Yaml) SetNested(nested bool) {
+	e.Nested = nested
+}
+
+// TODO: What about formats like excel and apple strings?
 func (e *Yaml) Export(locale *model.Locale) ([]byte, error) {
-	nestedPairs := getNestedKVPairs(locale.Pairs, ".")
+	var pairs interface{} = locale.Pairs
+	if e.Nested {
+		nested, err := nest(locale.Pairs, keyDelimiter(e.Delimiter))
+		if err != nil {
+			return nil, err
+		}
+		pairs = nested
+	}
+
 	data := make(map[string]interface{})
-	data[locale.Ident] = nestedPairs
+	data[locale.Ident] = pairs
 	result, err := yaml.Marshal(data)
 	if err != nil {
 		return nil, err
@@ -26,21 +46,31 @@ func (e *Yaml) Export(locale *model.Locale) ([]byte, error) {
 	return result, nil
 }
 
-func getNestedKVPairs(pairs map[string]string, separator string) interface{} {
-	data := make(map[string]interface{})
-	for k, v := range pairs {
-		nesting := strings.Split(k, separator)
-		current := data
-		for i, nk := range nesting {
-			if i < len(nesting)-1 {
-				if current[nk] == nil {
-					current[nk] = make(map[string]interface{})
+// Import parses a yaml document. If the document has a single root key, as
+// written by Export, it is treated as the locale ident.
+func (e *Yaml) Import(data []byte) (*model.Locale, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	loc := &model.Locale{Pairs: make(map[string]string)}
+	if len(doc) == 1 {
+		for k, v := range doc {
+			if nested, ok := v.(map[interface{}]interface{}); ok {
+				root, err := stringMap(nested)
+				if err != nil {
+					return nil, err
 				}
-				current = current[nk].(map[string]interface{})
-			} else {
-				current[nk] = v
+				loc.Ident = k
+				doc = root
 			}
 		}
 	}
-	return data
+
+	if err := flattenNested("", doc, keyDelimiter(e.Delimiter), loc.Pairs); err != nil {
+		return nil, err
+	}
+
+	return loc, nil
 }
@@ -10,6 +10,7 @@ require (
 	github.com/kataras/golog v0.0.9
 	github.com/kataras/iris/v12 v12.0.1
 	github.com/lib/pq v1.2.0
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/tealeg/xlsx v1.0.5
 	golang.org/x/crypto v0.0.0-20191029031824-8986dd9e96cf
 	gopkg.in/yaml.v2 v2.2.4
@@ -1,29 +1,41 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
-	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kataras/golog"
-	"github.com/kataras/iris/v12"
-	"github.com/kataras/iris/v12/middleware/logger"
-	"github.com/kataras/iris/v12/middleware/recover"
-
-	"github.com/iris-contrib/parrot/parrot-api/api"
-	"github.com/iris-contrib/parrot/parrot-api/auth"
-	"github.com/iris-contrib/parrot/parrot-api/datastore"
 )
 
+// errUsage is returned by commands called with invalid arguments, after printing their usage.
+var errUsage = errors.New("invalid usage")
+
+// command is a parrot-api subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "start the API server (default)", runServe},
+		{"migrate", "apply, revert or list database migrations", runMigrate},
+		{"user", "create users and reset their passwords", runUser},
+		{"project", "export projects", runProject},
+		{"client", "create API clients", runClient},
+	}
+}
+
 func init() {
 	// Config log
 	golog.SetOutput(os.Stdout)
 	golog.SetLevel("info")
 }
 
-// TODO: refactor this into cli to start server
 func main() {
 	// init environment variables
 	err := godotenv.Load()
@@ -31,120 +43,54 @@ func main() {
 		golog.Info(err)
 	}
 
-	// init and ping datastore
-	dbName := os.Getenv("PARROT_API_DB")
-	dbURL := os.Getenv("PARROT_API_DB_URL")
-	if dbName == "" || dbURL == "" {
-		golog.Fatal("no db set in env")
+	if err := run(os.Args[1:]); err != nil {
+		if err != errUsage {
+			fmt.Fprintf(os.Stderr, "parrot-api: %v\n", err)
+		}
+		os.Exit(1)
 	}
+}
 
-	ds, err := datastore.NewDatastore(dbName, dbURL)
-	if err != nil {
-		golog.Fatal(err)
+// run runs the subcommand named by the first argument. Without one, the
+// server is started, as it was before subcommands existed.
+func run(args []string) error {
+	if len(args) == 0 || (len(args[0]) > 0 && args[0][0] == '-') {
+		return runServe(args)
 	}
-	defer ds.Close()
 
-	// Ping DB until service is up, block meanwhile
-	blockAndRetry(5*time.Second, func() bool {
-		if err = ds.Ping(); err != nil {
-			golog.Error(fmt.Sprintf("failed to ping datastore.\nerr: %s", err))
-			return false
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:])
 		}
-		return true
-	})
-
-	migrate(dbName, ds)
-
-	app := iris.New()
-
-	app.Use(
-		recover.New(),
-		func(ctx iris.Context) {
-			ctx.Header("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
-			ctx.Next()
-		},
-		logger.New(),
-	)
-
-	signingKey := os.Getenv("PARROT_AUTH_SIGNING_KEY")
-	if signingKey == "" {
-		golog.Fatal("no auth signing key set")
 	}
-	issuerName := os.Getenv("PARROT_AUTH_ISSUER_NAME")
-	if signingKey == "" {
-		golog.Warn("no auth issuer name set, resorting to default")
-		issuerName = "parrot-default"
-	}
-
-	tp := auth.TokenProvider{Name: issuerName, SigningKey: []byte(signingKey)}
-	app.Configure(auth.NewRouter(ds, tp))
-	app.Configure(api.NewRouter(ds, tp))
 
-	// config and init server
-	addr := ":8080"
-	if os.Getenv("PARROT_API_HOST_PORT") != "" {
-		addr = os.Getenv("PARROT_API_HOST_PORT")
+	if args[0] != "help" && args[0] != "-h" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
 	}
-
-	srv := &http.Server{
-		Addr:           addr,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
-		MaxHeaderBytes: 1 << 20,
+	printUsage()
+	if args[0] == "help" {
+		return nil
 	}
-
-	app.Run(iris.Server(srv))
+	return errUsage
 }
 
-func migrate(dbName string, ds datastore.Store) {
-	migrationStrategy := os.Getenv("PARROT_DB_MIGRATION_STRATEGY")
-	if migrationStrategy != "" {
-		golog.Infof("migration strategy is set to '%s'", migrationStrategy)
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: parrot-api <command> [arguments]\n\nCommands:\n")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun 'parrot-api <command> -h' for the flags of a command. "+
+		"Every flag defaults to the value of the environment variable shown in its description.\n")
+}
 
-	dirPath := os.Getenv("PARROT_DB_MIGRATIONS_DIR")
-	if dirPath == "" {
-		dirPath = fmt.Sprintf("./datastore/%s/migrations", dbName)
-		golog.Infof("migrations directory not set, using default one: '%s'", dirPath)
-	}
-
-	var fn func(string) error
-
-	switch migrationStrategy {
-	// Case when we want to start clean each time
-	case "down,up":
-		fn = func(path string) error {
-			err := ds.MigrateDown(path)
-			if err != nil {
-				return err
-			}
-			err = ds.MigrateUp(path)
-			if err != nil {
-				return err
-			}
-			return nil
-		}
-	// Case when we want to apply migrations if needed
-	case "up":
-		fn = ds.MigrateUp
-	// Case when we want to simply drop everything
-	case "down":
-		fn = ds.MigrateDown
-	default:
-		golog.Fatalf("could not recognize migration strategy '%s'", migrationStrategy)
+// parseFlags parses the flags of a command. Asking for help is not an error.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		os.Exit(0)
 	}
-
-	golog.Info("migrating...")
-	err := fn(dirPath)
 	if err != nil {
-		golog.Fatal(err)
-	}
-	golog.Info("migration completed successfully")
-}
-
-func blockAndRetry(d time.Duration, fn func() bool) {
-	for !fn() {
-		golog.Infof("retrying in %s...\n", d.String())
-		time.Sleep(d)
+		return errUsage
 	}
+	return nil
 }
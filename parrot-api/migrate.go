@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "migrate up|down|status|to <version> [flags]"
+
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate", migrateUsage)
+	db := dbFlags(fs)
+	if len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	action, args := args[0], args[1:]
+	var version int64
+	if action == "to" {
+		if len(args) == 0 {
+			fs.Usage()
+			return errUsage
+		}
+		v, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version: %s", err)
+		}
+		version, args = v, args[1:]
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ds, err := db.open(false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	switch action {
+	case "up":
+		err = ds.MigrateUp(db.dir())
+	case "down":
+		err = ds.MigrateDown(db.dir())
+	case "to":
+		err = ds.MigrateTo(db.dir(), version)
+	case "status":
+	default:
+		fs.Usage()
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := ds.MigrationStatus(db.dir())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, m := range status {
+		state, appliedAt := "pending", ""
+		if m.Applied {
+			state = "applied"
+			if m.AppliedAt != nil {
+				appliedAt = m.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", m.Version, m.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrInvalidFallback = &errors.Error{
+		Type:    "InvalidFallback",
+		Message: "fallback chains must only hold other locales of the project"}
+)
+
+// ValidateFallbacks returns an error if the project's fallback chains refer to
+// locales that are not in string slice idents, to the locale itself, or repeat a locale.
+func (p *Project) ValidateFallbacks(idents []string) error {
+	var errs []errors.Error
+	for ident, chain := range p.Fallbacks {
+		if !contains(idents, ident) {
+			errs = append(errs, *ErrInvalidFallback)
+			continue
+		}
+		var seen []string
+		for _, f := range chain {
+			if f == ident || contains(seen, f) || !contains(idents, f) {
+				errs = append(errs, *ErrInvalidFallback)
+				break
+			}
+			seen = append(seen, f)
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// FallbackChain returns the idents of the locales, out of string slice idents, whose values
+// fill the empty values of the locale, in order. A chain set on the project takes precedence.
+// Otherwise the chain holds the other locales of the same language, starting with the
+// project's source locale if it is one of them, and ends with the source locale otherwise.
+// Projects whose variants should fall back in another order, such as fr_FR first for
+// fr_CA, set the chain by hand.
+func (p *Project) FallbackChain(ident string, idents []string) []string {
+	var chain []string
+
+	if manual, ok := p.Fallbacks[ident]; ok {
+		for _, f := range manual {
+			if f != ident && contains(idents, f) {
+				chain = append(chain, f)
+			}
+		}
+		return chain
+	}
+
+	language := localeLanguage(ident)
+	for _, f := range idents {
+		if f != ident && localeLanguage(f) == language {
+			chain = append(chain, f)
+		}
+	}
+	sort.Slice(chain, func(i, j int) bool {
+		si, sj := chain[i] == p.SourceLocale, chain[j] == p.SourceLocale
+		if si != sj {
+			return si
+		}
+		return chain[i] < chain[j]
+	})
+
+	if s := p.SourceLocale; s != "" && s != ident && contains(idents, s) && !contains(chain, s) {
+		chain = append(chain, s)
+	}
+
+	return chain
+}
+
+// localeLanguage returns the language of a locale ident. Standard locales use the
+// language of model.Locales, others the language code the ident starts with.
+func localeLanguage(ident string) string {
+	if info, ok := Locales[ident]; ok {
+		return info.Language
+	}
+	if i := strings.Index(ident, "_"); i >= 0 {
+		return ident[:i]
+	}
+	return ident
+}
+
+// Fill sets the empty values of the locale to the first non-empty value found in the
+// fallback locales and returns the number of values that were filled. Plural categories
+// missing from a fallback locale use its 'other' form.
+func (loc *Locale) Fill(fallbacks []Locale) int {
+	filled := 0
+	for k, v := range loc.Pairs {
+		if v != "" {
+			continue
+		}
+		for _, f := range fallbacks {
+			if f.Pairs[k] != "" {
+				loc.Pairs[k] = f.Pairs[k]
+				filled++
+				break
+			}
+		}
+	}
+
+	for k, forms := range loc.Plurals {
+		for c, v := range forms {
+			if v != "" {
+				continue
+			}
+			for _, f := range fallbacks {
+				value, ok := f.Plurals[k][c]
+				if !ok {
+					value = f.Plurals[k][PluralOther]
+				}
+				if value != "" {
+					forms[c] = value
+					filled++
+					break
+				}
+			}
+		}
+	}
+
+	return filled
+}
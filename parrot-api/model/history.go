@@ -0,0 +1,221 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// known history actions
+const (
+	HistoryPairUpdated = "pair_updated"
+	// HistoryPluralUpdated entries hold the plural forms of the key as JSON objects.
+	HistoryPluralUpdated = "plural_updated"
+	HistoryKeyAdded      = "key_added"
+	HistoryKeyRenamed    = "key_renamed"
+	HistoryKeyDeleted    = "key_deleted"
+	// HistoryKeyPluralUpdated entries hold whether the key was and is plural, 'true' or 'false'.
+	HistoryKeyPluralUpdated = "key_plural_updated"
+)
+
+// HistoryStorer is the interface to store the change history of project keys and locale pairs.
+type HistoryStorer interface {
+	AddHistoryEntries(entries []HistoryEntry) error
+	GetLocaleHistory(projID, localeIdent, key string) ([]HistoryEntry, error)
+	GetHistoryEntry(projID, entryID string) (*HistoryEntry, error)
+}
+
+// HistoryEntry is an append-only record of a single change to a locale pair or a project key.
+// Key level changes are not bound to a locale and have an empty LocaleIdent.
+type HistoryEntry struct {
+	ID          string    `db:"id" json:"id"`
+	ProjectID   string    `db:"project_id" json:"project_id"`
+	LocaleIdent string    `db:"locale_ident" json:"locale_ident,omitempty"`
+	Key         string    `db:"key" json:"key"`
+	Action      string    `db:"action" json:"action"`
+	OldValue    string    `db:"old_value" json:"old_value"`
+	NewValue    string    `db:"new_value" json:"new_value"`
+	SubjectID   string    `db:"subject_id" json:"subject_id"`
+	SubjectType string    `db:"subject_type" json:"subject_type"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// Journal records the history of a change on behalf of the subject making it.
+// Stores record the changes they apply in the journal and write its entries in
+// the same transaction as the change, so that the history neither misses a
+// change nor holds one that failed. A journal is meant for a single store call,
+// a nil one records nothing.
+//
+// The webhook events of the change are queued along with its history: the ones
+// describing the entries are derived from them, others are emitted on the journal.
+type Journal struct {
+	SubjectID   string
+	SubjectType string
+	Entries     []HistoryEntry
+
+	emitted []Event
+}
+
+// Record appends the entries to the journal on behalf of its subject.
+func (j *Journal) Record(entries ...HistoryEntry) {
+	if j == nil {
+		return
+	}
+	for _, e := range entries {
+		e.SubjectID = j.SubjectID
+		e.SubjectType = j.SubjectType
+		j.Entries = append(j.Entries, e)
+	}
+}
+
+// Emit records an event that is not derived from the entries of the journal.
+func (j *Journal) Emit(projectID, event string, data interface{}) {
+	if j == nil {
+		return
+	}
+	j.emitted = append(j.emitted, NewEvent(projectID, event, data))
+}
+
+// Events returns the events emitted on the journal, followed by the events
+// describing its entries. Changes to the pairs and plural forms of a locale
+// are described by a single locale.updated event listing the changed keys.
+func (j *Journal) Events() []Event {
+	if j == nil {
+		return nil
+	}
+	events := append([]Event(nil), j.emitted...)
+
+	var locales []HistoryEntry
+	localeKeys := make(map[string][]string)
+	for _, e := range j.Entries {
+		switch e.Action {
+		case HistoryKeyAdded:
+			events = append(events, NewEvent(e.ProjectID, EventKeyAdded, map[string]interface{}{"key": e.Key}))
+		case HistoryKeyRenamed:
+			events = append(events, NewEvent(e.ProjectID, EventKeyRenamed, map[string]interface{}{"old_key": e.OldValue, "new_key": e.NewValue}))
+		case HistoryKeyDeleted:
+			events = append(events, NewEvent(e.ProjectID, EventKeyDeleted, map[string]interface{}{"key": e.Key}))
+		case HistoryKeyPluralUpdated:
+			events = append(events, NewEvent(e.ProjectID, EventKeyUpdated, map[string]interface{}{"key": e.Key, "plural": e.NewValue == "true"}))
+		case HistoryPairUpdated, HistoryPluralUpdated:
+			keys, ok := localeKeys[e.LocaleIdent]
+			if !ok {
+				locales = append(locales, e)
+			}
+			if !contains(keys, e.Key) {
+				localeKeys[e.LocaleIdent] = append(keys, e.Key)
+			}
+		}
+	}
+
+	for _, e := range locales {
+		keys := localeKeys[e.LocaleIdent]
+		sort.Strings(keys)
+		events = append(events, NewEvent(e.ProjectID, EventLocaleUpdated, map[string]interface{}{
+			"locale": e.LocaleIdent,
+			"keys":   keys,
+		}))
+	}
+	return events
+}
+
+// RecordLocale records the changes made to a locale, given its state before them.
+func (j *Journal) RecordLocale(before, after *Locale) {
+	j.Record(PairChanges(after.ProjectID, after.Ident, before.Pairs, after.Pairs)...)
+	j.Record(PluralChanges(after.ProjectID, after.Ident, before.Plurals, after.Plurals)...)
+}
+
+// KeyChanges returns a history entry for each key of newKeys missing from oldKeys
+// and for each key of oldKeys missing from newKeys, sorted by key.
+func KeyChanges(projectID string, oldKeys, newKeys []string) []HistoryEntry {
+	entries := make([]HistoryEntry, 0)
+	for _, k := range newKeys {
+		if !contains(oldKeys, k) {
+			entries = append(entries, HistoryEntry{ProjectID: projectID, Key: k, Action: HistoryKeyAdded, NewValue: k})
+		}
+	}
+	for _, k := range oldKeys {
+		if !contains(newKeys, k) {
+			entries = append(entries, HistoryEntry{ProjectID: projectID, Key: k, Action: HistoryKeyDeleted, OldValue: k})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// KeyRenamed returns the history entry of a key renamed from oldKey to newKey.
+func KeyRenamed(projectID, oldKey, newKey string) HistoryEntry {
+	return HistoryEntry{ProjectID: projectID, Key: oldKey, Action: HistoryKeyRenamed, OldValue: oldKey, NewValue: newKey}
+}
+
+// KeyPluralUpdated returns the history entry of a key that became plural, or stopped being so.
+func KeyPluralUpdated(projectID, key string, plural bool) HistoryEntry {
+	return HistoryEntry{ProjectID: projectID, Key: key, Action: HistoryKeyPluralUpdated,
+		OldValue: strconv.FormatBool(!plural), NewValue: strconv.FormatBool(plural)}
+}
+
+// PairChanges returns a history entry for each pair in newPairs whose value differs
+// from the one in oldPairs, sorted by key. Pairs that are no longer present are ignored,
+// since they are only dropped when their key is deleted from the project.
+func PairChanges(projectID, localeIdent string, oldPairs, newPairs map[string]string) []HistoryEntry {
+	var keys []string
+	for k, v := range newPairs {
+		if oldPairs[k] != v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]HistoryEntry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, HistoryEntry{
+			ProjectID:   projectID,
+			LocaleIdent: localeIdent,
+			Key:         k,
+			Action:      HistoryPairUpdated,
+			OldValue:    oldPairs[k],
+			NewValue:    newPairs[k]})
+	}
+	return entries
+}
+
+// PluralChanges returns a history entry for each plural key in newPlurals whose forms
+// differ from the ones in oldPlurals, sorted by key. Like with pairs, plural keys that
+// are no longer present are ignored.
+func PluralChanges(projectID, localeIdent string, oldPlurals, newPlurals map[string]PluralForms) []HistoryEntry {
+	var keys []string
+	for k, forms := range newPlurals {
+		if !forms.Equal(oldPlurals[k]) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := make([]HistoryEntry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, HistoryEntry{
+			ProjectID:   projectID,
+			LocaleIdent: localeIdent,
+			Key:         k,
+			Action:      HistoryPluralUpdated,
+			OldValue:    pluralValue(oldPlurals[k]),
+			NewValue:    pluralValue(newPlurals[k])})
+	}
+	return entries
+}
+
+// pluralValue returns the non-empty forms as a JSON object, or an empty string if there are none.
+func pluralValue(forms PluralForms) string {
+	values := make(map[string]string, len(forms))
+	for c, v := range forms {
+		if v != "" {
+			values[c] = v
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
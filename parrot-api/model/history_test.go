@@ -0,0 +1,114 @@
+package model
+
+import "testing"
+
+func TestPairChanges(t *testing.T) {
+	old := map[string]string{"same": "a", "changed": "b", "dropped": "c"}
+	changes := PairChanges("p", "en_US", old, map[string]string{"same": "a", "changed": "B", "added": "d"})
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if changes[0].Key != "added" || changes[0].OldValue != "" || changes[0].NewValue != "d" {
+		t.Errorf("unexpected change %+v", changes[0])
+	}
+	if changes[1].Key != "changed" || changes[1].OldValue != "b" || changes[1].NewValue != "B" {
+		t.Errorf("unexpected change %+v", changes[1])
+	}
+	for _, c := range changes {
+		if c.ProjectID != "p" || c.LocaleIdent != "en_US" || c.Action != HistoryPairUpdated {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+}
+
+func TestPluralChanges(t *testing.T) {
+	old := map[string]PluralForms{"same": {"one": "a"}, "changed": {"one": "b", "other": "bs"}}
+	changes := PluralChanges("p", "en_US", old, map[string]PluralForms{
+		"same":    {"one": "a", "other": ""},
+		"changed": {"one": "b", "other": "Bs"},
+		"added":   {"one": "", "other": ""}})
+
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %v", changes)
+	}
+	c := changes[0]
+	if c.Key != "changed" || c.Action != HistoryPluralUpdated {
+		t.Errorf("unexpected change %+v", c)
+	}
+	if c.OldValue != `{"one":"b","other":"bs"}` || c.NewValue != `{"one":"b","other":"Bs"}` {
+		t.Errorf("expected the forms as JSON objects, got '%s' and '%s'", c.OldValue, c.NewValue)
+	}
+}
+
+func TestKeyChanges(t *testing.T) {
+	changes := KeyChanges("p", []string{"a", "b"}, []string{"b", "c"})
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if changes[0].Key != "a" || changes[0].Action != HistoryKeyDeleted || changes[0].OldValue != "a" {
+		t.Errorf("unexpected change %+v", changes[0])
+	}
+	if changes[1].Key != "c" || changes[1].Action != HistoryKeyAdded || changes[1].NewValue != "c" {
+		t.Errorf("unexpected change %+v", changes[1])
+	}
+}
+
+func TestJournalRecord(t *testing.T) {
+	var none *Journal
+	none.Record(HistoryEntry{Key: "a"})
+
+	j := &Journal{SubjectID: "id", SubjectType: "user"}
+	before := &Locale{ProjectID: "p", Ident: "en_US", Pairs: map[string]string{"a": "A"}}
+	after := before.Copy()
+	after.Pairs["a"] = "AA"
+	j.RecordLocale(before, after)
+
+	if len(j.Entries) != 1 || j.Entries[0].SubjectID != "id" || j.Entries[0].SubjectType != "user" || j.Entries[0].OldValue != "A" {
+		t.Errorf("expected the change on behalf of the subject, got %v", j.Entries)
+	}
+}
+
+func TestJournalEvents(t *testing.T) {
+	var none *Journal
+	none.Emit("p", EventLocaleCreated, nil)
+	if none.Events() != nil {
+		t.Error("expected no events from a nil journal")
+	}
+
+	j := &Journal{}
+	j.Emit("p", EventLocaleCreated, map[string]interface{}{"locale": "en_US"})
+	j.Record(KeyRenamed("p", "a", "b"))
+	j.Record(PairChanges("p", "en_US", nil, map[string]string{"d": "D", "c": "C"})...)
+	j.Record(PluralChanges("p", "en_US", nil, map[string]PluralForms{"c": {"one": "C"}})...)
+
+	events := j.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %v", events)
+	}
+	if events[0].Event != EventLocaleCreated || events[1].Event != EventKeyRenamed || events[2].Event != EventLocaleUpdated {
+		t.Errorf("unexpected events %v", events)
+	}
+	data := events[2].Data.(map[string]interface{})
+	if keys := data["keys"].([]string); data["locale"] != "en_US" || len(keys) != 2 || keys[0] != "c" || keys[1] != "d" {
+		t.Errorf("expected the changed keys of the locale once, got %v", data)
+	}
+}
+
+func TestJournalKeyPluralEvents(t *testing.T) {
+	j := &Journal{}
+	j.Record(KeyPluralUpdated("p", "items", true))
+	if e := j.Entries[0]; e.OldValue != "false" || e.NewValue != "true" {
+		t.Errorf("expected the plural flag to go from false to true, got %+v", e)
+	}
+
+	events := j.Events()
+	if len(events) != 1 || events[0].Event != EventKeyUpdated {
+		t.Fatalf("expected a %s event, got %v", EventKeyUpdated, events)
+	}
+	data := events[0].Data.(map[string]interface{})
+	if data["key"] != "items" || data["plural"] != true {
+		t.Errorf("expected the key and its plural flag, got %v", data)
+	}
+}
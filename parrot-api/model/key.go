@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrInvalidKeyMaxLength = &errors.Error{
+		Type:    "InvalidKeyMaxLength",
+		Message: "invalid field key max length"}
+	ErrValueTooLong = &errors.Error{
+		Type:    "ValueTooLong",
+		Message: "value exceeds the key's max length"}
+)
+
+// KeyStorer is the interface to store the metadata of project keys.
+type KeyStorer interface {
+	GetProjectKeys(projectID string) ([]Key, error)
+	GetProjectKey(projectID, key string) (*Key, error)
+	UpdateProjectKeyMeta(key Key) (*Key, error)
+}
+
+// Key holds the metadata of a project key. Keys are added, renamed and
+// deleted through the project, which keeps their metadata in sync.
+type Key struct {
+	ProjectID   string    `db:"project_id" json:"project_id"`
+	Key         string    `db:"key" json:"key"`
+	Description string    `db:"description" json:"description"`
+	Context     string    `db:"context" json:"context"`
+	MaxLength   int       `db:"max_length" json:"max_length,omitempty"`
+	Tags        []string  `db:"tags" json:"tags"`
+	Plural      bool      `db:"-" json:"plural"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Validate returns an error if the key's metadata is invalid.
+func (k *Key) Validate() error {
+	var errs []errors.Error
+	if k.MaxLength < 0 {
+		errs = append(errs, *ErrInvalidKeyMaxLength)
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// SanitizeTags removes empty and duplicate tags and sorts the rest.
+func (k *Key) SanitizeTags() {
+	tags := make([]string, 0, len(k.Tags))
+	for _, t := range k.Tags {
+		if t == "" || contains(tags, t) {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	k.Tags = tags
+}
+
+// Fits returns true if the value is within the key's max length, counted in characters.
+// Keys without a max length accept values of any length.
+func (k *Key) Fits(value string) bool {
+	return k.MaxLength == 0 || utf8.RuneCountInString(value) <= k.MaxLength
+}
+
+// ValidateLength returns an error for every pair or plural form of the locale whose
+// value is longer than the max length of its key.
+func (loc *Locale) ValidateLength(keys []Key) error {
+	var errs []errors.Error
+	for _, k := range keys {
+		if k.MaxLength == 0 {
+			continue
+		}
+		values := []string{loc.Pairs[k.Key]}
+		for _, v := range loc.Plurals[k.Key] {
+			values = append(values, v)
+		}
+		for _, v := range values {
+			if !k.Fits(v) {
+				errs = append(errs, errors.Error{
+					Type:    ErrValueTooLong.Type,
+					Message: fmt.Sprintf("value of key '%s' exceeds its max length of %d", k.Key, k.MaxLength)})
+				break
+			}
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// KeyDescriptions returns the non-empty descriptions of the keys, by key.
+func KeyDescriptions(keys []Key) map[string]string {
+	result := make(map[string]string)
+	for _, k := range keys {
+		if k.Description != "" {
+			result[k.Key] = k.Description
+		}
+	}
+	return result
+}
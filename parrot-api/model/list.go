@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrInvalidCursor = errors.New(
+		http.StatusBadRequest,
+		"InvalidCursor",
+		"invalid pagination cursor")
+)
+
+// Sortable fields of the lists, the first one is the default.
+var (
+	ProjectSortFields       = []string{"name", "id"}
+	LocaleSortFields        = []string{"ident", "language", "country"}
+	ProjectUserSortFields   = []string{"name", "email"}
+	ProjectClientSortFields = []string{"name", "id"}
+)
+
+// ListOptions selects a page of a list sorted by one of its fields.
+// Items with the same sort value are ordered by id, so that pages don't overlap.
+type ListOptions struct {
+	// Limit is the maximum number of items, zero means no limit.
+	Limit int
+	// Sort is the field to sort by, Desc reverses the order.
+	Sort string
+	Desc bool
+	// After is the position after which the page starts, nil for the first page.
+	After *Cursor
+}
+
+// LocaleQuery selects a page of the locales of a project.
+type LocaleQuery struct {
+	ListOptions
+	// Idents restricts the locales to the ones with these idents, if any.
+	Idents []string
+	// WithoutPairs skips loading the pairs, plural forms and statuses of the locales.
+	WithoutPairs bool
+}
+
+// Cursor is the position of an item in a sorted list: its sort value and id.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Encode returns the cursor as an opaque string.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor returned by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := Cursor{}
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort == "" || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// SortField returns the sort field if it is one of fields, otherwise the default one.
+func (o ListOptions) SortField(fields []string) string {
+	for _, f := range fields {
+		if f == o.Sort {
+			return f
+		}
+	}
+	return fields[0]
+}
+
+// NextCursor returns the cursor of the page following the n items of a page,
+// fetched with one extra item to tell whether there are more. It returns nil if
+// the page is the last one, otherwise the number of items to keep is the limit.
+// key returns the sort value and id of the item at the index.
+func (o ListOptions) NextCursor(n int, key func(i int) (string, string)) *Cursor {
+	if o.Limit <= 0 || n <= o.Limit {
+		return nil
+	}
+	value, id := key(o.Limit - 1)
+	return &Cursor{Sort: o.Sort, Desc: o.Desc, Value: value, ID: id}
+}
+
+// SortValue returns the value of the project field named by a ProjectSortFields entry.
+func (p *Project) SortValue(field string) string {
+	if field == "id" {
+		return p.ID
+	}
+	return p.Name
+}
+
+// SortValue returns the value of the locale field named by a LocaleSortFields entry.
+func (l *Locale) SortValue(field string) string {
+	switch field {
+	case "language":
+		return l.Language
+	case "country":
+		return l.Country
+	}
+	return l.Ident
+}
+
+// SortValue returns the value of the project user field named by a ProjectUserSortFields entry.
+func (u *ProjectUser) SortValue(field string) string {
+	if field == "email" {
+		return u.Email
+	}
+	return u.Name
+}
+
+// SortValue returns the value of the project client field named by a ProjectClientSortFields entry.
+func (c *ProjectClient) SortValue(field string) string {
+	if field == "id" {
+		return c.ClientID
+	}
+	return c.Name
+}
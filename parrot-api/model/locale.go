@@ -17,23 +17,30 @@ var (
 
 // LocaleStorer is the interface to store locales.
 type LocaleStorer interface {
-	CreateLocale(loc Locale) (*Locale, error)
-	DeleteLocale(projID string, ident string) error
+	CreateLocale(loc Locale, j *Journal) (*Locale, error)
+	DeleteLocale(projID string, ident string, j *Journal) error
 }
 
 type Locale struct {
-	ID        string            `db:"id" json:"id"`
-	Ident     string            `db:"ident" json:"ident"`
-	Language  string            `db:"language" json:"language"`
-	Country   string            `db:"country" json:"country"`
-	Pairs     map[string]string `db:"pairs" json:"pairs"`
-	ProjectID string            `db:"project_id" json:"project_id"`
+	ID        string                 `db:"id" json:"id"`
+	Ident     string                 `db:"ident" json:"ident"`
+	Language  string                 `db:"language" json:"language"`
+	Country   string                 `db:"country" json:"country"`
+	Pairs     map[string]string      `db:"pairs" json:"pairs"`
+	Plurals   map[string]PluralForms `db:"plurals" json:"plurals,omitempty"`
+	Statuses  map[string]string      `db:"statuses" json:"statuses,omitempty"`
+	ProjectID string                 `db:"project_id" json:"project_id"`
+	// Version is incremented by every update of the locale.
+	Version int `db:"version" json:"version"`
 }
 
+// PluralForms maps CLDR plural categories to the values of a plural key.
+type PluralForms map[string]string
+
 // Validate returns an error if the locale's data is invalid.
 func (loc *Locale) Validate() error {
 	var errs []errors.Error
-	if !HasMinLength(loc.Ident, 2) {
+	if !ValidLocaleIdent(loc.Ident) {
 		errs = append(errs, *ErrInvalidLocaleIdent)
 	}
 	if !HasMinLength(loc.Language, 1) {
@@ -64,3 +71,172 @@ func (loc *Locale) SyncKeys(t []string) {
 
 	loc.Pairs = temp
 }
+
+// MergePairs merges the provided pairs into the document pairs and returns
+// the number of values that changed. Existing non-empty values are only
+// replaced if overwrite is true.
+func (loc *Locale) MergePairs(pairs map[string]string, overwrite bool) int {
+	if loc.Pairs == nil {
+		loc.Pairs = make(map[string]string)
+	}
+
+	changed := 0
+	for k, v := range pairs {
+		current, ok := loc.Pairs[k]
+		if ok && current != "" && !overwrite {
+			continue
+		}
+		if ok && current == v {
+			continue
+		}
+		loc.Pairs[k] = v
+		changed++
+	}
+
+	return changed
+}
+
+// MergePlurals merges the provided plural forms into the document plural forms and
+// returns the number of values that changed. Only keys and categories already present
+// are merged, so SyncPluralKeys should be called first. Existing non-empty values are
+// only replaced if overwrite is true.
+func (loc *Locale) MergePlurals(plurals map[string]PluralForms, overwrite bool) int {
+	changed := 0
+	for k, forms := range plurals {
+		current, ok := loc.Plurals[k]
+		if !ok {
+			continue
+		}
+		for c, v := range forms {
+			value, ok := current[c]
+			if !ok || value == v || (value != "" && !overwrite) {
+				continue
+			}
+			current[c] = v
+			changed++
+		}
+	}
+
+	return changed
+}
+
+// SetPairs replaces the document pairs. Values that change lose their review status.
+func (loc *Locale) SetPairs(pairs map[string]string) {
+	for k, v := range loc.Pairs {
+		if nv, ok := pairs[k]; !ok || nv != v {
+			delete(loc.Statuses, k)
+		}
+	}
+	loc.Pairs = pairs
+}
+
+// PutPairs sets the provided pairs and keeps the other document pairs.
+// Values that change lose their review status.
+func (loc *Locale) PutPairs(pairs map[string]string) {
+	if loc.Pairs == nil {
+		loc.Pairs = make(map[string]string)
+	}
+	for k, v := range pairs {
+		if ov, ok := loc.Pairs[k]; !ok || ov != v {
+			delete(loc.Statuses, k)
+		}
+		loc.Pairs[k] = v
+	}
+}
+
+// SetPlurals replaces the document plural forms. Plural forms that change lose
+// their review status.
+func (loc *Locale) SetPlurals(plurals map[string]PluralForms) {
+	for k, forms := range loc.Plurals {
+		if nf, ok := plurals[k]; !ok || !forms.Equal(nf) {
+			delete(loc.Statuses, k)
+		}
+	}
+	loc.Plurals = plurals
+}
+
+// Copy returns a copy of the locale that shares none of its maps.
+func (loc *Locale) Copy() *Locale {
+	result := *loc
+	result.Pairs = make(map[string]string, len(loc.Pairs))
+	for k, v := range loc.Pairs {
+		result.Pairs[k] = v
+	}
+	result.Statuses = make(map[string]string, len(loc.Statuses))
+	for k, v := range loc.Statuses {
+		result.Statuses[k] = v
+	}
+	result.Plurals = make(map[string]PluralForms, len(loc.Plurals))
+	for k, forms := range loc.Plurals {
+		copied := make(PluralForms, len(forms))
+		for c, v := range forms {
+			copied[c] = v
+		}
+		result.Plurals[k] = copied
+	}
+	return &result
+}
+
+// Equal returns true if both hold the same values, a missing category being empty.
+func (forms PluralForms) Equal(other PluralForms) bool {
+	for c, v := range forms {
+		if other[c] != v {
+			return false
+		}
+	}
+	for c, v := range other {
+		if forms[c] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PluralRule returns the plural rule of the locale's language. Standard locales
+// are matched by ident, otherwise the language field is used.
+func (loc *Locale) PluralRule() PluralRule {
+	if info, ok := Locales[loc.Ident]; ok {
+		return PluralRuleFor(info.Language)
+	}
+	return PluralRuleFor(loc.Language)
+}
+
+// SyncPluralKeys keeps the plural forms of the keys in string slice t and makes sure
+// each of them holds every category required by the locale's language.
+func (loc *Locale) SyncPluralKeys(t []string) {
+	rule := loc.PluralRule()
+	temp := make(map[string]PluralForms)
+
+	for _, k := range t {
+		forms := make(PluralForms)
+		for _, c := range rule.Categories {
+			forms[c] = loc.Plurals[k][c]
+		}
+		temp[k] = forms
+	}
+
+	loc.Plurals = temp
+}
+
+// ValidatePlurals returns an error if any plural forms belong to a key that is not in
+// string slice pluralKeys or use a category that the locale's language does not have.
+func (loc *Locale) ValidatePlurals(pluralKeys []string) error {
+	rule := loc.PluralRule()
+
+	var errs []errors.Error
+	for k, forms := range loc.Plurals {
+		if !contains(pluralKeys, k) {
+			errs = append(errs, *ErrInvalidPluralKey)
+			continue
+		}
+		for c := range forms {
+			if !rule.HasCategory(c) {
+				errs = append(errs, *ErrInvalidPluralCategory)
+			}
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package model
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
 
 func TestLocaleSyncKeys(t *testing.T) {
 	l := Locale{}
@@ -20,3 +24,178 @@ func TestLocaleSyncKeys(t *testing.T) {
 		t.Fatal("expected 'testkey2' to not be present")
 	}
 }
+
+func TestLocaleMergePairs(t *testing.T) {
+	l := Locale{Pairs: map[string]string{"kept": "old", "empty": ""}}
+
+	changed := l.MergePairs(map[string]string{"kept": "new", "empty": "filled", "added": "value"}, false)
+	if changed != 2 {
+		t.Fatalf("expected 2 changed pairs, got %d", changed)
+	}
+	if l.Pairs["kept"] != "old" {
+		t.Fatal("expected 'kept' to keep its existing value")
+	}
+	if l.Pairs["empty"] != "filled" || l.Pairs["added"] != "value" {
+		t.Fatal("expected empty and missing pairs to be filled")
+	}
+
+	changed = l.MergePairs(map[string]string{"kept": "new"}, true)
+	if changed != 1 || l.Pairs["kept"] != "new" {
+		t.Fatal("expected 'kept' to be overwritten")
+	}
+}
+
+func TestLocaleSyncPluralKeys(t *testing.T) {
+	l := Locale{
+		Ident: "pl_PL",
+		Plurals: map[string]PluralForms{
+			"files":   {"one": "plik"},
+			"removed": {"one": "x"},
+		},
+	}
+
+	l.SyncPluralKeys([]string{"files"})
+	if _, ok := l.Plurals["removed"]; ok {
+		t.Fatal("expected 'removed' to not be present")
+	}
+	forms := l.Plurals["files"]
+	if forms["one"] != "plik" {
+		t.Fatal("expected existing plural form to be kept")
+	}
+	for _, c := range []string{"one", "few", "many", "other"} {
+		if _, ok := forms[c]; !ok {
+			t.Fatalf("expected category '%s' to be present", c)
+		}
+	}
+
+	l.Plurals["files"]["two"] = "pliki"
+	if err := l.ValidatePlurals([]string{"files"}); err == nil {
+		t.Fatal("expected category 'two' to be rejected for Polish")
+	}
+}
+
+func TestLocaleStats(t *testing.T) {
+	l := Locale{
+		Ident: "en_US",
+		Pairs: map[string]string{"a": "A", "b": "", "apples": "", "pears": ""},
+		Plurals: map[string]PluralForms{
+			"apples": {PluralOne: "apple", PluralOther: "apples"},
+			"pears":  {PluralOne: "pear", PluralOther: ""},
+		},
+	}
+
+	stats := l.Stats()
+	if stats.Total != 4 || stats.Translated != 2 || stats.Empty != 2 {
+		t.Fatalf("expected 2 of 4 keys translated but got %+v", stats)
+	}
+	if stats.Progress != 50 {
+		t.Fatalf("expected progress 50 but got %v", stats.Progress)
+	}
+
+	l.RemoveUntranslated()
+	if _, ok := l.Pairs["b"]; ok {
+		t.Fatal("expected 'b' to be removed")
+	}
+	if _, ok := l.Plurals["pears"]; ok {
+		t.Fatal("expected 'pears' to be removed")
+	}
+	if _, ok := l.Plurals["apples"]; !ok {
+		t.Fatal("expected 'apples' to be kept")
+	}
+}
+
+func TestLocaleValidateLength(t *testing.T) {
+	keys := []Key{
+		{Key: "title", MaxLength: 5},
+		{Key: "body"},
+		{Key: "apples", MaxLength: 6},
+	}
+
+	l := Locale{
+		Pairs:   map[string]string{"title": "Grüße", "body": "a long text without limits"},
+		Plurals: map[string]PluralForms{"apples": {PluralOne: "apple", PluralOther: "apples"}},
+	}
+	if err := l.ValidateLength(keys); err != nil {
+		t.Fatalf("expected values to fit but got %v", err)
+	}
+
+	l.Pairs["title"] = "Grüße!"
+	l.Plurals["apples"][PluralOther] = "apples!"
+	err := l.ValidateLength(keys)
+	if err == nil {
+		t.Fatal("expected values to be too long")
+	}
+	if errs := err.(*errors.MultiError).Errors; len(errs) != 2 {
+		t.Fatalf("expected 2 errors but got %d", len(errs))
+	}
+}
+
+func TestLocaleKeepApproved(t *testing.T) {
+	source := &Locale{
+		Ident:   "en_US",
+		Pairs:   map[string]string{"a": "A", "b": "B", "apples": ""},
+		Plurals: map[string]PluralForms{"apples": {PluralOne: "apple", PluralOther: "apples"}},
+	}
+	l := Locale{
+		Ident:    "ru_RU",
+		Pairs:    map[string]string{"a": "А", "b": "Б", "apples": ""},
+		Plurals:  map[string]PluralForms{"apples": {PluralOne: "яблоко", PluralFew: "яблока", PluralMany: "яблок", PluralOther: "яблока"}},
+		Statuses: map[string]string{"a": StatusApproved, "b": StatusNeedsReview},
+	}
+
+	if s := l.Status("apples"); s != StatusTranslated {
+		t.Fatalf("expected 'apples' to be translated but got %s", s)
+	}
+
+	l.KeepApproved(source)
+	if l.Pairs["a"] != "А" {
+		t.Fatalf("expected approved value to be kept but got %s", l.Pairs["a"])
+	}
+	if l.Pairs["b"] != "B" {
+		t.Fatalf("expected source value but got %s", l.Pairs["b"])
+	}
+	if l.Plurals["apples"][PluralOne] != "apple" || l.Plurals["apples"][PluralFew] != "apples" {
+		t.Fatalf("expected source plural forms but got %v", l.Plurals["apples"])
+	}
+}
+
+func TestLocaleSetPairsClearsStatuses(t *testing.T) {
+	l := Locale{
+		Pairs:    map[string]string{"same": "a", "changed": "b", "dropped": "c"},
+		Statuses: map[string]string{"same": StatusApproved, "changed": StatusApproved, "dropped": StatusApproved}}
+
+	l.SetPairs(map[string]string{"same": "a", "changed": "B"})
+	if l.Statuses["same"] != StatusApproved || len(l.Statuses) != 1 {
+		t.Errorf("expected only the unchanged value to keep its status, got %v", l.Statuses)
+	}
+
+	l.Statuses = map[string]string{"same": StatusApproved, "changed": StatusApproved}
+	l.PutPairs(map[string]string{"changed": "BB", "added": "d"})
+	if l.Statuses["same"] != StatusApproved || len(l.Statuses) != 1 || l.Pairs["same"] != "a" {
+		t.Errorf("expected merged pairs to keep the other values and statuses, got %v %v", l.Pairs, l.Statuses)
+	}
+}
+
+func TestLocaleSetPluralsClearsStatuses(t *testing.T) {
+	l := Locale{
+		Plurals:  map[string]PluralForms{"same": {"one": "a"}, "changed": {"one": "b"}},
+		Statuses: map[string]string{"same": StatusApproved, "changed": StatusApproved}}
+
+	l.SetPlurals(map[string]PluralForms{"same": {"one": "a"}, "changed": {"one": "b", "other": "bs"}})
+	if l.Statuses["same"] != StatusApproved || len(l.Statuses) != 1 {
+		t.Errorf("expected only the unchanged forms to keep their status, got %v", l.Statuses)
+	}
+}
+
+func TestValidLocaleIdent(t *testing.T) {
+	for _, ident := range []string{"en", "fil", "en_US", "pt-BR", "sr_Latn_RS", "es_419"} {
+		if !ValidLocaleIdent(ident) {
+			t.Errorf("expected %q to be valid", ident)
+		}
+	}
+	for _, ident := range []string{"", "e", "english", "../../x", "en/US", "en_", "en US"} {
+		if ValidLocaleIdent(ident) {
+			t.Errorf("expected %q to be invalid", ident)
+		}
+	}
+}
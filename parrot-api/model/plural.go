@@ -0,0 +1,140 @@
+package model
+
+import "github.com/iris-contrib/parrot/parrot-api/errors"
+
+// CLDR plural categories
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+var (
+	ErrInvalidPluralCategory = &errors.Error{
+		Type:    "InvalidPluralCategory",
+		Message: "plural category not used by the locale's language"}
+	ErrInvalidPluralKey = &errors.Error{
+		Type:    "InvalidPluralKey",
+		Message: "key is not a plural key of the project"}
+)
+
+// PluralRule holds the CLDR plural categories used by a language.
+// Categories are listed in the order of the gettext msgstr indexes
+// produced by GettextFormula. Categories that only apply to fractions
+// keep their index even though the formula never selects them.
+type PluralRule struct {
+	Categories     []string
+	GettextFormula string
+}
+
+// HasCategory returns true if the rule uses the provided category.
+func (r PluralRule) HasCategory(category string) bool {
+	return contains(r.Categories, category)
+}
+
+var (
+	pluralRuleNone = PluralRule{
+		Categories:     []string{PluralOther},
+		GettextFormula: "nplurals=1; plural=0;"}
+	pluralRuleOneOther = PluralRule{
+		Categories:     []string{PluralOne, PluralOther},
+		GettextFormula: "nplurals=2; plural=(n != 1);"}
+	pluralRuleOneOtherZeroIsOne = PluralRule{
+		Categories:     []string{PluralOne, PluralOther},
+		GettextFormula: "nplurals=2; plural=(n > 1);"}
+	pluralRuleSlavic = PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<12 || n%100>14) ? 1 : 2);"}
+	pluralRuleBalkan = PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralOther},
+		GettextFormula: "nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);"}
+	pluralRuleWestSlavic = PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n==1 ? 0 : n>=2 && n<=4 ? 1 : 3);"}
+)
+
+// pluralRules maps the languages of the standard locales to their plural rules.
+var pluralRules = map[string]PluralRule{
+	"Albanian": pluralRuleOneOther,
+	"Arabic": PluralRule{
+		Categories:     []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);"},
+	"Belarusian":            pluralRuleSlavic,
+	"Bulgarian":             pluralRuleOneOther,
+	"Catalan":               pluralRuleOneOther,
+	"Chinese (Simplified)":  pluralRuleNone,
+	"Chinese (Traditional)": pluralRuleNone,
+	"Croatian":              pluralRuleBalkan,
+	"Czech":                 pluralRuleWestSlavic,
+	"Danish":                pluralRuleOneOther,
+	"Dutch":                 pluralRuleOneOther,
+	"English":               pluralRuleOneOther,
+	"Estonian":              pluralRuleOneOther,
+	"Finnish":               pluralRuleOneOther,
+	"French":                pluralRuleOneOtherZeroIsOne,
+	"German":                pluralRuleOneOther,
+	"Greek":                 pluralRuleOneOther,
+	"Hebrew": PluralRule{
+		Categories:     []string{PluralOne, PluralTwo, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n==1 ? 0 : n==2 ? 1 : n>10 && n%10==0 ? 2 : 3);"},
+	"Hindi":     pluralRuleOneOtherZeroIsOne,
+	"Hungarian": pluralRuleOneOther,
+	"Icelandic": PluralRule{
+		Categories:     []string{PluralOne, PluralOther},
+		GettextFormula: "nplurals=2; plural=(n%10!=1 || n%100==11);"},
+	"Indonesian": pluralRuleNone,
+	"Irish": PluralRule{
+		Categories:     []string{PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=5; plural=(n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4);"},
+	"Italian":  pluralRuleOneOther,
+	"Japanese": pluralRuleNone,
+	"Korean":   pluralRuleNone,
+	"Latvian": PluralRule{
+		Categories:     []string{PluralZero, PluralOne, PluralOther},
+		GettextFormula: "nplurals=3; plural=(n%10==0 || (n%100>=11 && n%100<=19) ? 0 : n%10==1 && n%100!=11 ? 1 : 2);"},
+	"Lithuanian": PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n%10==1 && (n%100<11 || n%100>19) ? 0 : n%10>=2 && (n%100<11 || n%100>19) ? 1 : 3);"},
+	"Macedonian": PluralRule{
+		Categories:     []string{PluralOne, PluralOther},
+		GettextFormula: "nplurals=2; plural=(n%10==1 && n%100!=11 ? 0 : 1);"},
+	"Malay": pluralRuleNone,
+	"Maltese": PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n==1 ? 0 : n==0 || (n%100>=2 && n%100<=10) ? 1 : n%100>=11 && n%100<=19 ? 2 : 3);"},
+	"Norwegian (Bokmål)":  pluralRuleOneOther,
+	"Norwegian (Nynorsk)": pluralRuleOneOther,
+	"Polish": PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<12 || n%100>14) ? 1 : 2);"},
+	"Portuguese": pluralRuleOneOther,
+	"Romanian": PluralRule{
+		Categories:     []string{PluralOne, PluralFew, PluralOther},
+		GettextFormula: "nplurals=3; plural=(n==1 ? 0 : n==0 || (n%100>0 && n%100<20) ? 1 : 2);"},
+	"Russian":            pluralRuleSlavic,
+	"Serbian (Cyrillic)": pluralRuleBalkan,
+	"Serbian (Latin)":    pluralRuleBalkan,
+	"Slovak":             pluralRuleWestSlavic,
+	"Slovenian": PluralRule{
+		Categories:     []string{PluralOne, PluralTwo, PluralFew, PluralOther},
+		GettextFormula: "nplurals=4; plural=(n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3);"},
+	"Spanish":               pluralRuleOneOther,
+	"Swedish":               pluralRuleOneOther,
+	"Thai (Western digits)": pluralRuleNone,
+	"Thai (Thai digits)":    pluralRuleNone,
+	"Turkish":               pluralRuleOneOther,
+	"Ukrainian":             pluralRuleSlavic,
+	"Vietnamese":            pluralRuleNone,
+}
+
+// PluralRuleFor returns the plural rule of the provided language.
+// Unknown languages default to the 'one' and 'other' categories.
+func PluralRuleFor(language string) PluralRule {
+	if rule, ok := pluralRules[language]; ok {
+		return rule
+	}
+	return pluralRuleOneOther
+}
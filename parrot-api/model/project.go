@@ -1,39 +1,81 @@
 package model
 
-import "github.com/iris-contrib/parrot/parrot-api/errors"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
 
 // ProjectStorer is the interface to store projects.
+// The keys added, renamed and deleted are recorded in the journal, as are the
+// keys that become plural or stop being so. Keys are added with their metadata.
 type ProjectStorer interface {
+	GetProjects() ([]Project, error)
 	GetProject(string) (*Project, error)
 	CreateProject(Project) (*Project, error)
-	UpdateProject(Project) (*Project, error)
+	UpdateProject(Project, *Journal) (*Project, error)
 	DeleteProject(string) error
 	UpdateProjectName(projectID, name string) (*Project, error)
-	AddProjectKey(projectID, key string) (*Project, error)
-	UpdateProjectKey(projectID, oldKey, newKey string) (*Project, int, error)
-	DeleteProjectKey(projectID, key string) (*Project, error)
+	AddProjectKey(key Key, j *Journal) (*Project, error)
+	UpdateProjectKey(projectID, oldKey, newKey string, j *Journal) (*Project, int, error)
+	DeleteProjectKey(projectID, key string, j *Journal) (*Project, error)
+	SetProjectKeyPlural(projectID, key string, plural bool, j *Journal) (*Project, error)
+	UpdateProjectFallbacks(projectID string, fallbacks map[string][]string) (*Project, error)
+	UpdateProjectSourceLocale(projectID, ident string) (*Project, error)
+	UpdateProjectKeyDelimiter(projectID, delimiter string) (*Project, error)
 }
 
 // ProjectLocaleStorer is the interface to store project locales.
+// The version given to the pair updates is the one the locale is expected to be
+// at, zero skips the check. The changed values are recorded in the journal.
 type ProjectLocaleStorer interface {
-	UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string) (*Locale, error)
+	UpdateLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *Journal) (*Locale, error)
+	MergeLocalePairs(projID string, localeIdent string, pairs map[string]string, version int, j *Journal) (*Locale, error)
+	UpdateLocalePlurals(projID string, localeIdent string, plurals map[string]PluralForms, version int, j *Journal) (*Locale, error)
+	UpdateLocaleStatuses(projID string, localeIdent string, statuses map[string]string) (*Locale, error)
 	GetProjectLocaleByIdent(projID string, localeIdent string) (*Locale, error)
 	GetProjectLocales(projID string, localeIdents ...string) ([]Locale, error)
+	FindProjectLocales(projID string, q LocaleQuery) ([]Locale, *Cursor, error)
+	UpdateLocales(projID string, localeIdents []string, fn func(*Locale) error, j *Journal) ([]Locale, error)
+	// ImportLocale adds the keys missing from the project, marking the ones of pluralKeys
+	// as plural, then applies fn to the locale, in a single transaction. Keys that already
+	// exist are left as they are. fn is given the updated project, and the added keys
+	// are returned.
+	ImportLocale(projID, localeIdent string, keys, pluralKeys []string, fn func(*Project, *Locale) error, j *Journal) (*Locale, []string, error)
 }
 
 var (
 	ErrInvalidProjectName = &errors.Error{
 		Type:    "InvalidProjectName",
 		Message: "invalid field project name"}
+	ErrInvalidSourceLocale = &errors.Error{
+		Type:    "InvalidSourceLocale",
+		Message: "source locale must be a locale of the project"}
+	ErrInvalidKeyDelimiter = &errors.Error{
+		Type:    "InvalidKeyDelimiter",
+		Message: "key delimiter must be 1 to 3 characters without whitespace"}
 )
 
+// DefaultKeyDelimiter separates the levels of nested keys, e.g. 'checkout.button.submit',
+// unless the project sets another delimiter.
+const DefaultKeyDelimiter = "."
+
 type Project struct {
-	ID   string   `db:"id" json:"id"`
-	Name string   `db:"name" json:"name"`
-	Keys []string `db:"keys" json:"keys"`
+	ID         string              `db:"id" json:"id"`
+	Name       string              `db:"name" json:"name"`
+	Keys       []string            `db:"keys" json:"keys"`
+	PluralKeys []string            `db:"plural_keys" json:"plural_keys"`
+	Fallbacks  map[string][]string `db:"fallbacks" json:"fallbacks"`
+	// SourceLocale is the ident of the locale the others are translated from, if any.
+	SourceLocale string `db:"source_locale" json:"source_locale"`
+	// KeyDelimiter separates the levels of keys written as nested objects.
+	KeyDelimiter string `db:"key_delimiter" json:"key_delimiter"`
 }
 
-// SanitizeKeys removes empty and duplicate keys.
+// SanitizeKeys removes empty and duplicate keys, as well as plural keys
+// that are not project keys.
 func (p *Project) SanitizeKeys() {
 	var sk []string
 	for _, key := range p.Keys {
@@ -46,7 +88,21 @@ func (p *Project) SanitizeKeys() {
 		sk = append(sk, key)
 	}
 
+	var spk []string
+	for _, key := range p.PluralKeys {
+		if !contains(sk, key) || contains(spk, key) {
+			continue
+		}
+		spk = append(spk, key)
+	}
+
 	p.Keys = sk
+	p.PluralKeys = spk
+}
+
+// IsPluralKey returns true if the key holds plural forms.
+func (p *Project) IsPluralKey(key string) bool {
+	return contains(p.PluralKeys, key)
 }
 
 // contains returns true if parameter string 'str' is contained in parameter []string 'col'.
@@ -70,3 +126,22 @@ func (p *Project) Validate() error {
 	}
 	return nil
 }
+
+// ValidateSourceLocale returns an error if the project's source locale is set
+// but is not in string slice idents.
+func (p *Project) ValidateSourceLocale(idents []string) error {
+	if p.SourceLocale != "" && !contains(idents, p.SourceLocale) {
+		return NewValidationError([]errors.Error{*ErrInvalidSourceLocale})
+	}
+	return nil
+}
+
+// ValidateKeyDelimiter returns an error if the project's key delimiter is empty,
+// longer than 3 characters or holds whitespace.
+func (p *Project) ValidateKeyDelimiter() error {
+	if !HasMinLength(p.KeyDelimiter, 1) || utf8.RuneCountInString(p.KeyDelimiter) > 3 ||
+		strings.IndexFunc(p.KeyDelimiter, unicode.IsSpace) >= 0 {
+		return NewValidationError([]errors.Error{*ErrInvalidKeyDelimiter})
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProjectFallbackChain(t *testing.T) {
+	p := Project{}
+	idents := []string{"en_US", "fr_CA", "fr_BE", "fr_FR", "de_DE"}
+
+	chain := p.FallbackChain("fr_CA", idents)
+	if expected := []string{"fr_BE", "fr_FR"}; !reflect.DeepEqual(chain, expected) {
+		t.Fatalf("expected derived chain %v but got %v", expected, chain)
+	}
+	if chain := p.FallbackChain("de_DE", idents); len(chain) != 0 {
+		t.Fatalf("expected empty chain but got %v", chain)
+	}
+
+	p.SourceLocale = "en_US"
+	chain = p.FallbackChain("fr_CA", idents)
+	if expected := []string{"fr_BE", "fr_FR", "en_US"}; !reflect.DeepEqual(chain, expected) {
+		t.Fatalf("expected derived chain ending with the source %v but got %v", expected, chain)
+	}
+	p.SourceLocale = "fr_FR"
+	chain = p.FallbackChain("fr_CA", idents)
+	if expected := []string{"fr_FR", "fr_BE"}; !reflect.DeepEqual(chain, expected) {
+		t.Fatalf("expected derived chain starting with the source %v but got %v", expected, chain)
+	}
+	p.SourceLocale = "en_US"
+	if chain := p.FallbackChain("en_US", idents); len(chain) != 0 {
+		t.Fatalf("expected empty chain for the source but got %v", chain)
+	}
+	if err := p.ValidateSourceLocale(idents); err != nil {
+		t.Fatalf("expected source locale to be valid but got %v", err)
+	}
+	if err := p.ValidateSourceLocale(idents[1:]); err == nil {
+		t.Fatal("expected unknown source locale to be invalid")
+	}
+
+	p.Fallbacks = map[string][]string{"fr_CA": {"fr_FR", "en_US", "it_IT"}}
+	chain = p.FallbackChain("fr_CA", idents)
+	if expected := []string{"fr_FR", "en_US"}; !reflect.DeepEqual(chain, expected) {
+		t.Fatalf("expected manual chain %v but got %v", expected, chain)
+	}
+
+	if err := p.ValidateFallbacks(idents); err == nil {
+		t.Fatal("expected unknown fallback locale to be invalid")
+	}
+	p.Fallbacks["fr_CA"] = []string{"fr_FR", "en_US"}
+	if err := p.ValidateFallbacks(idents); err != nil {
+		t.Fatalf("expected fallbacks to be valid but got %v", err)
+	}
+}
+
+func TestLocaleFill(t *testing.T) {
+	l := Locale{Pairs: map[string]string{"a": "", "b": "", "c": "C"}}
+	fallbacks := []Locale{
+		{Pairs: map[string]string{"a": "", "b": "B1", "c": "C1"}},
+		{Pairs: map[string]string{"a": "A2", "b": "B2"}},
+	}
+
+	if filled := l.Fill(fallbacks); filled != 2 {
+		t.Fatalf("expected 2 filled values but got %d", filled)
+	}
+	expected := map[string]string{"a": "A2", "b": "B1", "c": "C"}
+	if !reflect.DeepEqual(l.Pairs, expected) {
+		t.Fatalf("expected %v but got %v", expected, l.Pairs)
+	}
+}
@@ -11,21 +11,25 @@ var (
 		Message: "invalid field project_id"}
 )
 
+// ProjectClient is an application accessing a project with its own credentials.
+// Clients can export locales, and add keys to the project only if CanAddKeys is set.
 type ProjectClient struct {
-	ClientID  string `db:"client_id" json:"client_id"`
-	Name      string `db:"name" json:"name"`
-	Secret    string `db:"secret" json:"secret,omitempty"`
-	ProjectID string `db:"project_id" json:"project_id"`
+	ClientID   string `db:"client_id" json:"client_id"`
+	Name       string `db:"name" json:"name"`
+	Secret     string `db:"secret" json:"secret,omitempty"`
+	ProjectID  string `db:"project_id" json:"project_id"`
+	CanAddKeys bool   `db:"can_add_keys" json:"can_add_keys"`
 }
 
 // ProjectClientStorer is the interface to store project clients.
 type ProjectClientStorer interface {
 	FindOneClient(string) (*ProjectClient, error)
-	GetProjectClients(string) ([]ProjectClient, error)
+	GetProjectClients(projectID string, opts ListOptions) ([]ProjectClient, *Cursor, error)
 	GetProjectClient(projectID, clientID string) (*ProjectClient, error)
 	CreateProjectClient(ProjectClient) (*ProjectClient, error)
 	UpdateProjectClientSecret(ProjectClient) (*ProjectClient, error)
 	UpdateProjectClientName(ProjectClient) (*ProjectClient, error)
+	UpdateProjectClientCanAddKeys(ProjectClient) (*ProjectClient, error)
 	DeleteProjectClient(projectID, clientID string) error
 }
 
@@ -2,8 +2,8 @@ package model
 
 // ProjectUserStorer is the interface to store project users.
 type ProjectUserStorer interface {
-	GetProjectUsers(projID string) ([]ProjectUser, error)
-	GetUserProjects(userID string) ([]Project, error)
+	GetProjectUsers(projID string, opts ListOptions) ([]ProjectUser, *Cursor, error)
+	GetUserProjects(userID string, opts ListOptions) ([]Project, *Cursor, error)
 	GetProjectUser(projID, userID string) (*ProjectUser, error)
 	AssignProjectUser(ProjectUser) (*ProjectUser, error)
 	RevokeProjectUser(ProjectUser) error
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrPlaceholderMismatch = &errors.Error{
+		Type:    "PlaceholderMismatch",
+		Message: "placeholders differ from the source"}
+	ErrTagMismatch = &errors.Error{
+		Type:    "TagMismatch",
+		Message: "HTML or XML tags differ from the source"}
+	ErrWhitespaceMismatch = &errors.Error{
+		Type:    "WhitespaceMismatch",
+		Message: "leading or trailing whitespace differs from the source"}
+	ErrPunctuationMismatch = &errors.Error{
+		Type:    "PunctuationMismatch",
+		Message: "terminal punctuation differs from the source"}
+)
+
+var (
+	// placeholderRegex matches printf-style verbs, e.g. '%s', '%1$d' or '%@', and named
+	// or ICU arguments, e.g. '{name}', '{{name}}' or '{count, plural, ...}'. A literal
+	// '%%' is matched so that it can be skipped.
+	placeholderRegex = regexp.MustCompile(`%%|%(\d+\$)?[-+#0']*(\d+|\*)?(\.(\d+|\*))?(hh|h|ll|l|L|q|j|z|t)?[diouxXeEfFgGaAcspn@]|\{\{\s*[\w.-]+\s*\}\}|\{\s*([A-Za-z_][\w.-]*|\d+)\s*[,}]`)
+	// tagRegex matches HTML and XML opening, closing and self-closing tags.
+	tagRegex = regexp.MustCompile(`<(/?)([A-Za-z][\w:.-]*)[^<>]*?(/?)>`)
+	// terminalPunctuation maps the punctuation ending a value to its kind, so that
+	// the full-width and script specific forms match the ASCII ones.
+	terminalPunctuation = map[rune]string{
+		'.': ".", '。': ".", '｡': ".", '।': ".",
+		'!': "!", '！': "!",
+		'?': "?", '？': "?", '؟': "?",
+		':': ":", '：': ":",
+		';': ";", '；': ";",
+		'…': "…",
+	}
+)
+
+// Issue is a problem found by comparing a value of a locale to the one of the source locale.
+type Issue struct {
+	Key string `json:"key"`
+	// Category is set when the value is a plural form.
+	Category string `json:"category,omitempty"`
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+}
+
+// Check compares the values of the locale to the ones of the source locale and returns
+// the issues, sorted by key and category. Placeholders, tags, leading and trailing
+// whitespace and terminal punctuation are checked. Empty values are skipped, and the
+// plural forms that aren't 'other' may leave out placeholders, e.g. 'one item'.
+func (loc *Locale) Check(source *Locale) []Issue {
+	issues := make([]Issue, 0)
+	for k, v := range loc.Pairs {
+		if _, ok := loc.Plurals[k]; ok {
+			continue
+		}
+		for _, issue := range CheckValue(source.Pairs[k], v, false) {
+			issue.Key = k
+			issues = append(issues, issue)
+		}
+	}
+	for k, forms := range loc.Plurals {
+		for c, v := range forms {
+			src, ok := source.Plurals[k][c]
+			if !ok {
+				src = source.Plurals[k][PluralOther]
+			}
+			for _, issue := range CheckValue(src, v, c != PluralOther) {
+				issue.Key, issue.Category = k, c
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Key != issues[j].Key {
+			return issues[i].Key < issues[j].Key
+		}
+		return issues[i].Category < issues[j].Category
+	})
+	return issues
+}
+
+// CheckValue compares a value to the source value and returns the issues, without
+// their key. Missing placeholders are allowed if partial is true. Nothing is checked
+// if either value is empty.
+func CheckValue(source, value string, partial bool) []Issue {
+	if source == "" || value == "" {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(err *errors.Error, format string, args ...interface{}) {
+		issues = append(issues, Issue{Type: err.Type, Message: fmt.Sprintf(format, args...)})
+	}
+
+	missing, unexpected := compareTokens(placeholders(source), placeholders(value))
+	for _, p := range unexpected {
+		add(ErrPlaceholderMismatch, "unexpected placeholder %s", p)
+	}
+	if !partial {
+		for _, p := range missing {
+			add(ErrPlaceholderMismatch, "missing placeholder %s", p)
+		}
+	}
+
+	missing, unexpected = compareTokens(tags(source), tags(value))
+	for _, t := range missing {
+		add(ErrTagMismatch, "missing tag %s", t)
+	}
+	for _, t := range unexpected {
+		add(ErrTagMismatch, "unexpected tag %s", t)
+	}
+
+	if leadingSpace(source) != leadingSpace(value) {
+		add(ErrWhitespaceMismatch, "leading whitespace differs from the source")
+	}
+	if trailingSpace(source) != trailingSpace(value) {
+		add(ErrWhitespaceMismatch, "trailing whitespace differs from the source")
+	}
+
+	if p, sp := punctuation(value), punctuation(source); p != sp {
+		switch {
+		case sp == "":
+			add(ErrPunctuationMismatch, "ends with '%s' unlike the source", p)
+		case p == "":
+			add(ErrPunctuationMismatch, "missing terminal '%s'", sp)
+		default:
+			add(ErrPunctuationMismatch, "ends with '%s' instead of '%s'", p, sp)
+		}
+	}
+
+	return issues
+}
+
+// NewIssuesError returns a validation error listing the issues, or nil if there are none.
+func NewIssuesError(issues []Issue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	errs := make([]errors.Error, 0, len(issues))
+	for _, issue := range issues {
+		key := issue.Key
+		if issue.Category != "" {
+			key += "." + issue.Category
+		}
+		errs = append(errs, errors.Error{
+			Type:    issue.Type,
+			Message: fmt.Sprintf("value of key '%s': %s", key, issue.Message)})
+	}
+	return NewValidationError(errs)
+}
+
+// PlaceholderIndexes returns the start and end offsets of the placeholders of s, as the
+// checks find them. ICU arguments with a format, e.g. '{count, plural, ...}', only span
+// their opening brace, name and comma.
+func PlaceholderIndexes(s string) [][]int {
+	return placeholderRegex.FindAllStringIndex(s, -1)
+}
+
+// placeholders returns the placeholders of s, with the ICU arguments reduced to their name.
+func placeholders(s string) []string {
+	var result []string
+	for _, m := range placeholderRegex.FindAllString(s, -1) {
+		switch {
+		case m == "%%":
+			continue
+		case strings.HasPrefix(m, "{{"):
+			m = "{{" + strings.TrimSpace(strings.Trim(m, "{}")) + "}}"
+		case strings.HasPrefix(m, "{"):
+			m = "{" + strings.TrimSpace(strings.TrimRight(m[1:], ",}")) + "}"
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// tags returns the tags of s, without their attributes.
+func tags(s string) []string {
+	var result []string
+	for _, m := range tagRegex.FindAllStringSubmatch(s, -1) {
+		result = append(result, "<"+m[1]+m[2]+m[3]+">")
+	}
+	return result
+}
+
+// compareTokens returns the tokens of the source missing from the value and the
+// tokens of the value that the source doesn't have, counting duplicates.
+func compareTokens(source, value []string) (missing, unexpected []string) {
+	counts := make(map[string]int)
+	for _, t := range source {
+		counts[t]++
+	}
+	for _, t := range value {
+		if counts[t] > 0 {
+			counts[t]--
+			continue
+		}
+		unexpected = append(unexpected, t)
+	}
+	for _, t := range source {
+		if counts[t] > 0 {
+			counts[t]--
+			missing = append(missing, t)
+		}
+	}
+	return missing, unexpected
+}
+
+// leadingSpace returns the whitespace s starts with.
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+}
+
+// trailingSpace returns the whitespace s ends with.
+func trailingSpace(s string) string {
+	return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+}
+
+// punctuation returns the kind of punctuation ending s, if any.
+func punctuation(s string) string {
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if strings.HasSuffix(s, "...") {
+		return "…"
+	}
+	for r, kind := range terminalPunctuation {
+		if strings.HasSuffix(s, string(r)) {
+			return kind
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		source   string
+		value    string
+		expected []string
+	}{
+		{"Hello %@!", "Hallo %@!", nil},
+		{"Hello %@!", "Hallo!", []string{"missing placeholder %@"}},
+		{"%1$s sent %2$d files.", "%2$d Dateien von %1$s.", nil},
+		{"You have {count} items.", "Tienes {cuenta} artículos.", []string{"unexpected placeholder {cuenta}", "missing placeholder {count}"}},
+		{"{count, plural, one {# item} other {# items}}", "{count, plural, one {# Artikel} other {# Artikel}}", nil},
+		{"Hi {{name}}", "Hallo {name}", []string{"unexpected placeholder {name}", "missing placeholder {{name}}"}},
+		{"100% sure", "100% sicher", nil},
+		{"Click <a href=\"/x\">here</a>.", "Klicke <a href=\"/y\">hier<a>.", []string{"missing tag </a>", "unexpected tag <a>"}},
+		{" Name: ", "Name:", []string{"leading whitespace differs from the source", "trailing whitespace differs from the source"}},
+		{"Saved.", "Gespeichert", []string{"missing terminal '.'"}},
+		{"Saved.", "保存しました。", nil},
+		{"Are you sure?", "Bist du sicher.", []string{"ends with '.' instead of '?'"}},
+		{"Loading...", "Laden…", nil},
+		{"Saved.", "", nil},
+	}
+
+	for _, tc := range tests {
+		var messages []string
+		for _, issue := range CheckValue(tc.source, tc.value, false) {
+			messages = append(messages, issue.Message)
+		}
+		if !reflect.DeepEqual(messages, tc.expected) {
+			t.Errorf("%q -> %q: expected issues %v, got %v", tc.source, tc.value, tc.expected, messages)
+		}
+	}
+}
+
+func TestLocaleCheck(t *testing.T) {
+	source := &Locale{
+		Pairs:   map[string]string{"greeting": "Hello {name}!", "items": ""},
+		Plurals: map[string]PluralForms{"items": {"one": "One item", "other": "%d items"}},
+	}
+	loc := &Locale{
+		Pairs:   map[string]string{"greeting": "Hallo!", "items": ""},
+		Plurals: map[string]PluralForms{"items": {"one": "Ein Artikel", "few": "%d Artikel", "other": "Artikel"}},
+	}
+
+	issues := loc.Check(source)
+	expected := []Issue{
+		{Key: "greeting", Type: ErrPlaceholderMismatch.Type, Message: "missing placeholder {name}"},
+		{Key: "items", Category: "other", Type: ErrPlaceholderMismatch.Type, Message: "missing placeholder %d"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected issues %v, got %v", expected, issues)
+	}
+
+	err := NewIssuesError(issues)
+	multi, ok := err.(*errors.MultiError)
+	if !ok || len(multi.Errors) != 2 || multi.Errors[1].Message != "value of key 'items.other': missing placeholder %d" {
+		t.Errorf("unexpected issues error %v", err)
+	}
+	if NewIssuesError(nil) != nil {
+		t.Error("expected no error without issues")
+	}
+}
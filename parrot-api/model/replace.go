@@ -0,0 +1,107 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrInvalidReplacePattern = &errors.Error{
+		Type:    "InvalidReplacePattern",
+		Message: "invalid field pattern"}
+	ErrInvalidReplaceLocales = &errors.Error{
+		Type:    "InvalidReplaceLocales",
+		Message: "invalid field locales"}
+)
+
+// Replace describes a find-and-replace across the values of some locales.
+// A regex replacement can refer to submatches, e.g. '$1'.
+type Replace struct {
+	Pattern     string   `json:"pattern"`
+	Replacement string   `json:"replacement"`
+	Regex       bool     `json:"regex"`
+	IgnoreCase  bool     `json:"ignore_case"`
+	Locales     []string `json:"locales"`
+}
+
+// ValueChange is a value changed by a replace. Category is set for plural forms.
+type ValueChange struct {
+	Locale   string `json:"locale"`
+	Key      string `json:"key"`
+	Category string `json:"category,omitempty"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// Validate returns an error if the replace's data is invalid.
+func (r *Replace) Validate() error {
+	var errs []errors.Error
+	if _, err := r.compile(); err != nil {
+		errs = append(errs, *ErrInvalidReplacePattern)
+	}
+	if len(r.Locales) == 0 {
+		errs = append(errs, *ErrInvalidReplaceLocales)
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// compile returns the expression matching the pattern.
+func (r *Replace) compile() (*regexp.Regexp, error) {
+	if r.Pattern == "" {
+		return nil, ErrInvalidReplacePattern
+	}
+	expr := r.Pattern
+	if !r.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if r.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// Apply replaces the matches of the pattern in the pairs and plural forms of the
+// locale, sorted by key and category. Changed values lose their review status.
+func (r *Replace) Apply(loc *Locale) ([]ValueChange, error) {
+	re, err := r.compile()
+	if err != nil {
+		return nil, err
+	}
+	replace := func(s string) string {
+		if r.Regex {
+			return re.ReplaceAllString(s, r.Replacement)
+		}
+		return re.ReplaceAllLiteralString(s, r.Replacement)
+	}
+
+	changes := make([]ValueChange, 0)
+	for k, v := range loc.Pairs {
+		if nv := replace(v); nv != v {
+			loc.Pairs[k] = nv
+			delete(loc.Statuses, k)
+			changes = append(changes, ValueChange{Locale: loc.Ident, Key: k, OldValue: v, NewValue: nv})
+		}
+	}
+	for k, forms := range loc.Plurals {
+		for c, v := range forms {
+			if nv := replace(v); nv != v {
+				forms[c] = nv
+				delete(loc.Statuses, k)
+				changes = append(changes, ValueChange{Locale: loc.Ident, Key: k, Category: c, OldValue: v, NewValue: nv})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Key != changes[j].Key {
+			return changes[i].Key < changes[j].Key
+		}
+		return changes[i].Category < changes[j].Category
+	})
+	return changes, nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplaceApply(t *testing.T) {
+	tests := []struct {
+		replace  Replace
+		expected []ValueChange
+	}{
+		{
+			Replace{Pattern: "Acme", Replacement: "Globex"},
+			[]ValueChange{
+				{Locale: "en", Key: "items", Category: "other", OldValue: "%d Acme items", NewValue: "%d Globex items"},
+				{Locale: "en", Key: "title", OldValue: "Acme (beta)", NewValue: "Globex (beta)"},
+			},
+		},
+		{
+			Replace{Pattern: "acme (", Replacement: "$1", IgnoreCase: true},
+			[]ValueChange{{Locale: "en", Key: "title", OldValue: "Acme (beta)", NewValue: "$1beta)"}},
+		},
+		{
+			Replace{Pattern: `Acme \((\w+)\)`, Replacement: "Globex $1", Regex: true},
+			[]ValueChange{{Locale: "en", Key: "title", OldValue: "Acme (beta)", NewValue: "Globex beta"}},
+		},
+	}
+
+	for _, tt := range tests {
+		loc := Locale{
+			Ident:    "en",
+			Pairs:    map[string]string{"title": "Acme (beta)", "other": "Unchanged"},
+			Plurals:  map[string]PluralForms{"items": {"one": "1 item", "other": "%d Acme items"}},
+			Statuses: map[string]string{"title": StatusApproved, "other": StatusApproved},
+		}
+		changes, err := tt.replace.Apply(&loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(changes, tt.expected) {
+			t.Errorf("%+v: expected changes %+v, got %+v", tt.replace, tt.expected, changes)
+		}
+		if _, ok := loc.Statuses["title"]; ok {
+			t.Errorf("%+v: expected the changed value to lose its status", tt.replace)
+		}
+		if loc.Statuses["other"] != StatusApproved {
+			t.Errorf("%+v: expected the unchanged value to keep its status", tt.replace)
+		}
+	}
+}
+
+func TestReplaceValidate(t *testing.T) {
+	if err := (&Replace{Pattern: "(", Regex: true, Locales: []string{"en"}}).Validate(); err == nil {
+		t.Error("expected an invalid regex to fail validation")
+	}
+	if err := (&Replace{Pattern: "(", Locales: []string{"en"}}).Validate(); err != nil {
+		t.Errorf("expected a literal pattern to be valid, got %v", err)
+	}
+	if err := (&Replace{Pattern: "a"}).Validate(); err == nil {
+		t.Error("expected a replace without locales to fail validation")
+	}
+}
@@ -0,0 +1,105 @@
+package model
+
+import "github.com/iris-contrib/parrot/parrot-api/errors"
+
+// review statuses of locale pairs
+const (
+	StatusUntranslated = "untranslated"
+	StatusTranslated   = "translated"
+	StatusNeedsReview  = "needs-review"
+	StatusApproved     = "approved"
+	// StatusMachineTranslated marks the values filled by a machine translation
+	// provider, until they are reviewed or changed.
+	StatusMachineTranslated = "machine-translated"
+)
+
+var (
+	ErrInvalidStatus = &errors.Error{
+		Type:    "InvalidStatus",
+		Message: "invalid pair status"}
+	ErrUntranslatedStatus = &errors.Error{
+		Type:    "UntranslatedStatus",
+		Message: "pairs without a value can't be reviewed"}
+)
+
+// Status returns the review status of a key. Keys without a value are untranslated.
+// Values are translated unless they have been machine translated, marked for review
+// or approved.
+// Changing a value drops the review status it had.
+func (loc *Locale) Status(key string) string {
+	if !loc.IsTranslated(key) {
+		return StatusUntranslated
+	}
+	switch s := loc.Statuses[key]; s {
+	case StatusNeedsReview, StatusApproved, StatusMachineTranslated:
+		return s
+	}
+	return StatusTranslated
+}
+
+// SyncStatuses sets the review status of every key in the document pairs,
+// so that untranslated and translated keys are listed too.
+func (loc *Locale) SyncStatuses() {
+	temp := make(map[string]string, len(loc.Pairs))
+	for k := range loc.Pairs {
+		temp[k] = loc.Status(k)
+	}
+	loc.Statuses = temp
+}
+
+// ValidateStatuses returns an error if any of the statuses is unknown or
+// belongs to a key that is not in the document pairs or has no value.
+func (loc *Locale) ValidateStatuses(statuses map[string]string) error {
+	var errs []errors.Error
+	for k, s := range statuses {
+		switch s {
+		case StatusTranslated, StatusNeedsReview, StatusApproved:
+		default:
+			errs = append(errs, *ErrInvalidStatus)
+			continue
+		}
+		if _, ok := loc.Pairs[k]; !ok {
+			errs = append(errs, *ErrInvalidStatus)
+			continue
+		}
+		if !loc.IsTranslated(k) {
+			errs = append(errs, *ErrUntranslatedStatus)
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// KeepApproved replaces every value that has not been approved with the value of the
+// source locale, or with an empty string if there is no source locale. Plural categories
+// missing from the source fall back to its 'other' form.
+func (loc *Locale) KeepApproved(source *Locale) {
+	for k := range loc.Pairs {
+		if loc.Status(k) == StatusApproved {
+			continue
+		}
+
+		value := ""
+		if source != nil {
+			value = source.Pairs[k]
+		}
+		loc.Pairs[k] = value
+
+		forms, ok := loc.Plurals[k]
+		if !ok {
+			continue
+		}
+		for c := range forms {
+			value := ""
+			if source != nil {
+				value, ok = source.Plurals[k][c]
+				if !ok {
+					value = source.Plurals[k][PluralOther]
+				}
+			}
+			forms[c] = value
+		}
+	}
+}
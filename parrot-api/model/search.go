@@ -0,0 +1,196 @@
+package model
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+var (
+	ErrInvalidSearchQuery = errors.New(
+		http.StatusBadRequest,
+		"InvalidSearchQuery",
+		"a search text is required unless searching for untranslated entries")
+	ErrInvalidSearchPattern = errors.New(
+		http.StatusBadRequest,
+		"InvalidSearchPattern",
+		"invalid regular expression")
+)
+
+// SearchStorer is the interface to search the keys and values of a project.
+type SearchStorer interface {
+	SearchProject(projectID string, q SearchQuery) ([]SearchResult, error)
+}
+
+// SearchQuery describes what to search for in a project.
+type SearchQuery struct {
+	// Text is matched against the key names and the values, as a substring
+	// or as a regular expression.
+	Text       string
+	Regex      bool
+	IgnoreCase bool
+	// Locales restricts the search to the locales with these idents, if any.
+	Locales []string
+	// Untranslated only matches the entries without a value, Text is then
+	// optional and only matched against the key names.
+	Untranslated bool
+}
+
+// SearchMatch is an entry of a locale that matched the search.
+type SearchMatch struct {
+	Locale string `json:"locale"`
+	// Category is set when the value is a plural form.
+	Category string `json:"category,omitempty"`
+	Value    string `json:"value"`
+}
+
+// SearchResult groups the matches of a key.
+type SearchResult struct {
+	Key string `json:"key"`
+	// KeyMatched is true if the key name itself matched the search text.
+	KeyMatched bool          `json:"key_matched"`
+	Matches    []SearchMatch `json:"matches"`
+}
+
+// Validate returns an error if the query can't be run.
+func (q *SearchQuery) Validate() error {
+	_, err := q.Matcher()
+	return err
+}
+
+// Matcher returns a function reporting whether a string matches the search text.
+func (q *SearchQuery) Matcher() (func(string) bool, error) {
+	if q.Text == "" {
+		if !q.Untranslated {
+			return nil, ErrInvalidSearchQuery
+		}
+		return func(string) bool { return true }, nil
+	}
+
+	if q.Regex {
+		expr := q.Text
+		if q.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, ErrInvalidSearchPattern
+		}
+		return re.MatchString, nil
+	}
+
+	if q.IgnoreCase {
+		text := strings.ToLower(q.Text)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), text) }, nil
+	}
+	return func(s string) bool { return strings.Contains(s, q.Text) }, nil
+}
+
+// Literal returns a text that every string matched by a regular expression holds, the
+// literal prefix of the expression, which may be empty. Stores whose own regular
+// expressions differ from Go's can prefilter on it, then match with Matcher.
+func (q *SearchQuery) Literal() string {
+	re, err := regexp.Compile(q.Text)
+	if err != nil {
+		return ""
+	}
+	prefix, _ := re.LiteralPrefix()
+	return prefix
+}
+
+// Search runs the query against the locales of the project, which should be
+// limited to q.Locales already. The locales are synced with the project keys.
+func Search(project *Project, locales []Locale, q SearchQuery) ([]SearchResult, error) {
+	match, err := q.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	for i := range locales {
+		locales[i].SyncKeys(project.Keys)
+		locales[i].SyncPluralKeys(project.PluralKeys)
+	}
+
+	var keys []string
+	for _, k := range project.Keys {
+		if match(k) {
+			keys = append(keys, k)
+		}
+	}
+
+	matches := make(map[string][]SearchMatch)
+	for i := range locales {
+		loc := &locales[i]
+		if q.Untranslated {
+			for _, k := range keys {
+				if !loc.IsTranslated(k) {
+					matches[k] = append(matches[k], SearchMatch{Locale: loc.Ident})
+				}
+			}
+			continue
+		}
+
+		for k, v := range loc.Pairs {
+			if _, ok := loc.Plurals[k]; !ok && match(v) {
+				matches[k] = append(matches[k], SearchMatch{Locale: loc.Ident, Value: v})
+			}
+		}
+		for k, forms := range loc.Plurals {
+			for c, v := range forms {
+				if match(v) {
+					matches[k] = append(matches[k], SearchMatch{Locale: loc.Ident, Category: c, Value: v})
+				}
+			}
+		}
+	}
+
+	if q.Untranslated {
+		// Only the keys with untranslated entries are results
+		keys = nil
+		if q.Text != "" {
+			for k := range matches {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return GroupSearchResults(keys, matches), nil
+}
+
+// GroupSearchResults returns a result for each of the matched keys and of the keys
+// of the matched entries, sorted by key. The matches are sorted by locale and category.
+func GroupSearchResults(keys []string, matches map[string][]SearchMatch) []SearchResult {
+	byKey := make(map[string]*SearchResult)
+	result := func(k string) *SearchResult {
+		r, ok := byKey[k]
+		if !ok {
+			r = &SearchResult{Key: k, Matches: []SearchMatch{}}
+			byKey[k] = r
+		}
+		return r
+	}
+
+	for _, k := range keys {
+		result(k).KeyMatched = true
+	}
+	for k, m := range matches {
+		r := result(k)
+		r.Matches = append(r.Matches, m...)
+		sort.Slice(r.Matches, func(i, j int) bool {
+			if r.Matches[i].Locale != r.Matches[j].Locale {
+				return r.Matches[i].Locale < r.Matches[j].Locale
+			}
+			return r.Matches[i].Category < r.Matches[j].Category
+		})
+	}
+
+	results := make([]SearchResult, 0, len(byKey))
+	for _, r := range byKey {
+		results = append(results, *r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Key < results[j].Key })
+
+	return results
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchPlurals(t *testing.T) {
+	p := &Project{Keys: []string{"items", "title"}, PluralKeys: []string{"items"}}
+	locales := []Locale{
+		{Ident: "en", Language: "en", Pairs: map[string]string{"title": "Items"},
+			Plurals: map[string]PluralForms{"items": {"one": "1 item", "other": "%d items"}}},
+		{Ident: "fr", Language: "fr", Pairs: map[string]string{"title": "Articles"},
+			Plurals: map[string]PluralForms{"items": {"one": "1 article"}}},
+	}
+
+	results, err := Search(p, locales, SearchQuery{Text: "items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SearchResult{{Key: "items", KeyMatched: true, Matches: []SearchMatch{{Locale: "en", Category: "other", Value: "%d items"}}}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %+v, got %+v", expected, results)
+	}
+
+	// A plural key is untranslated until every category has a value
+	results, err = Search(p, locales, SearchQuery{Untranslated: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []SearchResult{{Key: "items", Matches: []SearchMatch{{Locale: "fr"}}}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %+v, got %+v", expected, results)
+	}
+}
+
+func TestSearchQueryValidate(t *testing.T) {
+	if err := (&SearchQuery{}).Validate(); err != ErrInvalidSearchQuery {
+		t.Errorf("expected %v, got %v", ErrInvalidSearchQuery, err)
+	}
+	if err := (&SearchQuery{Text: "(", Regex: true}).Validate(); err != ErrInvalidSearchPattern {
+		t.Errorf("expected %v, got %v", ErrInvalidSearchPattern, err)
+	}
+	if err := (&SearchQuery{Untranslated: true}).Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestSearchQueryLiteral(t *testing.T) {
+	tests := map[string]string{
+		`Hello\b`: "Hello",
+		`^Hello`:  "Hello",
+		`a|b`:     "",
+		`(`:       "",
+	}
+	for text, expected := range tests {
+		if got := (&SearchQuery{Text: text, Regex: true}).Literal(); got != expected {
+			t.Errorf("%s: expected %q, got %q", text, expected, got)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+// LocaleStats holds the translation progress of a locale.
+type LocaleStats struct {
+	Ident      string  `json:"ident"`
+	Language   string  `json:"language"`
+	Country    string  `json:"country"`
+	Total      int     `json:"total"`
+	Translated int     `json:"translated"`
+	Empty      int     `json:"empty"`
+	Progress   float64 `json:"progress"`
+}
+
+// ProjectStats holds the translation progress of every project locale.
+type ProjectStats struct {
+	ProjectID string        `json:"project_id"`
+	Keys      int           `json:"keys"`
+	Locales   []LocaleStats `json:"locales"`
+}
+
+// IsTranslated returns true if the key has a value. Plural keys
+// are only translated once every plural form has a value.
+func (loc *Locale) IsTranslated(key string) bool {
+	if forms, ok := loc.Plurals[key]; ok {
+		if len(forms) == 0 {
+			return false
+		}
+		for _, v := range forms {
+			if v == "" {
+				return false
+			}
+		}
+		return true
+	}
+	return loc.Pairs[key] != ""
+}
+
+// Stats returns the translation progress of the locale. Keys should be
+// synced with the project first, so that missing keys are counted as empty.
+func (loc *Locale) Stats() LocaleStats {
+	stats := LocaleStats{
+		Ident:    loc.Ident,
+		Language: loc.Language,
+		Country:  loc.Country,
+		Total:    len(loc.Pairs),
+		Progress: 100}
+
+	for k := range loc.Pairs {
+		if loc.IsTranslated(k) {
+			stats.Translated++
+		}
+	}
+	stats.Empty = stats.Total - stats.Translated
+
+	if stats.Total > 0 {
+		stats.Progress = float64(stats.Translated) * 100 / float64(stats.Total)
+	}
+
+	return stats
+}
+
+// RemoveUntranslated removes the keys that don't have a value from the locale.
+func (loc *Locale) RemoveUntranslated() {
+	for k := range loc.Pairs {
+		if !loc.IsTranslated(k) {
+			delete(loc.Pairs, k)
+			delete(loc.Plurals, k)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// TokenStorer is the interface to store refresh tokens and revoked access tokens.
+// Access tokens are revoked one by one, or for a subject as a whole: every token
+// of the subject issued before the revocation is then refused until expiresAt,
+// when they would all have expired anyway.
+type TokenStorer interface {
+	CreateRefreshToken(token RefreshToken) (*RefreshToken, error)
+	GetRefreshToken(tokenHash string) (*RefreshToken, error)
+	RotateRefreshToken(oldTokenHash string, token RefreshToken) (*RefreshToken, error)
+	RevokeRefreshTokenFamily(familyID string) error
+	RevokeSubjectRefreshTokens(subjectID string) error
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	RevokeSubjectAccessTokens(subjectID string, issuedBefore, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID, subjectID string, issuedAt time.Time) (bool, error)
+}
+
+// RefreshToken is an opaque token used to obtain new access tokens. Only a hash of
+// the token is stored. Each use replaces it with a new token of the same family,
+// so that reusing a replaced token can be detected and the whole family revoked.
+type RefreshToken struct {
+	ID          string     `db:"id" json:"id"`
+	TokenHash   string     `db:"token_hash" json:"-"`
+	FamilyID    string     `db:"family_id" json:"family_id"`
+	SubjectID   string     `db:"subject_id" json:"subject_id"`
+	SubjectType string     `db:"subject_type" json:"subject_type"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt   *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
+
+// IsActive returns true if the token has neither expired nor been revoked.
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
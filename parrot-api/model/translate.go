@@ -0,0 +1,116 @@
+package model
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+// Machine translation providers
+const (
+	TranslationPseudo = "pseudo"
+	TranslationHTTP   = "http"
+)
+
+// TranslationProviders lists the machine translation providers projects can use.
+var TranslationProviders = []string{
+	TranslationPseudo,
+	TranslationHTTP,
+}
+
+var (
+	ErrInvalidTranslationProvider = &errors.Error{
+		Type:    "InvalidTranslationProvider",
+		Message: "invalid field provider, unknown provider"}
+	ErrInvalidTranslationURL = &errors.Error{
+		Type:    "InvalidTranslationURL",
+		Message: "invalid field url, must be an http or https url"}
+	ErrInvalidPrefillSource = &errors.Error{
+		Type:    "InvalidPrefillSource",
+		Message: "invalid field source"}
+	ErrInvalidPrefillLocales = &errors.Error{
+		Type:    "InvalidPrefillLocales",
+		Message: "invalid field locales, must not be empty nor hold the source"}
+	ErrTranslationNotConfigured = errors.New(
+		http.StatusUnprocessableEntity,
+		"TranslationNotConfigured",
+		"no machine translation provider is configured for the project")
+)
+
+// TranslationConfigStorer is the interface to store the translation provider of projects.
+type TranslationConfigStorer interface {
+	GetTranslationConfig(projectID string) (*TranslationConfig, error)
+	// SetTranslationConfig creates or replaces the translation config of the project.
+	SetTranslationConfig(TranslationConfig) (*TranslationConfig, error)
+	DeleteTranslationConfig(projectID string) error
+}
+
+// TranslationConfig selects the machine translation provider of a project.
+type TranslationConfig struct {
+	ProjectID string `db:"project_id" json:"project_id"`
+	Provider  string `db:"provider" json:"provider"`
+	// URL is the endpoint of the HTTP provider.
+	URL string `db:"url" json:"url,omitempty"`
+	// APIKey is the credential sent to the provider, it is never returned by the API.
+	APIKey    string    `db:"api_key" json:"-"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Prefill describes the machine translation of the empty values of some locales
+// from the values of a source locale.
+type Prefill struct {
+	Source  string   `json:"source"`
+	Locales []string `json:"locales"`
+	// Keys restricts the prefill to these keys, if any.
+	Keys []string `json:"keys"`
+}
+
+// Validate returns an error if the config's data is invalid.
+func (c *TranslationConfig) Validate() error {
+	var errs []errors.Error
+	if !contains(TranslationProviders, c.Provider) {
+		errs = append(errs, *ErrInvalidTranslationProvider)
+	}
+	if c.Provider == TranslationHTTP {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, *ErrInvalidTranslationURL)
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// Validate returns an error if the prefill's data is invalid.
+func (p *Prefill) Validate() error {
+	var errs []errors.Error
+	if p.Source == "" {
+		errs = append(errs, *ErrInvalidPrefillSource)
+	}
+	if len(p.Locales) == 0 || contains(p.Locales, p.Source) {
+		errs = append(errs, *ErrInvalidPrefillLocales)
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// Missing returns the values of the source locale for the keys of the project that
+// have no value in the target locale. Plural keys are left out.
+func (p *Prefill) Missing(project *Project, source, target *Locale) map[string]string {
+	values := make(map[string]string)
+	for _, k := range project.Keys {
+		if contains(project.PluralKeys, k) || (len(p.Keys) > 0 && !contains(p.Keys, k)) {
+			continue
+		}
+		if v := source.Pairs[k]; v != "" && target.Pairs[k] == "" {
+			values[k] = v
+		}
+	}
+	return values
+}
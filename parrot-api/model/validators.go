@@ -12,7 +12,8 @@ type Validatable interface {
 }
 
 var (
-	emailRegex *regexp.Regexp
+	emailRegex       *regexp.Regexp
+	localeIdentRegex *regexp.Regexp
 )
 var (
 	ErrValidationFailure = &errors.Error{
@@ -22,6 +23,7 @@ var (
 
 func init() {
 	emailRegex = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
+	localeIdentRegex = regexp.MustCompile(`^[A-Za-z]{2,3}([_-][A-Za-z0-9]+)*$`)
 }
 
 // ValidEmail returns true if the string is of the valid email format.
@@ -29,6 +31,12 @@ func ValidEmail(str string) bool {
 	return emailRegex.MatchString(str)
 }
 
+// ValidLocaleIdent returns true if the string is a language code, optionally
+// followed by region or variant subtags separated by '_' or '-'.
+func ValidLocaleIdent(str string) bool {
+	return localeIdentRegex.MatchString(str)
+}
+
 // HasMinLength returns true if the string's length is greater than or equal
 // to the min parameter.
 func HasMinLength(str string, min int) bool {
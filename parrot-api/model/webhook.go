@@ -0,0 +1,198 @@
+package model
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/errors"
+)
+
+// Webhook events
+const (
+	EventLocaleCreated = "locale.created"
+	EventLocaleUpdated = "locale.updated"
+	EventLocaleDeleted = "locale.deleted"
+	EventKeyAdded      = "key.added"
+	EventKeyRenamed    = "key.renamed"
+	EventKeyDeleted    = "key.deleted"
+	EventKeyUpdated    = "key.updated"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []string{
+	EventLocaleCreated,
+	EventLocaleUpdated,
+	EventLocaleDeleted,
+	EventKeyAdded,
+	EventKeyRenamed,
+	EventKeyDeleted,
+	EventKeyUpdated,
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+var (
+	ErrInvalidWebhookURL = &errors.Error{
+		Type:    "InvalidWebhookURL",
+		Message: "invalid field url, must be an http or https url"}
+	ErrPrivateWebhookURL = &errors.Error{
+		Type:    "PrivateWebhookURL",
+		Message: "invalid field url, must not be a local or private address"}
+	ErrInvalidWebhookEvent = &errors.Error{
+		Type:    "InvalidWebhookEvent",
+		Message: "invalid field events, unknown event"}
+)
+
+// WebhookStorer is the interface to store webhooks and their deliveries.
+type WebhookStorer interface {
+	GetProjectWebhooks(projectID string) ([]Webhook, error)
+	GetProjectWebhook(projectID, webhookID string) (*Webhook, error)
+	CreateWebhook(Webhook) (*Webhook, error)
+	UpdateWebhook(Webhook) (*Webhook, error)
+	DeleteWebhook(projectID, webhookID string) error
+	CreateWebhookDelivery(WebhookDelivery) (*WebhookDelivery, error)
+	// ClaimWebhookDeliveries returns up to limit pending deliveries that are due and
+	// postpones their next attempt by lease, so that concurrent workers skip them.
+	// A delivery whose worker stops before updating it is retried after the lease.
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]WebhookDelivery, error)
+	UpdateWebhookDelivery(WebhookDelivery) (*WebhookDelivery, error)
+	// GetWebhookDeliveries returns the latest deliveries of the webhook, newest first.
+	GetWebhookDeliveries(webhookID string, limit int) ([]WebhookDelivery, error)
+}
+
+// Webhook is a subscription of a URL to the events of a project.
+// Deliveries are signed with the secret. No events means every event.
+type Webhook struct {
+	ID        string    `db:"id" json:"id"`
+	ProjectID string    `db:"project_id" json:"project_id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret,omitempty"`
+	Events    []string  `db:"events" json:"events"`
+	Active    bool      `db:"active" json:"active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook.
+type WebhookDelivery struct {
+	ID             string     `db:"id" json:"id"`
+	WebhookID      string     `db:"webhook_id" json:"webhook_id"`
+	ProjectID      string     `db:"project_id" json:"project_id"`
+	Event          string     `db:"event" json:"event"`
+	Payload        string     `db:"payload" json:"payload"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode int        `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string     `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+}
+
+// Event is a change to a project, sent as the JSON body of a delivery.
+type Event struct {
+	Event     string      `json:"event"`
+	ProjectID string      `json:"project_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// NewEvent returns the event of the project, created now.
+func NewEvent(projectID, event string, data interface{}) Event {
+	return Event{Event: event, ProjectID: projectID, CreatedAt: time.Now().UTC(), Data: data}
+}
+
+// Deliveries returns a pending delivery of the event for each of the webhooks subscribed to it.
+func (e Event) Deliveries(webhooks []Webhook) ([]WebhookDelivery, error) {
+	var payload []byte
+	var result []WebhookDelivery
+	for _, w := range webhooks {
+		if w.ProjectID != e.ProjectID || !w.Subscribes(e.Event) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(e); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, WebhookDelivery{
+			WebhookID:     w.ID,
+			ProjectID:     e.ProjectID,
+			Event:         e.Event,
+			Payload:       string(payload),
+			NextAttemptAt: time.Now()})
+	}
+	return result, nil
+}
+
+// Validate returns an error if the webhook's data is invalid.
+func (w *Webhook) Validate() error {
+	var errs []errors.Error
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, *ErrInvalidWebhookURL)
+	} else if isPrivateHost(u.Hostname()) {
+		errs = append(errs, *ErrPrivateWebhookURL)
+	}
+	for _, e := range w.Events {
+		if !contains(WebhookEvents, e) {
+			errs = append(errs, *ErrInvalidWebhookEvent)
+			break
+		}
+	}
+	if errs != nil {
+		return NewValidationError(errs)
+	}
+	return nil
+}
+
+// nonPublicNetworks lists the loopback, link-local, private, shared and reserved
+// address ranges, which webhooks are never delivered to.
+var nonPublicNetworks []*net.IPNet
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+		"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nonPublicNetworks = append(nonPublicNetworks, n)
+	}
+}
+
+// IsPublicIP returns true if webhooks may be delivered to the address.
+func IsPublicIP(ip net.IP) bool {
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrivateHost returns true if the host is a local name or a non-public address.
+// Other names are checked again once resolved, when the webhook is delivered.
+func isPrivateHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && !IsPublicIP(ip)
+}
+
+// Subscribes returns true if the webhook is active and subscribed to the event.
+func (w *Webhook) Subscribes(event string) bool {
+	return w.Active && (len(w.Events) == 0 || contains(w.Events, event))
+}
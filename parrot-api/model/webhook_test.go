@@ -0,0 +1,42 @@
+package model
+
+import (
+	"net"
+	"testing"
+)
+
+func TestWebhookValidateURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/hook":         true,
+		"http://93.184.216.34:8080/hook":   true,
+		"ftp://example.com":                false,
+		"http://localhost:8080":            false,
+		"http://api.localhost.":            false,
+		"http://127.0.0.1":                 false,
+		"http://169.254.169.254/latest":    false,
+		"http://10.0.0.1":                  false,
+		"http://192.168.1.1":               false,
+		"http://[::1]:8080":                false,
+		"http://[fd00::1]":                 false,
+		"http://[::ffff:169.254.169.254]/": false,
+	}
+	for url, valid := range tests {
+		w := Webhook{URL: url}
+		if err := w.Validate(); (err == nil) != valid {
+			t.Errorf("%s: expected valid to be %v, got %v", url, valid, err)
+		}
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"8.8.8.8", "2606:4700::1111"} {
+		if !IsPublicIP(net.ParseIP(ip)) {
+			t.Errorf("expected %s to be public", ip)
+		}
+	}
+	for _, ip := range []string{"0.0.0.0", "127.0.0.2", "172.20.0.1", "100.64.0.1", "224.0.0.1", "::", "fe80::1"} {
+		if IsPublicIP(net.ParseIP(ip)) {
+			t.Errorf("expected %s not to be public", ip)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore"
+	"github.com/iris-contrib/parrot/parrot-api/export"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+func runProject(args []string) error {
+	if len(args) > 0 && args[0] == "export" {
+		return exportProjects(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Usage: parrot-api project export [flags]\n")
+	return errUsage
+}
+
+// exportProjects writes the locales of the projects as files, in a directory
+// per project named after the project, laid out like the API's export archives.
+func exportProjects(args []string) error {
+	fs := newFlagSet("project export", "project export -all|-project <id> [flags]")
+	db := dbFlags(fs)
+	all := fs.Bool("all", false, "export every project")
+	projectID := fs.String("project", "", "id of the project to export")
+	i18nType := fs.String("type", "keyvaluejson", "export format")
+	out := fs.String("out", ".", "output directory")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *all == (*projectID != "") {
+		fs.Usage()
+		return errUsage
+	}
+	if _, ok := export.NewExporter(*i18nType); !ok {
+		return fmt.Errorf("unknown export type '%s'", *i18nType)
+	}
+
+	ds, err := db.open(false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	var projects []model.Project
+	if *all {
+		projects, err = ds.GetProjects()
+		if err != nil {
+			return err
+		}
+	} else {
+		project, err := ds.GetProject(*projectID)
+		if err != nil {
+			return fmt.Errorf("could not find project %s: %v", *projectID, err)
+		}
+		projects = []model.Project{*project}
+	}
+
+	for i := range projects {
+		dir := filepath.Join(*out, fmt.Sprintf("%s_%s",
+			unsafeFilenameChars.ReplaceAllString(projects[i].Name, "_"), projects[i].ID))
+		count, err := exportProject(ds, &projects[i], *i18nType, dir)
+		if err != nil {
+			return fmt.Errorf("failed to export project %s: %v", projects[i].ID, err)
+		}
+		fmt.Printf("%s: %d locales exported to %s\n", projects[i].Name, count, dir)
+	}
+
+	return nil
+}
+
+// exportProject writes every locale of the project to dir and returns their count.
+func exportProject(ds datastore.Store, project *model.Project, i18nType, dir string) (int, error) {
+	exporter, _ := export.NewExporter(i18nType)
+	if nester, ok := exporter.(export.Nester); ok {
+		nester.SetKeyDelimiter(project.KeyDelimiter)
+	}
+	if commenter, ok := exporter.(export.Commenter); ok {
+		keys, err := ds.GetProjectKeys(project.ID)
+		if err != nil {
+			return 0, err
+		}
+		commenter.SetComments(model.KeyDescriptions(keys))
+	}
+
+	locales, err := ds.GetProjectLocales(project.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range locales {
+		locales[i].SyncKeys(project.Keys)
+		locales[i].SyncPluralKeys(project.PluralKeys)
+
+		data, err := exporter.Export(&locales[i])
+		if err != nil {
+			return 0, err
+		}
+
+		bundlePath, err := export.BundlePath(i18nType, locales[i].Ident)
+		if err != nil {
+			return 0, fmt.Errorf("locale %s: %v", locales[i].Ident, err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(bundlePath))
+		if !isWithin(dir, path) {
+			return 0, fmt.Errorf("locale %s: path %s is outside of %s", locales[i].Ident, path, dir)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return 0, err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(locales), nil
+}
+
+// isWithin returns true if path is dir or one of its descendants.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
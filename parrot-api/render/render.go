@@ -18,6 +18,14 @@ type apiResponseBody struct {
 type responseMeta struct {
 	Status int   `json:"status,omitempty"`
 	Error  error `json:"error,omitempty"`
+	Page   *Page `json:"page,omitempty"`
+}
+
+// Page holds the pagination metadata of a list. The next page is requested
+// with the cursor, which is empty on the last page.
+type Page struct {
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // Error writes an API error to the response.
@@ -42,6 +50,18 @@ func JSON(ctx iris.Context, status int, payload interface{}) {
 	ctx.JSON(body)
 }
 
+// JSONPage writes a page of a list as json to the response, along with its pagination metadata.
+func JSONPage(ctx iris.Context, status int, page Page, payload interface{}) {
+	body := apiResponseBody{
+		responseMeta: responseMeta{
+			Status: status,
+			Page:   &page},
+		Payload: payload}
+
+	ctx.StatusCode(status)
+	ctx.JSON(body)
+}
+
 // JSONWithHeaders writes a payload as json to the response and includes the provided headers.
 func JSONWithHeaders(ctx iris.Context, status int, headers map[string]string, payload interface{}) {
 	h := ctx.ResponseWriter().Header()
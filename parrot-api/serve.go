@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/middleware/logger"
+	"github.com/kataras/iris/v12/middleware/recover"
+
+	"github.com/iris-contrib/parrot/parrot-api/api"
+	"github.com/iris-contrib/parrot/parrot-api/auth"
+	"github.com/iris-contrib/parrot/parrot-api/datastore"
+	"github.com/iris-contrib/parrot/parrot-api/webhook"
+)
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve", "serve [flags]")
+	db := dbFlags(fs)
+	addr := envString(fs, "addr", "PARROT_API_HOST_PORT", ":8080", "address to listen on")
+	strategy := envString(fs, "migration-strategy", "PARROT_DB_MIGRATION_STRATEGY", "", "migrations to run on start: up, down, down,up or to")
+	version := envString(fs, "migration-version", "PARROT_DB_MIGRATION_VERSION", "", "version to migrate to with the 'to' strategy")
+	signingKey := envString(fs, "signing-key", "PARROT_AUTH_SIGNING_KEY", "", "HMAC key used to sign tokens")
+	signingKeys := envString(fs, "signing-keys", "PARROT_AUTH_SIGNING_KEYS", "", "PEM files of the RSA or ECDSA keys used to sign tokens")
+	activeKeyID := envString(fs, "active-key-id", "PARROT_AUTH_ACTIVE_KEY_ID", "", "id of the key used to sign new tokens")
+	issuerName := envString(fs, "issuer-name", "PARROT_AUTH_ISSUER_NAME", "parrot-default", "token issuer name")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	keys, err := auth.LoadPEMKeys(*signingKeys)
+	if err != nil {
+		return err
+	}
+	if *signingKey == "" && len(keys) == 0 {
+		return errors.New("no auth signing key set")
+	}
+
+	// init and ping datastore
+	ds, err := db.open(true)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	if err := migrateOnStart(ds, db.dir(), *strategy, *version); err != nil {
+		return err
+	}
+
+	// deliver the queued webhook events in the background
+	go webhook.NewWorker(ds).Run(nil)
+
+	app := iris.New()
+
+	app.Use(
+		recover.New(),
+		func(ctx iris.Context) {
+			ctx.Header("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+			ctx.Next()
+		},
+		logger.New(),
+	)
+
+	tp := auth.TokenProvider{
+		Name:        *issuerName,
+		SigningKey:  []byte(*signingKey),
+		Keys:        keys,
+		ActiveKeyID: *activeKeyID,
+	}
+	app.Configure(auth.NewRouter(ds, tp))
+	app.Configure(api.NewRouter(ds, tp))
+
+	// config and init server
+	srv := &http.Server{
+		Addr:           *addr,
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	return app.Run(iris.Server(srv))
+}
+
+// migrateOnStart runs the migrations of the strategy. No strategy skips migrations.
+func migrateOnStart(ds datastore.Store, dirPath, strategy, version string) error {
+	if strategy == "" {
+		golog.Info("no migration strategy set, skipping migrations")
+		return nil
+	}
+	golog.Infof("migration strategy is set to '%s'", strategy)
+
+	var fn func(string) error
+
+	switch strategy {
+	// Case when we want to start clean each time
+	case "down,up":
+		fn = func(path string) error {
+			err := ds.MigrateDown(path)
+			if err != nil {
+				return err
+			}
+			return ds.MigrateUp(path)
+		}
+	// Case when we want to apply migrations if needed
+	case "up":
+		fn = ds.MigrateUp
+	// Case when we want to simply drop everything
+	case "down":
+		fn = ds.MigrateDown
+	// Case when we want to migrate up or down to a given version
+	case "to":
+		v, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version: %s", err)
+		}
+		fn = func(path string) error {
+			return ds.MigrateTo(path, v)
+		}
+	default:
+		return fmt.Errorf("could not recognize migration strategy '%s'", strategy)
+	}
+
+	golog.Infof("migrating with the migrations of '%s'...", dirPath)
+	if err := fn(dirPath); err != nil {
+		return err
+	}
+	golog.Info("migration completed successfully")
+
+	status, err := ds.MigrationStatus(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, m := range status {
+		state := "pending"
+		if m.Applied {
+			state = "applied"
+		}
+		golog.Infof("migration %d_%s: %s", m.Version, m.Name, state)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package translate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTP is a provider that delegates to a service speaking a simple JSON protocol.
+// The texts are POSTed to the URL as
+//
+//	{"source": "en_US", "target": "de_DE", "texts": ["Hello"]}
+//
+// and the service answers with a 2xx status and the translations in the same order:
+//
+//	{"translations": ["Hallo"]}
+//
+// The API key, if any, is sent as a bearer token.
+type HTTP struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// httpRequest is the body sent to an HTTP provider.
+type httpRequest struct {
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Texts  []string `json:"texts"`
+}
+
+// httpResponse is the body returned by an HTTP provider.
+type httpResponse struct {
+	Translations []string `json:"translations"`
+}
+
+// Translate implements the Provider interface.
+func (p *HTTP) Translate(source, target string, texts []string) ([]string, error) {
+	body, err := json.Marshal(httpRequest{Source: source, Target: target, Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		// Drain the body so that the connection can be reused
+		io.Copy(ioutil.Discard, io.LimitReader(res.Body, 1<<16))
+		return nil, fmt.Errorf("translate: provider answered with status %d", res.StatusCode)
+	}
+
+	result := httpResponse{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("translate: invalid provider response: %v", err)
+	}
+	return result.Translations, nil
+}
@@ -0,0 +1,130 @@
+package translate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// markup matches the HTML tags and entities of a value, which are kept as they are
+// along with the placeholders found by the quality checks.
+var markup = regexp.MustCompile(`<[^<>]+>|&#?\w+;`)
+
+// accents replaces ASCII letters with accented look-alikes.
+var accents = strings.NewReplacer(
+	"a", "å", "b", "ƀ", "c", "ç", "d", "ð", "e", "é", "f", "ƒ", "g", "ĝ", "h", "ĥ", "i", "î",
+	"j", "ĵ", "k", "ķ", "l", "ļ", "m", "ɱ", "n", "ñ", "o", "ö", "p", "þ", "q", "ǫ", "r", "ŕ",
+	"s", "š", "t", "ţ", "u", "û", "v", "ṽ", "w", "ŵ", "x", "ẋ", "y", "ý", "z", "ž",
+	"A", "Å", "B", "Ɓ", "C", "Ç", "D", "Ð", "E", "É", "F", "Ƒ", "G", "Ĝ", "H", "Ĥ", "I", "Î",
+	"J", "Ĵ", "K", "Ķ", "L", "Ļ", "M", "Ṁ", "N", "Ñ", "O", "Ö", "P", "Þ", "Q", "Ǫ", "R", "Ŕ",
+	"S", "Š", "T", "Ţ", "U", "Û", "V", "Ṽ", "W", "Ŵ", "X", "Ẋ", "Y", "Ý", "Z", "Ž",
+)
+
+// DefaultExpansion is the percentage by which pseudo-localized texts are lengthened,
+// about what translations from English need.
+const DefaultExpansion = 30
+
+// Pseudo is a provider that pseudo-localizes the texts, so that untranslated strings,
+// encoding issues and truncated layouts stand out without a real translation.
+type Pseudo struct{}
+
+// Translate implements the Provider interface.
+func (Pseudo) Translate(source, target string, texts []string) ([]string, error) {
+	result := make([]string, len(texts))
+	for i, s := range texts {
+		result[i] = Pseudolocalize(s, DefaultExpansion)
+	}
+	return result, nil
+}
+
+// Pseudolocalize accents the letters of s, pads it by the expansion percentage of
+// its length to simulate longer languages and wraps it in brackets. Placeholders,
+// HTML tags and entities are kept as they are. Empty strings stay empty.
+func Pseudolocalize(s string, expansion int) string {
+	if s == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(pseudoText(s))
+	if padding := (utf8.RuneCountInString(s)*expansion + 99) / 100; padding > 0 {
+		b.WriteString(" ")
+		b.WriteString(strings.Repeat("~", padding))
+	}
+	b.WriteString("]")
+
+	return b.String()
+}
+
+// pseudoText accents the letters of s outside of its placeholders, tags and entities.
+// ICU arguments with a format keep their name, type and selectors, only the messages
+// nested in their braces are accented, e.g. '{count, plural, one {# item} other {# items}}'.
+func pseudoText(s string) string {
+	spans := append(model.PlaceholderIndexes(s), markup.FindAllStringIndex(s, -1)...)
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var b strings.Builder
+	last := 0
+	for _, m := range spans {
+		// Skip the spans overlapping a tag or an argument already written
+		if m[0] < last {
+			continue
+		}
+		b.WriteString(accents.Replace(s[last:m[0]]))
+		end := m[1]
+		if s[m[0]] == '{' && s[end-1] == ',' {
+			if close := closingBrace(s, m[0]); close >= 0 {
+				end = close + 1
+				b.WriteString(pseudoArgument(s[m[0]:end]))
+				last = end
+				continue
+			}
+		}
+		b.WriteString(s[m[0]:end])
+		last = end
+	}
+	b.WriteString(accents.Replace(s[last:]))
+	return b.String()
+}
+
+// pseudoArgument accents the messages nested in the braces of an ICU argument,
+// keeping the rest of it as it is.
+func pseudoArgument(arg string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 1; i < len(arg)-1; i++ {
+		if arg[i] != '{' {
+			b.WriteByte(arg[i])
+			continue
+		}
+		close := closingBrace(arg, i)
+		b.WriteByte('{')
+		b.WriteString(pseudoText(arg[i+1 : close]))
+		b.WriteByte('}')
+		i = close
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// closingBrace returns the index of the brace closing the one at index i of s,
+// or -1 if the braces are unbalanced.
+func closingBrace(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+// Package translate fills the empty values of locales with the help of machine
+// translation providers.
+package translate
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+const (
+	// timeout bounds a request to a provider.
+	timeout = 30 * time.Second
+	// batchSize is the number of texts sent to a provider at once.
+	batchSize = 100
+)
+
+// Provider translates texts from a locale to another.
+type Provider interface {
+	// Translate returns the translations of the texts, in the same order.
+	// Locales are identified by their ident, e.g. 'en_US'.
+	Translate(source, target string, texts []string) ([]string, error)
+}
+
+// New returns the provider selected by the config.
+func New(config model.TranslationConfig) (Provider, error) {
+	switch config.Provider {
+	case model.TranslationPseudo:
+		return Pseudo{}, nil
+	case model.TranslationHTTP:
+		return &HTTP{
+			URL:    config.URL,
+			APIKey: config.APIKey,
+			Client: &http.Client{Timeout: timeout}}, nil
+	}
+	return nil, fmt.Errorf("translate: unknown provider %q", config.Provider)
+}
+
+// Values translates the values of a locale, mapped by key, to the target locale.
+// The values are sent to the provider in batches, sorted by key.
+func Values(p Provider, source, target string, values map[string]string) (map[string]string, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(keys))
+	for start := 0; start < len(keys); start += batchSize {
+		end := start + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		texts := make([]string, 0, end-start)
+		for _, k := range keys[start:end] {
+			texts = append(texts, values[k])
+		}
+		translations, err := p.Translate(source, target, texts)
+		if err != nil {
+			return nil, err
+		}
+		if len(translations) != len(texts) {
+			return nil, fmt.Errorf("translate: expected %d translations, got %d", len(texts), len(translations))
+		}
+		for i, k := range keys[start:end] {
+			result[k] = translations[i]
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package translate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPseudolocalize(t *testing.T) {
+	tests := []struct {
+		in        string
+		expansion int
+		expected  string
+	}{
+		{"", 30, ""},
+		{"Hello", 30, "[Ĥéļļö ~~]"},
+		{"Hello", 0, "[Ĥéļļö]"},
+		{"Hi %s, you have %1$d {count} items", 30, "[Ĥî %s, ýöû ĥåṽé %1$d {count} îţéɱš ~~~~~~~~~~~]"},
+		{"<b>Save</b> &amp; {{name}}", 50, "[<b>Šåṽé</b> &amp; {{name}} ~~~~~~~~~~~~~]"},
+		{"100% sure, 50% off", 0, "[100% šûŕé, 50% öƒƒ]"},
+		{"{count, plural, one {# item in {place}} other {<b>#</b> items}}", 0,
+			"[{count, plural, one {# îţéɱ îñ {place}} other {<b>#</b> îţéɱš}}]"},
+		{"{gender, select, female {She} other {They}} left", 0, "[{gender, select, female {Šĥé} other {Ţĥéý}} ļéƒţ]"},
+		{"{count, plural, one {item}", 0, "[{count, þļûŕåļ, öñé {item}]"},
+	}
+	for _, tc := range tests {
+		if got := Pseudolocalize(tc.in, tc.expansion); got != tc.expected {
+			t.Errorf("expected %q to be pseudo-localized as %q, got %q", tc.in, tc.expected, got)
+		}
+	}
+}
+
+func TestHTTPValues(t *testing.T) {
+	var requests []httpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		req := httpRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+
+		res := httpResponse{}
+		for _, s := range req.Texts {
+			res.Translations = append(res.Translations, strings.ToUpper(s))
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	p := &HTTP{URL: srv.URL, APIKey: "key"}
+	result, err := Values(p, "en_US", "de_DE", map[string]string{"b": "bye", "a": "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"a": "HELLO", "b": "BYE"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+	if len(requests) != 1 || requests[0].Source != "en_US" || requests[0].Target != "de_DE" ||
+		!reflect.DeepEqual(requests[0].Texts, []string{"hello", "bye"}) {
+		t.Errorf("unexpected provider requests %+v", requests)
+	}
+
+	p.APIKey = "wrong"
+	if _, err := Values(p, "en_US", "de_DE", map[string]string{"a": "hello"}); err == nil {
+		t.Error("expected an error for a failed provider request")
+	}
+}
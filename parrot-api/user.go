@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/iris-contrib/parrot/parrot-api/auth"
+	apiErrors "github.com/iris-contrib/parrot/parrot-api/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func runUser(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "create":
+			return createUser(args[1:])
+		case "reset-password":
+			return resetUserPassword(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Usage: parrot-api user create|reset-password [flags]\n")
+	return errUsage
+}
+
+// createUser creates a user, as the registration endpoint does.
+func createUser(args []string) error {
+	fs := newFlagSet("user create", "user create -email <email> -name <name> [flags]")
+	db := dbFlags(fs)
+	email := fs.String("email", "", "email of the user")
+	name := fs.String("name", "", "name of the user")
+	password := envString(fs, "password", "PARROT_USER_PASSWORD", "", "password of the user, read from the standard input if not set")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *password == "" {
+		p, err := readPassword()
+		if err != nil {
+			return err
+		}
+		*password = p
+	}
+
+	user := model.User{Email: *email, Name: *name, Password: *password}
+	if err := user.Validate(); err != nil {
+		return validationError(err)
+	}
+
+	ds, err := db.open(false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	existingUser, err := ds.GetUserByEmail(user.Email)
+	if err == nil && existingUser.Email == user.Email {
+		return fmt.Errorf("a user with email %s already exists", user.Email)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashed)
+
+	result, err := ds.CreateUser(user)
+	if err != nil {
+		return err
+	}
+
+	// Hide password
+	result.Password = ""
+	return printJSON(result)
+}
+
+// resetUserPassword sets a new password for the user with the email, and revokes the
+// tokens issued to the user.
+func resetUserPassword(args []string) error {
+	fs := newFlagSet("user reset-password", "user reset-password -email <email> [flags]")
+	db := dbFlags(fs)
+	email := fs.String("email", "", "email of the user")
+	password := envString(fs, "password", "PARROT_USER_PASSWORD", "", "new password, read from the standard input if not set")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	if *password == "" {
+		p, err := readPassword()
+		if err != nil {
+			return err
+		}
+		*password = p
+	}
+	if !model.HasMinLength(*password, 8) {
+		return errors.New(model.ErrInvalidPassword.Message)
+	}
+
+	ds, err := db.open(false)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	user, err := ds.GetUserByEmail(strings.ToLower(*email))
+	if err != nil {
+		return fmt.Errorf("could not find user with email %s: %v", *email, err)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashed)
+
+	if _, err := ds.UpdateUserPassword(*user); err != nil {
+		return err
+	}
+	// The password may have been reset because the account was compromised
+	if err := auth.RevokeSubject(ds, user.ID); err != nil {
+		return fmt.Errorf("password updated but failed to revoke the tokens of %s: %v", user.Email, err)
+	}
+
+	fmt.Printf("password of %s updated\n", user.Email)
+	return nil
+}
+
+// readPassword reads a password from the first line of the standard input.
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("no password provided")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// validationError turns a model validation error into a readable error.
+func validationError(err error) error {
+	multi, ok := err.(*apiErrors.MultiError)
+	if !ok {
+		return err
+	}
+	messages := make([]string, len(multi.Errors))
+	for i, e := range multi.Errors {
+		messages[i] = e.Message
+	}
+	return fmt.Errorf("%s: %s", multi.Message, strings.Join(messages, ", "))
+}
@@ -0,0 +1,221 @@
+// Package webhook delivers the project events queued by the stores to the
+// subscribed webhooks, retrying failed deliveries with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/kataras/golog"
+
+	dbErrors "github.com/iris-contrib/parrot/parrot-api/datastore/errors"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+// Headers sent with every delivery. The signature is the hex encoded HMAC-SHA256
+// of the request body keyed with the webhook secret, prefixed with 'sha256='.
+const (
+	SignatureHeader = "X-Parrot-Signature"
+	EventHeader     = "X-Parrot-Event"
+	DeliveryHeader  = "X-Parrot-Delivery"
+)
+
+const (
+	// maxAttempts is the number of attempts after which a delivery is marked as failed.
+	maxAttempts = 10
+	// baseBackoff is the delay before the first retry, doubled after each attempt.
+	baseBackoff = 30 * time.Second
+	// maxBackoff caps the delay between attempts.
+	maxBackoff = 6 * time.Hour
+	// lease is how long a claimed delivery is hidden from other workers.
+	// It must exceed the client timeout.
+	lease = time.Minute
+	// batchSize is the number of deliveries claimed at once.
+	batchSize = 20
+)
+
+// Sign returns the signature of the body sent in the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Worker delivers the queued events. Several workers, e.g. one per API replica,
+// can share the queue.
+type Worker struct {
+	Store    model.WebhookStorer
+	Client   *http.Client
+	Interval time.Duration
+}
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address
+// that isn't public, like the loopback or the cloud metadata ones.
+var ErrPrivateAddress = errors.New("webhook: refusing to connect to a private address")
+
+// NewWorker creates a worker that polls the queue every few seconds.
+func NewWorker(store model.WebhookStorer) *Worker {
+	return &Worker{
+		Store:    store,
+		Client:   NewClient(),
+		Interval: 5 * time.Second,
+	}
+}
+
+// NewClient returns the HTTP client used for deliveries. It only connects to
+// public addresses, checked once the host name is resolved so that a name
+// pointing to an internal service is refused too, and doesn't follow redirects.
+func NewClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !model.IsPublicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run delivers the due events until stop is closed.
+func (w *Worker) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := w.RunOnce()
+			if err != nil {
+				golog.Errorf("failed to deliver webhooks: %v", err)
+			}
+			// Keep going while the queue is backed up
+			if err != nil || n < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce attempts a batch of due deliveries and returns their count.
+func (w *Worker) RunOnce() (int, error) {
+	deliveries, err := w.Store.ClaimWebhookDeliveries(batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		w.attempt(&deliveries[i])
+		if _, err := w.Store.UpdateWebhookDelivery(deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and records the outcome on it.
+func (w *Worker) attempt(d *model.WebhookDelivery) {
+	d.Attempts++
+	now := time.Now()
+
+	webhook, err := w.Store.GetProjectWebhook(d.ProjectID, d.WebhookID)
+	if err == dbErrors.ErrNotFound || (err == nil && !webhook.Active) {
+		d.Status = model.DeliveryFailed
+		d.LastError = "webhook was deleted or disabled"
+		return
+	}
+	if err != nil {
+		d.LastError = err.Error()
+		retry(d, now)
+		return
+	}
+
+	body := []byte(d.Payload)
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		d.Status = model.DeliveryFailed
+		d.LastError = err.Error()
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Parrot-Webhook")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		d.LastStatusCode = 0
+		d.LastError = err.Error()
+		retry(d, now)
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+
+	d.LastStatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		d.LastError = fmt.Sprintf("unexpected response status: %s", resp.Status)
+		retry(d, now)
+		return
+	}
+
+	d.Status = model.DeliveryDelivered
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// retry schedules the next attempt of the delivery, or marks it as failed
+// once it ran out of attempts.
+func retry(d *model.WebhookDelivery, now time.Time) {
+	if d.Attempts >= maxAttempts {
+		d.Status = model.DeliveryFailed
+		return
+	}
+	d.NextAttemptAt = now.Add(Backoff(d.Attempts))
+}
+
+// Backoff returns the delay before the next attempt of a delivery
+// that failed the provided number of times.
+func Backoff(attempts int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempts && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iris-contrib/parrot/parrot-api/datastore/memory"
+	"github.com/iris-contrib/parrot/parrot-api/model"
+)
+
+func TestDelivery(t *testing.T) {
+	status := http.StatusOK
+	var received []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	store := memory.New()
+	project, err := store.CreateProject(model.Project{Name: "webhooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook, err := store.CreateWebhook(model.Webhook{
+		ProjectID: project.ID,
+		URL:       srv.URL,
+		Secret:    "secret",
+		Events:    []string{model.EventLocaleUpdated},
+		Active:    true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only subscribed events are queued
+	if _, err := store.AddProjectKey(model.Key{ProjectID: project.ID, Key: "a"}, &model.Journal{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateLocale(model.Locale{Ident: "en", ProjectID: project.ID}, &model.Journal{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.UpdateLocalePairs(project.ID, "en", map[string]string{"a": "A"}, 0, &model.Journal{}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWorker(store)
+	// The test server listens on the loopback
+	w.Client = srv.Client()
+	n, err := w.RunOnce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(received) != 1 {
+		t.Fatalf("expected 1 delivery, got %d attempts and %d requests", n, len(received))
+	}
+
+	r := received[0]
+	if r.Header.Get(EventHeader) != model.EventLocaleUpdated {
+		t.Errorf("unexpected event header %q", r.Header.Get(EventHeader))
+	}
+	if r.Header.Get(SignatureHeader) != Sign("secret", bodies[0]) {
+		t.Errorf("invalid signature %q", r.Header.Get(SignatureHeader))
+	}
+	var event model.Event
+	if err := json.Unmarshal(bodies[0], &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Event != model.EventLocaleUpdated || event.ProjectID != project.ID {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// A failed attempt is retried later
+	status = http.StatusInternalServerError
+	if _, err := store.UpdateLocalePairs(project.ID, "en", map[string]string{"a": "AA"}, 0, &model.Journal{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := w.RunOnce(); n != 0 {
+		t.Errorf("expected the failed delivery to wait for its retry, got %d attempts", n)
+	}
+
+	deliveries, err := store.GetWebhookDeliveries(hook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(deliveries))
+	}
+	failed, delivered := deliveries[0], deliveries[1]
+	if delivered.Status != model.DeliveryDelivered || delivered.DeliveredAt == nil || delivered.Attempts != 1 {
+		t.Errorf("unexpected delivered delivery %+v", delivered)
+	}
+	if failed.Status != model.DeliveryPending || failed.Attempts != 1 || failed.LastStatusCode != http.StatusInternalServerError {
+		t.Errorf("unexpected failed delivery %+v", failed)
+	}
+	if failed.NextAttemptAt.Before(time.Now().Add(baseBackoff - time.Second)) {
+		t.Errorf("expected the retry to be delayed, next attempt at %v", failed.NextAttemptAt)
+	}
+}
+
+func TestPrivateAddresses(t *testing.T) {
+	var received int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+	}))
+	defer srv.Close()
+
+	store := memory.New()
+	project, err := store.CreateProject(model.Project{Name: "webhooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook, err := store.CreateWebhook(model.Webhook{ProjectID: project.ID, URL: srv.URL, Secret: "secret", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateLocale(model.Locale{Ident: "en", ProjectID: project.ID}, &model.Journal{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewWorker(store).RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if received != 0 {
+		t.Fatalf("expected the loopback address to be refused, got %d requests", received)
+	}
+	deliveries, err := store.GetWebhookDeliveries(hook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || !strings.Contains(deliveries[0].LastError, ErrPrivateAddress.Error()) {
+		t.Fatalf("expected the delivery to fail with %v, got %+v", ErrPrivateAddress, deliveries)
+	}
+}
+
+func TestNoRedirects(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	srv := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer srv.Close()
+
+	// Only the dialer is replaced, so that the test servers can be reached
+	client := NewClient()
+	client.Transport = http.DefaultTransport
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if redirected || resp.StatusCode != http.StatusFound {
+		t.Errorf("expected the redirect to be returned, got status %d", resp.StatusCode)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{20, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.expected {
+			t.Errorf("Backoff(%d): expected %v, got %v", tt.attempts, tt.expected, got)
+		}
+	}
+}
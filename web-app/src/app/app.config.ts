@@ -12,7 +12,8 @@ export const UserRoles = [
     'owner',
     'editor',
     'viewer',
-    'developer'
+    'developer',
+    'reviewer'
 ];
 
 export const ErrorMap = {
@@ -41,6 +42,7 @@ export const ErrorMap = {
 
 export const LocaleExportFormats = [
     { apiIdent: 'keyvaluejson', name: 'Key Value JSON', extension: '.json' },
+    { apiIdent: 'i18next', name: 'i18next JSON', extension: '.json' },
     { apiIdent: 'po', name: 'Gettext', extension: '.po' },
     { apiIdent: 'strings', name: 'Apple Strings', extension: '.strings' },
     { apiIdent: 'properties', name: 'Java Properties', extension: '.properties' },